@@ -2,76 +2,162 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
-	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
 
+	"github.com/gerps2/desafio-cloud-run/features/admin"
+	"github.com/gerps2/desafio-cloud-run/features/graphql"
 	"github.com/gerps2/desafio-cloud-run/features/weather"
+	"github.com/gerps2/desafio-cloud-run/features/weather/liveWeather"
+	"github.com/gerps2/desafio-cloud-run/shared/auth"
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/grpcserver"
+	"github.com/gerps2/desafio-cloud-run/shared/health"
 	httpServer "github.com/gerps2/desafio-cloud-run/shared/http"
+	"github.com/gerps2/desafio-cloud-run/shared/lifecycle"
 	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+	"github.com/gerps2/desafio-cloud-run/shared/openapi"
+	"github.com/gerps2/desafio-cloud-run/shared/ratelimit"
+	"github.com/gerps2/desafio-cloud-run/shared/reload"
 
 	"github.com/gin-gonic/gin"
 )
 
 type App struct {
-	server            *httpServer.Server
-	weatherController *weather.WeatherController
-	logger            logger.Logger
+	server             *httpServer.Server
+	grpcServer         *grpcserver.Server
+	weatherController  *weather.WeatherController
+	weatherGRPCService *weather.WeatherGRPCService
+	streamController   *weather.WeatherStreamController
+	liveWeather        *liveWeather.Hub
+	adminController    *admin.AdminController
+	graphqlController  *graphql.GraphQLController
+	metrics            *metrics.Metrics
+	health             *health.Registry
+	authGuard          *auth.Guard
+	rateLimiter        *ratelimit.Limiter
+	reloader           *reload.Reloader
+	lifecycle          *lifecycle.Manager
+	apiDoc             *openapi.Document
+	logger             logger.Logger
 }
 
 func NewApp(
 	server *httpServer.Server,
+	grpcServer *grpcserver.Server,
 	weatherController *weather.WeatherController,
+	weatherGRPCService *weather.WeatherGRPCService,
+	streamController *weather.WeatherStreamController,
+	liveWeatherHub *liveWeather.Hub,
+	adminController *admin.AdminController,
+	graphqlController *graphql.GraphQLController,
+	metrics *metrics.Metrics,
+	healthRegistry *health.Registry,
+	authGuard *auth.Guard,
+	rateLimiter *ratelimit.Limiter,
+	reloader *reload.Reloader,
+	lifecycleManager *lifecycle.Manager,
+	apiDoc *openapi.Document,
 	logger logger.Logger,
 ) *App {
 	return &App{
-		server:            server,
-		weatherController: weatherController,
-		logger:            logger,
+		server:             server,
+		grpcServer:         grpcServer,
+		weatherController:  weatherController,
+		weatherGRPCService: weatherGRPCService,
+		streamController:   streamController,
+		liveWeather:        liveWeatherHub,
+		adminController:    adminController,
+		graphqlController:  graphqlController,
+		metrics:            metrics,
+		health:             healthRegistry,
+		authGuard:          authGuard,
+		rateLimiter:        rateLimiter,
+		reloader:           reloader,
+		lifecycle:          lifecycleManager,
+		apiDoc:             apiDoc,
+		logger:             logger,
 	}
 }
 
 func (a *App) setupRoutes() {
 	router := a.server.GetRouter()
 
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "healthy"})
-	})
+	router.Use(a.lifecycle.Middleware())
+	// Limited before the guard so rejected credentials cannot flood it, and
+	// again per principal once authenticated.
+	router.Use(a.rateLimiter.Middleware())
+	router.Use(a.authGuard.Middleware())
+	router.Use(a.rateLimiter.PrincipalMiddleware())
+
+	router.GET("/livez", health.LivenessHandler())
+	router.GET("/readyz", health.ReadinessHandler(a.health))
+	// Kept for existing probes and clients; same semantics as /livez, with
+	// the original body.
+	router.GET("/health", health.LegacyHealthHandler())
+
+	router.GET("/metrics", gin.WrapH(a.metrics.Handler()))
+
+	router.GET("/openapi.json", openapi.Handler(a.apiDoc))
+	router.GET("/docs", openapi.UIHandler(a.apiDoc.Info.Title, "/openapi.json"))
 
 	a.weatherController.RegisterRoutes(router)
+	a.streamController.RegisterRoutes(router)
+	a.adminController.RegisterRoutes(router)
+	a.graphqlController.RegisterRoutes(router)
+
+	a.describeRoutes()
 }
 
-func (a *App) Run() error {
+// Run serves until a shutdown signal or a startup failure, then drains and
+// closes the dependencies released by cleanup.
+func (a *App) Run(cleanup func()) error {
 	a.setupRoutes()
 
-	go func() {
-		if err := a.server.Start(); err != nil {
-			a.logger.Error("Server failed to start: %v", err)
-		}
-	}()
+	a.lifecycle.OnDrain(a.health.StartDraining)
+	// Open streams would otherwise hold the server until the grace period.
+	a.lifecycle.OnDrain(a.liveWeather.Shutdown)
+	a.lifecycle.AddServer("http server", a.server.Start, a.server.Shutdown)
+	if a.grpcServer.Enabled() {
+		a.weatherGRPCService.Register(a.grpcServer)
+		a.lifecycle.OnDrain(a.grpcServer.StartDraining)
+		a.lifecycle.AddServer("grpc server", a.grpcServer.Start, a.grpcServer.Shutdown)
+	}
+	a.lifecycle.Go("config reloader", a.reloader.Run)
+	a.lifecycle.OnClose("upstream clients and telemetry", func() error {
+		cleanup()
+		return nil
+	})
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	return a.lifecycle.Run(context.Background())
+}
 
-	a.logger.Info("Shutting down server...")
+func main() {
+	configFile := flag.String("config", "", "path to a YAML or TOML config file (defaults to $CONFIG_FILE)")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration with secrets redacted and exit")
+	flag.Parse()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	opts := config.Options{File: *configFile}
 
-	return a.server.Shutdown(ctx)
-}
+	if *printConfig {
+		cfg, err := config.Load(opts)
+		if err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+		if err := cfg.WriteYAML(os.Stdout); err != nil {
+			log.Fatalf("Failed to print config: %v", err)
+		}
+		return
+	}
 
-func main() {
-	app, err := InitializeApp()
+	app, cleanup, err := InitializeApp(opts)
 	if err != nil {
 		log.Fatalf("Failed to initialize app: %v", err)
 	}
 
-	if err := app.Run(); err != nil {
+	if err := app.Run(cleanup); err != nil {
 		log.Fatalf("Failed to run app: %v", err)
 	}
 }
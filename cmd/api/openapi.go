@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gerps2/desafio-cloud-run/shared/health"
+	"github.com/gerps2/desafio-cloud-run/shared/openapi"
+)
+
+// describeRoutes documents every route registered in setupRoutes; the
+// controllers describe their own.
+func (a *App) describeRoutes() {
+	doc := a.apiDoc
+	report := doc.Schema("HealthReport", health.Report{})
+	alive := &openapi.Schema{
+		Type:       "object",
+		Properties: map[string]*openapi.Schema{"status": {Type: "string", Example: "alive"}},
+	}
+
+	liveness := openapi.Operation{
+		Tags:        []string{"health"},
+		Summary:     "Liveness probe",
+		Description: "Answers 200 while the process is running; checks no dependency.",
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK): openapi.JSON("Process is alive", alive),
+		},
+	}
+	livez := liveness
+	livez.OperationID = "livez"
+	legacyHealth := liveness
+	legacyHealth.OperationID = "health"
+	legacyHealth.Description += " Kept for existing probes; same as /livez, answering with its original status \"healthy\"."
+	legacyHealth.Responses = map[string]openapi.Response{
+		openapi.Status(http.StatusOK): openapi.JSON("Process is alive", &openapi.Schema{
+			Type:       "object",
+			Properties: map[string]*openapi.Schema{"status": {Type: "string", Example: "healthy"}},
+		}),
+	}
+	doc.Add(http.MethodGet, "/livez", livez)
+	doc.Add(http.MethodGet, "/health", legacyHealth)
+
+	doc.Add(http.MethodGet, "/readyz", openapi.Operation{
+		Tags:        []string{"health"},
+		Summary:     "Readiness probe",
+		Description: "Runs the dependency probes, cached for HEALTH_CACHE_TTL_SEC. Fails while the app is draining for shutdown.",
+		OperationID: "readyz",
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK):                 openapi.JSON("Ready or degraded", report),
+			openapi.Status(http.StatusServiceUnavailable): openapi.JSON("A critical dependency is down or the app is draining", report),
+		},
+	})
+
+	doc.Add(http.MethodGet, "/metrics", openapi.Operation{
+		Tags:        []string{"observability"},
+		Summary:     "Prometheus metrics",
+		OperationID: "metrics",
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "Prometheus text exposition format",
+				Content:     map[string]openapi.MediaType{"text/plain": {Schema: &openapi.Schema{Type: "string"}}},
+			},
+		},
+	})
+
+	doc.Add(http.MethodGet, "/openapi.json", openapi.Operation{
+		Tags:        []string{"docs"},
+		Summary:     "This OpenAPI document",
+		OperationID: "openapi",
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK): openapi.JSON("OpenAPI 3 document", &openapi.Schema{Type: "object"}),
+		},
+	})
+	doc.Add(http.MethodGet, "/docs", openapi.Operation{
+		Tags:        []string{"docs"},
+		Summary:     "Swagger UI",
+		OperationID: "docs",
+		Responses: map[string]openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "Swagger UI page",
+				Content:     map[string]openapi.MediaType{"text/html": {Schema: &openapi.Schema{Type: "string"}}},
+			},
+		},
+	})
+
+	a.weatherController.DescribeRoutes(doc)
+	a.streamController.DescribeRoutes(doc)
+	a.adminController.DescribeRoutes(doc)
+	a.graphqlController.DescribeRoutes(doc)
+}
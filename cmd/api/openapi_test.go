@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gerps2/desafio-cloud-run/features/admin"
+	"github.com/gerps2/desafio-cloud-run/features/graphql"
+	"github.com/gerps2/desafio-cloud-run/features/weather"
+	getWeatherByCepMocks "github.com/gerps2/desafio-cloud-run/features/weather/getWeatherByCep/mocks"
+	"github.com/gerps2/desafio-cloud-run/features/weather/liveWeather"
+	"github.com/gerps2/desafio-cloud-run/shared/auth"
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/grpcserver"
+	"github.com/gerps2/desafio-cloud-run/shared/health"
+	httpServer "github.com/gerps2/desafio-cloud-run/shared/http"
+	"github.com/gerps2/desafio-cloud-run/shared/lifecycle"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+	"github.com/gerps2/desafio-cloud-run/shared/providers"
+	"github.com/gerps2/desafio-cloud-run/shared/ratelimit"
+	"github.com/gerps2/desafio-cloud-run/shared/reload"
+	viaCepMocks "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/viapcep/mocks"
+	weatherRepo "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather"
+	weatherMocks "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather/mocks"
+	"github.com/gerps2/desafio-cloud-run/shared/telemetry"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestApp(t *testing.T) *App {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{Port: "8080"},
+		App:    config.AppConfig{Env: config.EnvTest, RequestTimeoutSec: 30, ShutdownGraceSec: 1},
+		Log:    config.LogConfig{Level: "error", Format: "json"},
+		Auth: config.AuthConfig{
+			PublicPaths: []string{"/health", "/livez", "/readyz", "/metrics", "/openapi.json", "/docs"},
+			APIKey:      config.APIKeyConfig{Header: "X-API-Key", QueryParam: "api_key"},
+		},
+		Stream:       config.StreamConfig{Enabled: true, PollIntervalSec: 60, HeartbeatSec: 15, MaxCeps: 10},
+		GraphQL:      config.GraphQLConfig{Enabled: true, MaxDepth: 5, MaxComplexity: 200, MaxListSize: 20},
+		ExternalAPIs: config.ExternalAPIsConfig{Weather: config.WeatherConfig{Budget: config.WeatherBudgetConfig{Period: "month"}}},
+	}
+	log := logger.NewWithWriter(io.Discard, cfg.Log, logger.NewLevel(cfg))
+	m := metrics.New()
+
+	limiter, err := ratelimit.NewLimiter(cfg, ratelimit.NewMemoryStore(), m, log)
+	require.NoError(t, err)
+	budget, err := weatherRepo.NewBudget(cfg.ExternalAPIs.Weather.Budget, m)
+	require.NoError(t, err)
+
+	useCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
+	viaCepRepo := viaCepMocks.NewMockViaCepRepositoryInterface(t)
+	weatherRepository := weatherMocks.NewMockWeatherRepositoryInterface(t)
+	hub := liveWeather.NewHub(cfg, viaCepRepo, weatherRepository, m, log)
+	store := config.NewStore(cfg, config.Options{})
+	graphqlController, err := graphql.NewGraphQLController(useCase, viaCepRepo, weatherRepository, store, log)
+	require.NoError(t, err)
+
+	return NewApp(
+		httpServer.NewServer(cfg, log, telemetry.NewNoop(), m),
+		grpcserver.NewServer(cfg, log),
+		weather.NewWeatherController(useCase, store, log),
+		weather.NewWeatherGRPCService(useCase, cfg, log),
+		weather.NewWeatherStreamController(hub, cfg, log),
+		hub,
+		admin.NewAdminController(budget),
+		graphqlController,
+		m,
+		health.NewRegistry(0),
+		auth.NewGuard(cfg, log),
+		limiter,
+		reload.NewReloader(store, m, log),
+		lifecycle.NewManager(cfg, log),
+		providers.ProvideOpenAPIDocument(cfg),
+		log,
+	)
+}
+
+func TestOpenAPIDescribesEveryRoute(t *testing.T) {
+	app := setupTestApp(t)
+	app.setupRoutes()
+
+	routes := app.server.GetRouter().Routes()
+	require.NotEmpty(t, routes)
+	for _, route := range routes {
+		op, ok := app.apiDoc.Operation(route.Method, route.Path)
+		if assert.True(t, ok, "%s %s is registered but missing from the OpenAPI spec", route.Method, route.Path) {
+			assert.NotEmpty(t, op.Responses, "%s %s has no responses", route.Method, route.Path)
+		}
+	}
+}
+
+func TestOpenAPIDocumentIsServed(t *testing.T) {
+	app := setupTestApp(t)
+	app.setupRoutes()
+	router := app.server.GetRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var doc struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Contains(t, doc.Paths, "/api/v1/weather/{cep}")
+	assert.Contains(t, doc.Components.Schemas, "GetWeatherByCepOutput")
+	assert.Contains(t, doc.Components.Schemas, "ErrorResponse")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `url: "/openapi.json"`)
+}
@@ -4,34 +4,66 @@
 package main
 
 import (
+	"github.com/gerps2/desafio-cloud-run/features/admin"
+	"github.com/gerps2/desafio-cloud-run/features/graphql"
 	"github.com/gerps2/desafio-cloud-run/features/weather"
+	"github.com/gerps2/desafio-cloud-run/features/weather/liveWeather"
 	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/grpcserver"
 	"github.com/gerps2/desafio-cloud-run/shared/http"
+	"github.com/gerps2/desafio-cloud-run/shared/httpclient"
+	"github.com/gerps2/desafio-cloud-run/shared/lifecycle"
 	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
 	"github.com/gerps2/desafio-cloud-run/shared/providers"
+	"github.com/gerps2/desafio-cloud-run/shared/telemetry"
 
 	"github.com/google/wire"
 )
 
-func InitializeApp() (*App, error) {
+func InitializeApp(opts config.Options) (*App, func(), error) {
 	wire.Build(
 		// Shared dependencies
 		config.Load,
+		config.NewStore,
+		logger.NewLevel,
 		logger.New,
+		telemetry.New,
+		metrics.New,
 		http.NewServer,
+		grpcserver.NewServer,
+		lifecycle.NewManager,
 
 		// External APIs providers
+		httpclient.NewFactory,
 		providers.ProvideViaCepClient,
 		providers.ProvideViaCepRepository,
 		providers.ProvideWeatherClient,
+		providers.ProvideWeatherBudget,
 		providers.ProvideWeatherRepository,
+		providers.ProvideHealthRegistry,
+		providers.ProvideAPIKeyAuthenticator,
+		providers.ProvideJWTAuthenticator,
+		providers.ProvideAuthGuard,
+		providers.ProvideRateLimiter,
+		providers.ProvideReloader,
+		providers.ProvideOpenAPIDocument,
 
 		// Weather feature dependencies
 		weather.ProvideGetWeatherByCepUseCase,
 		weather.NewWeatherController,
+		weather.NewWeatherGRPCService,
+		liveWeather.NewHub,
+		weather.NewWeatherStreamController,
+
+		// Admin feature dependencies
+		admin.NewAdminController,
+
+		// GraphQL feature dependencies
+		graphql.NewGraphQLController,
 
 		// App
 		NewApp,
 	)
-	return &App{}, nil
+	return &App{}, nil, nil
 }
@@ -7,25 +7,91 @@
 package main
 
 import (
+	"github.com/gerps2/desafio-cloud-run/features/admin"
+	"github.com/gerps2/desafio-cloud-run/features/graphql"
 	"github.com/gerps2/desafio-cloud-run/features/weather"
+	"github.com/gerps2/desafio-cloud-run/features/weather/liveWeather"
 	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/grpcserver"
 	"github.com/gerps2/desafio-cloud-run/shared/http"
+	"github.com/gerps2/desafio-cloud-run/shared/httpclient"
+	"github.com/gerps2/desafio-cloud-run/shared/lifecycle"
 	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
 	"github.com/gerps2/desafio-cloud-run/shared/providers"
+	"github.com/gerps2/desafio-cloud-run/shared/telemetry"
 )
 
 // Injectors from wire.go:
 
-func InitializeApp() (*App, error) {
-	configConfig := config.Load()
-	loggerLogger := logger.New()
-	server := http.NewServer(configConfig, loggerLogger)
-	viaCepClient := providers.ProvideViaCepClient(configConfig)
+func InitializeApp(opts config.Options) (*App, func(), error) {
+	configConfig, err := config.Load(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	level := logger.NewLevel(configConfig)
+	loggerLogger := logger.New(configConfig, level)
+	telemetryTelemetry, cleanup, err := telemetry.New(configConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	metricsMetrics := metrics.New()
+	server := http.NewServer(configConfig, loggerLogger, telemetryTelemetry, metricsMetrics)
+	grpcserverServer := grpcserver.NewServer(configConfig, loggerLogger)
+	factory, cleanup2, err := httpclient.NewFactory(configConfig)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	viaCepClient := providers.ProvideViaCepClient(configConfig, factory, telemetryTelemetry, metricsMetrics)
 	viaCepRepositoryInterface := providers.ProvideViaCepRepository(viaCepClient)
-	weatherClient := providers.ProvideWeatherClient(configConfig)
-	weatherRepositoryInterface := providers.ProvideWeatherRepository(weatherClient)
-	getWeatherByCepUseCaseInterface := weather.ProvideGetWeatherByCepUseCase(viaCepRepositoryInterface, weatherRepositoryInterface, loggerLogger)
-	weatherController := weather.NewWeatherController(getWeatherByCepUseCaseInterface, loggerLogger)
-	app := NewApp(server, weatherController, loggerLogger)
-	return app, nil
+	weatherClient := providers.ProvideWeatherClient(configConfig, factory, telemetryTelemetry, metricsMetrics)
+	budget, err := providers.ProvideWeatherBudget(configConfig, metricsMetrics)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	weatherRepositoryInterface := providers.ProvideWeatherRepository(weatherClient, budget)
+	getWeatherByCepUseCaseInterface := weather.ProvideGetWeatherByCepUseCase(viaCepRepositoryInterface, weatherRepositoryInterface, loggerLogger, telemetryTelemetry, metricsMetrics)
+	store := config.NewStore(configConfig, opts)
+	weatherController := weather.NewWeatherController(getWeatherByCepUseCaseInterface, store, loggerLogger)
+	weatherGRPCService := weather.NewWeatherGRPCService(getWeatherByCepUseCaseInterface, configConfig, loggerLogger)
+	hub := liveWeather.NewHub(configConfig, viaCepRepositoryInterface, weatherRepositoryInterface, metricsMetrics, loggerLogger)
+	weatherStreamController := weather.NewWeatherStreamController(hub, configConfig, loggerLogger)
+	adminController := admin.NewAdminController(budget)
+	graphQLController, err := graphql.NewGraphQLController(getWeatherByCepUseCaseInterface, viaCepRepositoryInterface, weatherRepositoryInterface, store, loggerLogger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	registry := providers.ProvideHealthRegistry(configConfig, viaCepClient, weatherClient, budget)
+	jwtAuthenticator, err := providers.ProvideJWTAuthenticator(configConfig, factory, loggerLogger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	apiKeyAuthenticator, err := providers.ProvideAPIKeyAuthenticator(configConfig, loggerLogger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	guard := providers.ProvideAuthGuard(configConfig, loggerLogger, jwtAuthenticator, apiKeyAuthenticator)
+	limiter, err := providers.ProvideRateLimiter(configConfig, metricsMetrics, loggerLogger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	reloader := providers.ProvideReloader(store, level, registry, limiter, metricsMetrics, loggerLogger)
+	manager := lifecycle.NewManager(configConfig, loggerLogger)
+	document := providers.ProvideOpenAPIDocument(configConfig)
+	app := NewApp(server, grpcserverServer, weatherController, weatherGRPCService, weatherStreamController, hub, adminController, graphQLController, metricsMetrics, registry, guard, limiter, reloader, manager, document, loggerLogger)
+	return app, func() {
+		cleanup2()
+		cleanup()
+	}, nil
 }
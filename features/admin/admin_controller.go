@@ -0,0 +1,52 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gerps2/desafio-cloud-run/shared/auth"
+	httpShared "github.com/gerps2/desafio-cloud-run/shared/http"
+	"github.com/gerps2/desafio-cloud-run/shared/openapi"
+	weather "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminController struct {
+	weatherBudget *weather.Budget
+}
+
+func NewAdminController(weatherBudget *weather.Budget) *AdminController {
+	return &AdminController{
+		weatherBudget: weatherBudget,
+	}
+}
+
+func (ac *AdminController) RegisterRoutes(router *gin.Engine) {
+	admin := router.Group("/admin", auth.RequirePrincipalScopes(auth.ScopeAdmin), httpShared.NegotiateMiddleware())
+	{
+		admin.GET("/budgets", ac.GetBudgets)
+	}
+}
+
+// DescribeRoutes documents the routes of RegisterRoutes in the OpenAPI spec.
+func (ac *AdminController) DescribeRoutes(doc *openapi.Document) {
+	snapshot := doc.Schema("BudgetSnapshot", weather.BudgetSnapshot{})
+
+	doc.Add(http.MethodGet, "/admin/budgets", openapi.Negotiated(openapi.Operation{
+		Tags:        []string{"admin"},
+		Summary:     "Remaining upstream call budgets",
+		Description: "Counters are kept per instance. Requires an authenticated principal with the admin scope, so the route answers 403 while authentication is disabled.",
+		OperationID: "getBudgets",
+		Responses: openapi.Responses(openapi.CommonErrors(true), map[string]openapi.Response{
+			openapi.Status(http.StatusOK): openapi.Success("Upstream budgets retrieved successfully",
+				&openapi.Schema{Type: "array", Items: snapshot}),
+		}),
+		Security: openapi.Secured(auth.ScopeAdmin),
+	}, httpShared.SupportedMediaTypes()))
+}
+
+// GetBudgets reports the remaining upstream call budgets of this instance.
+func (ac *AdminController) GetBudgets(c *gin.Context) {
+	budgets := []weather.BudgetSnapshot{ac.weatherBudget.Snapshot()}
+	httpShared.RespondWithSuccess(c, budgets, "Upstream budgets retrieved successfully")
+}
@@ -0,0 +1,22 @@
+package graphql
+
+import (
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/visitor"
+)
+
+// selectsWeather reports whether the document asks for weather anywhere,
+// either at the root or under an address.
+func selectsWeather(doc *ast.Document) bool {
+	found := false
+	visitor.Visit(doc, &visitor.VisitorOptions{
+		Enter: func(p visitor.VisitFuncParams) (string, interface{}) {
+			if field, ok := p.Node.(*ast.Field); ok && field.Name.Value == "weather" {
+				found = true
+				return visitor.ActionBreak, nil
+			}
+			return visitor.ActionNoChange, nil
+		},
+	}, nil)
+	return found
+}
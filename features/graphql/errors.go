@@ -0,0 +1,71 @@
+package graphql
+
+import (
+	"errors"
+
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+	"github.com/gerps2/desafio-cloud-run/shared/i18n"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// resolverError exposes the APIError code and causes of a failed field in the
+// GraphQL error extensions, so clients can branch on them as with REST.
+type resolverError struct {
+	apiErr *sharedErrors.APIError
+}
+
+func (e resolverError) Error() string {
+	return e.apiErr.Message
+}
+
+func (e resolverError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{
+		"code":   e.apiErr.Code,
+		"status": e.apiErr.StatusCode,
+	}
+	if len(e.apiErr.Causes) > 0 {
+		extensions["causes"] = e.apiErr.Causes
+	}
+	return extensions
+}
+
+func toResolverError(err error) error {
+	var apiErr *sharedErrors.APIError
+	if !errors.As(err, &apiErr) {
+		apiErr = sharedErrors.NewInternalError(sharedErrors.MsgInternalError.Format(), nil)
+	}
+	return resolverError{apiErr: apiErr}
+}
+
+// withExtensions fills in the extensions graphql-go drops for errors returned
+// by thunks, which it formats twice before locating them, and translates the
+// APIErrors behind them to the request's locale.
+func withExtensions(errs []gqlerrors.FormattedError, locale string) []gqlerrors.FormattedError {
+	for i := range errs {
+		resolved, ok := resolverErrorOf(errs[i].OriginalError())
+		if !ok {
+			continue
+		}
+		localized := resolverError{apiErr: i18n.LocalizeError(locale, resolved.apiErr)}
+		errs[i].Message = localized.Error()
+		errs[i].Extensions = localized.Extensions()
+	}
+	return errs
+}
+
+func resolverErrorOf(err error) (resolverError, bool) {
+	for err != nil {
+		switch e := err.(type) {
+		case resolverError:
+			return e, true
+		case gqlerrors.FormattedError:
+			err = e.OriginalError()
+		case *gqlerrors.Error:
+			err = e.OriginalError
+		default:
+			return resolverError{}, false
+		}
+	}
+	return resolverError{}, false
+}
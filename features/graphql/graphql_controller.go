@@ -0,0 +1,185 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/features/weather/getWeatherByCep"
+	"github.com/gerps2/desafio-cloud-run/shared/auth"
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	httpShared "github.com/gerps2/desafio-cloud-run/shared/http"
+	"github.com/gerps2/desafio-cloud-run/shared/i18n"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/openapi"
+	viacep "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/viapcep"
+	weatherRepo "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather"
+
+	"github.com/gin-gonic/gin"
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// GraphQLRequest is the body of POST /graphql. GET takes the same fields as
+// query parameters, with variables JSON-encoded.
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+type GraphQLController struct {
+	schema                 gql.Schema
+	config                 config.GraphQLConfig
+	store                  *config.Store
+	getWeatherByCepUseCase getWeatherByCep.GetWeatherByCepUseCaseInterface
+	viaCepRepo             viacep.ViaCepRepositoryInterface
+	weatherRepo            weatherRepo.WeatherRepositoryInterface
+	logger                 logger.Logger
+}
+
+func NewGraphQLController(
+	getWeatherByCepUseCase getWeatherByCep.GetWeatherByCepUseCaseInterface,
+	viaCepRepo viacep.ViaCepRepositoryInterface,
+	weatherRepo weatherRepo.WeatherRepositoryInterface,
+	store *config.Store,
+	logger logger.Logger,
+) (*GraphQLController, error) {
+	gc := &GraphQLController{
+		config:                 store.Current().GraphQL,
+		store:                  store,
+		getWeatherByCepUseCase: getWeatherByCepUseCase,
+		viaCepRepo:             viaCepRepo,
+		weatherRepo:            weatherRepo,
+		logger:                 logger,
+	}
+
+	schema, err := gc.buildSchema()
+	if err != nil {
+		return nil, err
+	}
+	gc.schema = schema
+
+	return gc, nil
+}
+
+func (gc *GraphQLController) RegisterRoutes(router *gin.Engine) {
+	if !gc.config.Enabled {
+		return
+	}
+
+	// GET queries are cacheable for the address max-age, shortened to the
+	// weather one when they select weather.
+	router.GET("/graphql", auth.RequireScopes(auth.ScopeWeatherRead), httpShared.CacheMiddleware(gc.store, addressMaxAge), gc.Query)
+	router.POST("/graphql", auth.RequireScopes(auth.ScopeWeatherRead), gc.Query)
+}
+
+func addressMaxAge(cfg config.HTTPCacheConfig) time.Duration {
+	return time.Duration(cfg.AddressMaxAgeSec) * time.Second
+}
+
+// DescribeRoutes documents the routes of RegisterRoutes in the OpenAPI spec.
+func (gc *GraphQLController) DescribeRoutes(doc *openapi.Document) {
+	if !gc.config.Enabled {
+		return
+	}
+
+	request := doc.Schema("GraphQLRequest", GraphQLRequest{})
+	result := &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"data":   {Type: "object", Nullable: true},
+			"errors": {Type: "array", Items: &openapi.Schema{Type: "object"}, Description: "extensions.code holds the API error code"},
+		},
+	}
+	operation := openapi.Operation{
+		Tags:        []string{"graphql"},
+		Summary:     "GraphQL queries over addresses and weather",
+		Description: "Queries address(cep), addresses(ceps), weather(cep) and address { weather }. Depth, complexity and list size are limited before execution.",
+		Responses: openapi.Responses(openapi.CommonErrors(true), map[string]openapi.Response{
+			openapi.Status(http.StatusOK):         openapi.JSON("Query executed; field failures are listed in errors", result),
+			openapi.Status(http.StatusBadRequest): openapi.JSON("Query could not be parsed, is invalid or exceeds the limits", result),
+		}),
+		Security: openapi.Secured(auth.ScopeWeatherRead),
+	}
+
+	get := operation
+	get.OperationID = "graphqlQuery"
+	get.Responses = openapi.Responses(operation.Responses, map[string]openapi.Response{
+		openapi.Status(http.StatusNotModified): openapi.NotModified(),
+	})
+	get.Parameters = []openapi.Parameter{
+		{Name: "query", In: "query", Required: true, Schema: &openapi.Schema{Type: "string"}, Example: `{ address(cep: "01001000") { city weather { tempC } } }`},
+		{Name: "operationName", In: "query", Schema: &openapi.Schema{Type: "string"}},
+		{Name: "variables", In: "query", Description: "JSON-encoded variables", Schema: &openapi.Schema{Type: "string"}},
+	}
+	doc.Add(http.MethodGet, "/graphql", openapi.Localized(get, i18n.Locales()))
+
+	post := operation
+	post.OperationID = "graphqlQueryPost"
+	post.RequestBody = &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{"application/json": {Schema: request}}}
+	doc.Add(http.MethodPost, "/graphql", openapi.Localized(post, i18n.Locales()))
+}
+
+func (gc *GraphQLController) Query(c *gin.Context) {
+	var req GraphQLRequest
+	if err := bindRequest(c, &req); err != nil {
+		respond(c, http.StatusBadRequest, &gql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+
+	ctx := logger.ContextWithFields(c.Request.Context(), "graphql_operation", req.OperationName)
+	log := gc.logger.WithContext(ctx)
+	log.Debug("GraphQL query received")
+
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		respond(c, http.StatusBadRequest, &gql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+	if validation := gql.ValidateDocument(&gc.schema, doc, nil); !validation.IsValid {
+		respond(c, http.StatusBadRequest, &gql.Result{Errors: validation.Errors})
+		return
+	}
+	if err := checkLimits(doc, req.OperationName, req.Variables, gc.config); err != nil {
+		log.Warn("GraphQL query rejected: %v", err)
+		respond(c, http.StatusBadRequest, &gql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+
+	result := gql.Execute(gql.ExecuteParams{
+		Schema:        gc.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       context.WithValue(ctx, loadersKey{}, gc.newLoaders()),
+	})
+	result.Errors = withExtensions(result.Errors, httpShared.Locale(c))
+
+	switch {
+	case len(result.Errors) > 0:
+		httpShared.SetMaxAge(c, 0)
+	case selectsWeather(doc):
+		httpShared.SetMaxAge(c, time.Duration(gc.store.Current().HTTPCache.WeatherMaxAgeSec)*time.Second)
+	}
+	respond(c, http.StatusOK, result)
+}
+
+func bindRequest(c *gin.Context, req *GraphQLRequest) error {
+	if c.Request.Method == http.MethodPost {
+		return c.ShouldBindJSON(req)
+	}
+
+	req.Query = c.Query("query")
+	req.OperationName = c.Query("operationName")
+	if variables := c.Query("variables"); variables != "" {
+		return json.Unmarshal([]byte(variables), &req.Variables)
+	}
+	return nil
+}
+
+func respond(c *gin.Context, status int, result *gql.Result) {
+	c.JSON(status, result)
+}
@@ -0,0 +1,290 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	getWeatherByCepMocks "github.com/gerps2/desafio-cloud-run/features/weather/getWeatherByCep/mocks"
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/domain/valueObjects"
+	httpShared "github.com/gerps2/desafio-cloud-run/shared/http"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	viacep "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/viapcep"
+	viaCepMocks "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/viapcep/mocks"
+	weatherRepo "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather"
+	weatherMocks "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type graphqlTest struct {
+	router     *gin.Engine
+	viaCepRepo *viaCepMocks.MockViaCepRepositoryInterface
+	weather    *weatherMocks.MockWeatherRepositoryInterface
+	useCase    *getWeatherByCepMocks.MockGetWeatherByCepUseCaseInterface
+}
+
+type graphqlResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Path       []interface{}          `json:"path"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func setupGraphQLTest(t *testing.T, limits config.GraphQLConfig) *graphqlTest {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		Log:       config.LogConfig{Level: "error", Format: "json"},
+		GraphQL:   limits,
+		HTTPCache: config.HTTPCacheConfig{Enabled: true, WeatherMaxAgeSec: 60, AddressMaxAgeSec: 86400},
+	}
+	test := &graphqlTest{
+		router:     gin.New(),
+		viaCepRepo: viaCepMocks.NewMockViaCepRepositoryInterface(t),
+		weather:    weatherMocks.NewMockWeatherRepositoryInterface(t),
+		useCase:    getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t),
+	}
+	log := logger.NewWithWriter(io.Discard, cfg.Log, logger.NewLevel(cfg))
+
+	controller, err := NewGraphQLController(test.useCase, test.viaCepRepo, test.weather, config.NewStore(cfg, config.Options{}), log)
+	require.NoError(t, err)
+	test.router.Use(httpShared.LocaleMiddleware())
+	controller.RegisterRoutes(test.router)
+
+	return test
+}
+
+func defaultLimits() config.GraphQLConfig {
+	return config.GraphQLConfig{Enabled: true, MaxDepth: 5, MaxComplexity: 200, MaxListSize: 20}
+}
+
+func (gt *graphqlTest) expectAddress(cep, city string) {
+	gt.viaCepRepo.EXPECT().GetAddress(mock.Anything, valueObjects.Cep(cep)).
+		Return(&viacep.ViaCepResponse{Cep: cep, City: city, State: "SP"}, nil).Once()
+}
+
+func (gt *graphqlTest) expectWeather(city string, tempC float64) {
+	response := &weatherRepo.WeatherResponse{}
+	response.Current.TempC = tempC
+	response.Current.TempF = tempC*1.8 + 32
+	gt.weather.EXPECT().GetWeather(mock.Anything, city).Return(response, nil).Once()
+}
+
+func (gt *graphqlTest) post(t *testing.T, query string, variables map[string]interface{}) (int, graphqlResponse) {
+	t.Helper()
+	body, err := json.Marshal(GraphQLRequest{Query: query, Variables: variables})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	gt.router.ServeHTTP(w, req)
+
+	var response graphqlResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w.Code, response
+}
+
+func TestGraphQLAddressWithNestedWeather(t *testing.T) {
+	gt := setupGraphQLTest(t, defaultLimits())
+	gt.expectAddress("01001-000", "São Paulo")
+	gt.expectWeather("São Paulo", 25)
+
+	code, response := gt.post(t, `{ address(cep: "01001000") { cep city weather { tempC tempK } } }`, nil)
+
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, response.Errors)
+	assert.Equal(t, map[string]interface{}{
+		"cep":     "01001-000",
+		"city":    "São Paulo",
+		"weather": map[string]interface{}{"tempC": 25.0, "tempK": 298.15},
+	}, response.Data["address"])
+}
+
+func TestGraphQLAddressesAreDeduplicated(t *testing.T) {
+	gt := setupGraphQLTest(t, defaultLimits())
+	// Each upstream lookup is expected once: the repeated CEP and the city
+	// shared by two CEPs must not cause extra calls.
+	gt.expectAddress("01001-000", "São Paulo")
+	gt.expectAddress("01310-100", "São Paulo")
+	gt.expectAddress("20040-002", "Rio de Janeiro")
+	gt.expectWeather("São Paulo", 25)
+	gt.expectWeather("Rio de Janeiro", 30)
+
+	code, response := gt.post(t, `query($ceps: [String!]!) { addresses(ceps: $ceps) { cep weather { tempC } } }`,
+		map[string]interface{}{"ceps": []string{"01001000", "01310-100", "20040002", "01001-000"}})
+
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, response.Errors)
+	addresses := response.Data["addresses"].([]interface{})
+	require.Len(t, addresses, 4)
+	assert.Equal(t, 30.0, addresses[2].(map[string]interface{})["weather"].(map[string]interface{})["tempC"])
+	assert.Equal(t, addresses[0], addresses[3])
+}
+
+func TestGraphQLWeatherDelegatesToUseCase(t *testing.T) {
+	gt := setupGraphQLTest(t, defaultLimits())
+	gt.useCase.EXPECT().Execute(mock.Anything, mock.Anything).Return(nil, errors.New("boom")).Once()
+
+	code, response := gt.post(t, `{ a: weather(cep: "01001000") { tempC } b: weather(cep: "01001-000") { tempC } }`, nil)
+
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, response.Errors, 2)
+	assert.Equal(t, "INTERNAL_SERVER_ERROR", response.Errors[0].Extensions["code"])
+}
+
+func TestGraphQLReportsAPIErrorCodes(t *testing.T) {
+	gt := setupGraphQLTest(t, defaultLimits())
+	gt.viaCepRepo.EXPECT().GetAddress(mock.Anything, valueObjects.Cep("99999-999")).Return(nil, viacep.ErrZipcodeNotFound).Once()
+
+	code, response := gt.post(t, `{ address(cep: "99999999") { city } invalid: address(cep: "123") { city } }`, nil)
+
+	require.Equal(t, http.StatusOK, code)
+	assert.Nil(t, response.Data["address"])
+	assert.Nil(t, response.Data["invalid"])
+	codes := map[string]interface{}{}
+	for _, e := range response.Errors {
+		codes[e.Path[0].(string)] = e.Extensions["code"]
+	}
+	assert.Equal(t, map[string]interface{}{"address": "ZIPCODE_NOT_FOUND", "invalid": "INVALID_ZIPCODE"}, codes)
+}
+
+func TestGraphQLLocalizesErrorsAndConditions(t *testing.T) {
+	gt := setupGraphQLTest(t, defaultLimits())
+	gt.expectAddress("01001-000", "São Paulo")
+	weather := &weatherRepo.WeatherResponse{}
+	weather.Current.Condition.Text = "Light rain"
+	weather.Current.Condition.Code = 1183
+	gt.weather.EXPECT().GetWeather(mock.Anything, "São Paulo").Return(weather, nil).Once()
+
+	body, err := json.Marshal(GraphQLRequest{Query: `{ address(cep: "01001000") { weather { condition } } invalid: address(cep: "123") { city } }`})
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "pt-BR")
+	gt.router.ServeHTTP(w, req)
+
+	var response graphqlResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, map[string]interface{}{"condition": "Chuva fraca"},
+		response.Data["address"].(map[string]interface{})["weather"])
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "CEP inválido", response.Errors[0].Message)
+	assert.Equal(t, "INVALID_ZIPCODE", response.Errors[0].Extensions["code"])
+	assert.Equal(t, []interface{}{"O formato do CEP informado é inválido"}, response.Errors[0].Extensions["causes"])
+}
+
+func TestGraphQLRejectsQueriesOverTheLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   func(l *config.GraphQLConfig)
+		query    string
+		expected string
+	}{
+		{
+			name:     "Depth",
+			limits:   func(l *config.GraphQLConfig) { l.MaxDepth = 2 },
+			query:    `{ address(cep: "01001000") { weather { tempC } } }`,
+			expected: "query depth 3 exceeds the maximum of 2",
+		},
+		{
+			name:     "Complexity",
+			limits:   func(l *config.GraphQLConfig) { l.MaxComplexity = 9 },
+			query:    `{ addresses(ceps: ["01001000", "20040002", "30130000"]) { city weather { tempC } } }`,
+			expected: "query complexity 10 exceeds the maximum of 9",
+		},
+		{
+			name:     "Invalid field",
+			limits:   func(l *config.GraphQLConfig) {},
+			query:    `{ address(cep: "01001000") { population } }`,
+			expected: `Cannot query field "population" on type "Address".`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits := defaultLimits()
+			tt.limits(&limits)
+			gt := setupGraphQLTest(t, limits)
+
+			code, response := gt.post(t, tt.query, nil)
+
+			assert.Equal(t, http.StatusBadRequest, code)
+			require.NotEmpty(t, response.Errors)
+			assert.Contains(t, response.Errors[0].Message, tt.expected)
+		})
+	}
+}
+
+func TestGraphQLRejectsTooManyCeps(t *testing.T) {
+	limits := defaultLimits()
+	limits.MaxListSize = 2
+	gt := setupGraphQLTest(t, limits)
+
+	code, response := gt.post(t, `{ addresses(ceps: ["01001000", "20040002", "30130000"]) { city } }`, nil)
+
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "INVALID_INPUT", response.Errors[0].Extensions["code"])
+}
+
+func TestGraphQLGetRequest(t *testing.T) {
+	gt := setupGraphQLTest(t, defaultLimits())
+	gt.expectAddress("01001-000", "São Paulo")
+	query := url.Values{
+		"query":     {`query($cep: String!) { address(cep: $cep) { city } }`},
+		"variables": {`{"cep": "01001000"}`},
+	}
+
+	w := httptest.NewRecorder()
+	gt.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql?"+query.Encode(), nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data": {"address": {"city": "São Paulo"}}}`, w.Body.String())
+	assert.Equal(t, "private, max-age=86400", w.Header().Get("Cache-Control"))
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+}
+
+func TestGraphQLGetCacheFollowsSelection(t *testing.T) {
+	gt := setupGraphQLTest(t, defaultLimits())
+	gt.expectAddress("01001-000", "São Paulo")
+	gt.expectWeather("São Paulo", 25)
+	gt.viaCepRepo.EXPECT().GetAddress(mock.Anything, valueObjects.Cep("99999-999")).
+		Return(nil, viacep.ErrZipcodeNotFound).Once()
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		gt.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql?"+url.Values{"query": {query}}.Encode(), nil))
+		return w
+	}
+
+	weather := get(`{ address(cep: "01001000") { ...withWeather } } fragment withWeather on Address { weather { tempC } }`)
+	failed := get(`{ address(cep: "99999999") { city } }`)
+
+	assert.Equal(t, "private, max-age=60", weather.Header().Get("Cache-Control"))
+	assert.Contains(t, failed.Body.String(), "errors")
+	assert.Empty(t, failed.Header().Get("Cache-Control"))
+}
+
+func TestGraphQLDisabled(t *testing.T) {
+	gt := setupGraphQLTest(t, config.GraphQLConfig{})
+
+	w := httptest.NewRecorder()
+	gt.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,99 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// cost is the shape of a selection set: how deep it nests and how many
+// fields it resolves.
+type cost struct {
+	depth      int
+	complexity int
+}
+
+// checkLimits rejects an operation whose depth or complexity is above the
+// configured maximum. Introspection fields are free so GraphiQL-style tools
+// keep working. The document must already be validated, which rules out
+// fragment cycles.
+func checkLimits(doc *ast.Document, operationName string, variables map[string]interface{}, limits config.GraphQLConfig) error {
+	fragments := map[string]*ast.FragmentDefinition{}
+	var operation *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operation = def
+			}
+		}
+	}
+	if operation == nil {
+		return nil
+	}
+
+	c := selectionCost(operation.SelectionSet, fragments, variables)
+	if c.depth > limits.MaxDepth {
+		return fmt.Errorf("query depth %d exceeds the maximum of %d", c.depth, limits.MaxDepth)
+	}
+	if c.complexity > limits.MaxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the maximum of %d", c.complexity, limits.MaxComplexity)
+	}
+	return nil
+}
+
+func selectionCost(set *ast.SelectionSet, fragments map[string]*ast.FragmentDefinition, variables map[string]interface{}) cost {
+	var total cost
+	if set == nil {
+		return total
+	}
+
+	for _, selection := range set.Selections {
+		var c cost
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+			children := selectionCost(selection.SelectionSet, fragments, variables)
+			c = cost{
+				depth:      children.depth + 1,
+				complexity: 1 + children.complexity*listSize(selection, variables),
+			}
+		case *ast.InlineFragment:
+			c = selectionCost(selection.SelectionSet, fragments, variables)
+		case *ast.FragmentSpread:
+			if fragment, ok := fragments[selection.Name.Value]; ok {
+				c = selectionCost(fragment.SelectionSet, fragments, variables)
+			}
+		}
+
+		total.depth = max(total.depth, c.depth)
+		total.complexity += c.complexity
+	}
+	return total
+}
+
+// listSize is how many times the children of field are resolved: the length
+// of its largest list argument, or 1.
+func listSize(field *ast.Field, variables map[string]interface{}) int {
+	size := 1
+	for _, arg := range field.Arguments {
+		n := 0
+		switch value := arg.Value.(type) {
+		case *ast.ListValue:
+			n = len(value.Values)
+		case *ast.Variable:
+			if list, ok := variables[value.Name.Value].([]interface{}); ok {
+				n = len(list)
+			}
+		}
+		size = max(size, n)
+	}
+	return size
+}
@@ -0,0 +1,91 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+// Loader deduplicates and batches lookups within one GraphQL request, in the
+// style of dataloader. Load only schedules a key; the first returned thunk to
+// run fetches every key scheduled so far, concurrently, so sibling fields
+// resolved in the same pass share a single round of upstream calls.
+type Loader[K comparable, V any] struct {
+	fetch       func(ctx context.Context, key K) (V, error)
+	concurrency int
+
+	mu      sync.Mutex
+	results map[K]*loaderResult[V]
+	pending []K
+}
+
+type loaderResult[V any] struct {
+	value V
+	err   error
+	done  chan struct{}
+}
+
+func NewLoader[K comparable, V any](fetch func(ctx context.Context, key K) (V, error), concurrency int) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:       fetch,
+		concurrency: concurrency,
+		results:     map[K]*loaderResult[V]{},
+	}
+}
+
+func (l *Loader[K, V]) Load(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	result, ok := l.results[key]
+	if !ok {
+		result = &loaderResult[V]{done: make(chan struct{})}
+		l.results[key] = result
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.dispatch(ctx)
+		<-result.done
+		return result.value, result.err
+	}
+}
+
+// LoadMany schedules every key before waiting on any of them.
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) ([]V, []error) {
+	thunks := make([]func() (V, error), len(keys))
+	for i, key := range keys {
+		thunks[i] = l.Load(ctx, key)
+	}
+
+	values := make([]V, len(keys))
+	errs := make([]error, len(keys))
+	for i, thunk := range thunks {
+		values[i], errs[i] = thunk()
+	}
+	return values, errs
+}
+
+func (l *Loader[K, V]) dispatch(ctx context.Context) {
+	l.mu.Lock()
+	keys := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+
+	sem := make(chan struct{}, l.concurrency)
+	var wg sync.WaitGroup
+	for _, key := range keys {
+		l.mu.Lock()
+		result := l.results[key]
+		l.mu.Unlock()
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(key K) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result.value, result.err = l.fetch(ctx, key)
+			close(result.done)
+		}(key)
+	}
+	wg.Wait()
+}
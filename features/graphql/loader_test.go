@@ -0,0 +1,48 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoaderBatchesAndDeduplicates(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	loader := NewLoader(func(_ context.Context, key string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[key]++
+		if key == "bad" {
+			return "", errors.New("failed")
+		}
+		return "value-" + key, nil
+	}, 2)
+	ctx := context.Background()
+
+	first := loader.Load(ctx, "a")
+	second := loader.Load(ctx, "b")
+	duplicate := loader.Load(ctx, "a")
+	failing := loader.Load(ctx, "bad")
+
+	value, err := first()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "value-a", value)
+	assert.Equal(t, map[string]int{"a": 1, "b": 1, "bad": 1}, calls, "the first thunk fetches every scheduled key")
+
+	value, _ = second()
+	assert.Equal(t, "value-b", value)
+	value, _ = duplicate()
+	assert.Equal(t, "value-a", value)
+	_, err = failing()
+	assert.EqualError(t, err, "failed")
+
+	values, errs := loader.LoadMany(ctx, []string{"a", "c"})
+	assert.Equal(t, []string{"value-a", "value-c"}, values)
+	assert.Equal(t, []error{nil, nil}, errs)
+	assert.Equal(t, map[string]int{"a": 1, "b": 1, "bad": 1, "c": 1}, calls, "results are cached for the request")
+}
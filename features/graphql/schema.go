@@ -0,0 +1,232 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gerps2/desafio-cloud-run/features/weather/getWeatherByCep"
+	"github.com/gerps2/desafio-cloud-run/shared/domain/valueObjects"
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+	"github.com/gerps2/desafio-cloud-run/shared/i18n"
+	weatherRepo "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather"
+
+	gql "github.com/graphql-go/graphql"
+)
+
+// loaderConcurrency caps the upstream calls in flight for one request.
+const loaderConcurrency = 4
+
+var msgCepsPerQuery = sharedErrors.NewMessage("ceps.per_query", "at most %d CEPs are allowed per query")
+
+type address struct {
+	Cep        string `json:"cep"`
+	Street     string `json:"street"`
+	Complement string `json:"complement"`
+	District   string `json:"district"`
+	City       string `json:"city"`
+	State      string `json:"state"`
+	IbgeCode   string `json:"ibgeCode"`
+}
+
+type weather struct {
+	TempC         float64 `json:"tempC"`
+	TempF         float64 `json:"tempF"`
+	TempK         float64 `json:"tempK"`
+	Condition     string  `json:"condition"`
+	ConditionCode int     `json:"-"`
+}
+
+// loaders are created per request, so results are never shared between
+// requests or callers.
+type loaders struct {
+	addressByCep  *Loader[valueObjects.Cep, *address]
+	weatherByCity *Loader[string, *weather]
+	weatherByCep  *Loader[valueObjects.Cep, *weather]
+}
+
+type loadersKey struct{}
+
+func (gc *GraphQLController) newLoaders() *loaders {
+	return &loaders{
+		addressByCep:  NewLoader(gc.fetchAddress, loaderConcurrency),
+		weatherByCity: NewLoader(gc.fetchWeatherByCity, loaderConcurrency),
+		weatherByCep:  NewLoader(gc.fetchWeatherByCep, loaderConcurrency),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+func (gc *GraphQLController) fetchAddress(ctx context.Context, cep valueObjects.Cep) (*address, error) {
+	response, err := gc.viaCepRepo.GetAddress(ctx, cep)
+	if err != nil {
+		gc.logger.WithContext(ctx).Error("Error fetching address for CEP %s: %v", cep, err)
+		return nil, getWeatherByCep.NewAddressLookupError(err)
+	}
+
+	return &address{
+		Cep:        response.Cep,
+		Street:     response.Street,
+		Complement: response.Complement,
+		District:   response.District,
+		City:       response.City,
+		State:      response.State,
+		IbgeCode:   response.IbgeCode,
+	}, nil
+}
+
+func (gc *GraphQLController) fetchWeatherByCity(ctx context.Context, city string) (*weather, error) {
+	response, err := gc.weatherRepo.GetWeather(ctx, city)
+	if errors.Is(err, weatherRepo.ErrBudgetExceeded) {
+		gc.logger.WithContext(ctx).Warn("Weather API budget exhausted for city %s: %v", city, err)
+		return nil, getWeatherByCep.NewWeatherBudgetExceededError()
+	}
+	if err != nil {
+		gc.logger.WithContext(ctx).Error("Error fetching weather for city %s: %v", city, err)
+		return nil, getWeatherByCep.NewWeatherServiceError()
+	}
+
+	return &weather{
+		TempC:         response.Current.TempC,
+		TempF:         response.Current.TempF,
+		TempK:         response.Current.TempC + 273.15,
+		Condition:     response.Current.Condition.Text,
+		ConditionCode: response.Current.Condition.Code,
+	}, nil
+}
+
+func (gc *GraphQLController) fetchWeatherByCep(ctx context.Context, cep valueObjects.Cep) (*weather, error) {
+	output, err := gc.getWeatherByCepUseCase.Execute(ctx, getWeatherByCep.GetWeatherByCepInput{CepString: cep.String()})
+	if err != nil {
+		gc.logger.WithContext(ctx).Error("Error executing GetWeatherByCep use case: %v", err)
+		return nil, err
+	}
+
+	return &weather{
+		TempC:         output.TempC,
+		TempF:         output.TempF,
+		TempK:         output.TempK,
+		Condition:     output.Condition,
+		ConditionCode: output.ConditionCode,
+	}, nil
+}
+
+func (gc *GraphQLController) buildSchema() (gql.Schema, error) {
+	weatherType := gql.NewObject(gql.ObjectConfig{
+		Name:        "Weather",
+		Description: "Current temperature in Celsius, Fahrenheit and Kelvin, and the weather condition.",
+		Fields: gql.Fields{
+			"tempC": &gql.Field{Type: gql.NewNonNull(gql.Float)},
+			"tempF": &gql.Field{Type: gql.NewNonNull(gql.Float)},
+			"tempK": &gql.Field{Type: gql.NewNonNull(gql.Float)},
+			"condition": &gql.Field{
+				Type:        gql.String,
+				Description: "Weather condition in the language of the Accept-Language header.",
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					w := p.Source.(*weather)
+					return i18n.Condition(i18n.FromContext(p.Context), w.ConditionCode, w.Condition), nil
+				},
+			},
+		},
+	})
+
+	addressType := gql.NewObject(gql.ObjectConfig{
+		Name:        "Address",
+		Description: "Address of a CEP, as returned by ViaCep.",
+		Fields: gql.Fields{
+			"cep":        &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"street":     &gql.Field{Type: gql.String},
+			"complement": &gql.Field{Type: gql.String},
+			"district":   &gql.Field{Type: gql.String},
+			"city":       &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"state":      &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"ibgeCode":   &gql.Field{Type: gql.String},
+			"weather": &gql.Field{
+				Type:        weatherType,
+				Description: "Current weather in the city of the address.",
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					city := p.Source.(*address).City
+					return thunk(loadersFrom(p.Context).weatherByCity.Load(p.Context, city)), nil
+				},
+			},
+		},
+	})
+
+	cepArg := gql.FieldConfigArgument{
+		"cep": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String), Description: "8-digit CEP, with or without hyphen"},
+	}
+
+	query := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"address": &gql.Field{
+				Type: addressType,
+				Args: cepArg,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					cep, err := valueObjects.NewCep(p.Args["cep"].(string))
+					if err != nil {
+						return nil, toResolverError(getWeatherByCep.NewInvalidZipcodeError())
+					}
+					return thunk(loadersFrom(p.Context).addressByCep.Load(p.Context, cep)), nil
+				},
+			},
+			"addresses": &gql.Field{
+				Type:        gql.NewList(addressType),
+				Description: "Addresses of several CEPs; a CEP that fails is null with its own error.",
+				Args: gql.FieldConfigArgument{
+					"ceps": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(gql.String)))},
+				},
+				Resolve: gc.resolveAddresses,
+			},
+			"weather": &gql.Field{
+				Type:        weatherType,
+				Description: "Current weather for a CEP, same as GET /api/v1/weather/{cep}.",
+				Args:        cepArg,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					cep, err := valueObjects.NewCep(p.Args["cep"].(string))
+					if err != nil {
+						return nil, toResolverError(getWeatherByCep.NewInvalidZipcodeError())
+					}
+					return thunk(loadersFrom(p.Context).weatherByCep.Load(p.Context, cep)), nil
+				},
+			},
+		},
+	})
+
+	return gql.NewSchema(gql.SchemaConfig{Query: query})
+}
+
+// resolveAddresses returns one entry per CEP. Each entry is a thunk so a
+// failed CEP yields a null item with an error at its own path.
+func (gc *GraphQLController) resolveAddresses(p gql.ResolveParams) (interface{}, error) {
+	raw := p.Args["ceps"].([]interface{})
+	if len(raw) > gc.config.MaxListSize {
+		return nil, toResolverError(getWeatherByCep.NewWeatherValidationError(getWeatherByCep.MsgTooManyCeps.Format(),
+			[]sharedErrors.Text{msgCepsPerQuery.Format(gc.config.MaxListSize)}))
+	}
+
+	loader := loadersFrom(p.Context).addressByCep
+	items := make([]interface{}, len(raw))
+	for i, value := range raw {
+		cep, err := valueObjects.NewCep(value.(string))
+		if err != nil {
+			items[i] = thunk(func() (*address, error) { return nil, getWeatherByCep.NewInvalidZipcodeError() })
+			continue
+		}
+		items[i] = thunk(loader.Load(p.Context, cep))
+	}
+	return items, nil
+}
+
+// thunk adapts a loader thunk to graphql-go, which resolves values of this
+// function type after the rest of the level, and maps its error.
+func thunk[V any](load func() (*V, error)) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		value, err := load()
+		if err != nil {
+			return nil, toResolverError(err)
+		}
+		return value, nil
+	}
+}
@@ -1,9 +1,11 @@
 package getWeatherByCep
 
 import (
+	"errors"
 	"net/http"
 
 	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+	viacep "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/viapcep"
 )
 
 const (
@@ -12,39 +14,77 @@ const (
 	CodeWeatherServiceError = "WEATHER_SERVICE_ERROR"
 )
 
+var (
+	msgInvalidZipcode       = sharedErrors.NewMessage("zipcode.invalid", "invalid zipcode")
+	msgInvalidZipcodeFormat = sharedErrors.NewMessage("zipcode.invalid_format", "The provided zipcode format is invalid")
+	msgZipcodeNotFound      = sharedErrors.NewMessage("zipcode.not_found", "can not find zipcode")
+	msgZipcodeNotFoundCause = sharedErrors.NewMessage("zipcode.not_found_cause", "The provided zipcode was not found")
+	msgAddressUnavailable   = sharedErrors.NewMessage("address.unavailable", "Address service temporarily unavailable")
+	msgAddressFetchFailed   = sharedErrors.NewMessage("address.fetch_failed", "Unable to fetch the address from external service")
+	msgWeatherUnavailable   = sharedErrors.NewMessage("weather.unavailable", "Weather service temporarily unavailable")
+	msgWeatherFetchFailed   = sharedErrors.NewMessage("weather.fetch_failed", "Unable to fetch weather data from external service")
+	msgWeatherBudget        = sharedErrors.NewMessage("weather.budget_exhausted", "The weather API call budget is exhausted, try again later")
+
+	// MsgTooManyCeps rejects lookups of more CEPs than a transport allows.
+	MsgTooManyCeps = sharedErrors.NewMessage("ceps.too_many", "Too many CEPs")
+)
+
 func NewInvalidZipcodeError() *sharedErrors.APIError {
 	return sharedErrors.NewAPIError(
 		CodeInvalidZipcode,
-		"invalid zipcode",
+		msgInvalidZipcode.Format(),
 		http.StatusUnprocessableEntity,
-		[]string{"The provided zipcode format is invalid"},
+		[]sharedErrors.Text{msgInvalidZipcodeFormat.Format()},
 	)
 }
 
 func NewZipcodeNotFoundError() *sharedErrors.APIError {
 	return sharedErrors.NewAPIError(
 		CodeZipcodeNotFound,
-		"can not find zipcode",
+		msgZipcodeNotFound.Format(),
 		http.StatusNotFound,
-		[]string{"The provided zipcode was not found"},
+		[]sharedErrors.Text{msgZipcodeNotFoundCause.Format()},
+	)
+}
+
+func NewAddressServiceError() *sharedErrors.APIError {
+	return sharedErrors.NewExternalServiceError(
+		msgAddressUnavailable.Format(),
+		[]sharedErrors.Text{msgAddressFetchFailed.Format()},
 	)
 }
 
+// NewAddressLookupError answers a failed ViaCEP lookup: 404 when ViaCEP does
+// not know the CEP, 502 when it could not be asked.
+func NewAddressLookupError(err error) *sharedErrors.APIError {
+	if errors.Is(err, viacep.ErrZipcodeNotFound) {
+		return NewZipcodeNotFoundError()
+	}
+	return NewAddressServiceError()
+}
+
 func NewWeatherServiceError() *sharedErrors.APIError {
 	return sharedErrors.NewExternalServiceError(
-		"Weather service temporarily unavailable",
-		[]string{"Unable to fetch weather data from external service"},
+		msgWeatherUnavailable.Format(),
+		[]sharedErrors.Text{msgWeatherFetchFailed.Format()},
+	)
+}
+
+func NewWeatherBudgetExceededError() *sharedErrors.APIError {
+	return sharedErrors.NewServiceUnavailableError(
+		msgWeatherUnavailable.Format(),
+		[]sharedErrors.Text{msgWeatherBudget.Format()},
 	)
 }
 
-func NewWeatherValidationError(message string, causes []string) *sharedErrors.APIError {
+func NewWeatherValidationError(message sharedErrors.Text, causes []sharedErrors.Text) *sharedErrors.APIError {
 	return sharedErrors.NewValidationError(message, causes)
 }
 
-func NewWeatherBusinessError(code, message string, causes []string) *sharedErrors.APIError {
+func NewWeatherBusinessError(code string, message sharedErrors.Text, causes []sharedErrors.Text) *sharedErrors.APIError {
 	return sharedErrors.NewBusinessError(code, message, causes)
 }
 
-func NewWeatherExternalError(message string, causes []string) *sharedErrors.APIError {
+func NewWeatherExternalError(message sharedErrors.Text, causes []sharedErrors.Text) *sharedErrors.APIError {
 	return sharedErrors.NewExternalServiceError(message, causes)
 }
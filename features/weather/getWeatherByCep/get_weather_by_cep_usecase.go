@@ -2,21 +2,48 @@ package getWeatherByCep
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/gerps2/desafio-cloud-run/shared/domain/valueObjects"
 	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
 	viacep "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/viapcep"
 	weather "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather"
+	"github.com/gerps2/desafio-cloud-run/shared/telemetry"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
+const tracerName = "github.com/gerps2/desafio-cloud-run/features/weather/getWeatherByCep"
+
 type GetWeatherByCepInput struct {
 	CepString string
 }
 
+// GetWeatherByCepOutput is shared by every API version; the controller's
+// presenters decide which fields each version exposes and how they are named.
 type GetWeatherByCepOutput struct {
-	TempC float64 `json:"temp_C"`
-	TempF float64 `json:"temp_F"`
-	TempK float64 `json:"temp_K"`
+	Cep   string
+	City  string
+	State string
+	// Region, Country and the coordinates are the weather provider's
+	// resolution of City.
+	Region    string
+	Country   string
+	Latitude  float64
+	Longitude float64
+
+	TempC float64
+	TempF float64
+	TempK float64
+	// Condition is the provider's description; ConditionCode is what the
+	// presenters look up in the request's locale.
+	Condition     string
+	ConditionCode int
+	// ObservedAt is when the upstream measured the temperature; zero when
+	// unknown.
+	ObservedAt time.Time
 }
 
 type GetWeatherByCepUseCase interface {
@@ -24,49 +51,93 @@ type GetWeatherByCepUseCase interface {
 }
 
 type getWeatherByCepUseCase struct {
-	viaCepRepo    viacep.ViaCepRepositoryInterface
-	weatherRepo   weather.WeatherRepositoryInterface
-	logger        logger.Logger
+	viaCepRepo  viacep.ViaCepRepositoryInterface
+	weatherRepo weather.WeatherRepositoryInterface
+	logger      logger.Logger
+	tracer      trace.Tracer
+	metrics     *metrics.Metrics
 }
 
-func NewGetWeatherByCepUseCase(viaCepRepo viacep.ViaCepRepositoryInterface, weatherRepo weather.WeatherRepositoryInterface, logger logger.Logger) GetWeatherByCepUseCaseInterface {
+func NewGetWeatherByCepUseCase(
+	viaCepRepo viacep.ViaCepRepositoryInterface,
+	weatherRepo weather.WeatherRepositoryInterface,
+	logger logger.Logger,
+	tel *telemetry.Telemetry,
+	m *metrics.Metrics,
+) GetWeatherByCepUseCaseInterface {
 	return &getWeatherByCepUseCase{
 		viaCepRepo:  viaCepRepo,
 		weatherRepo: weatherRepo,
 		logger:      logger,
+		tracer:      tel.Tracer(tracerName),
+		metrics:     m,
 	}
 }
 
-func (gwbc *getWeatherByCepUseCase) Execute(ctx context.Context, input GetWeatherByCepInput) (*GetWeatherByCepOutput, error) {
-	gwbc.logger.Debug("Executing get weather by cep use case for CEP: %s", input.CepString)
+func (gwbc *getWeatherByCepUseCase) Execute(ctx context.Context, input GetWeatherByCepInput) (_ *GetWeatherByCepOutput, err error) {
+	ctx, span := gwbc.tracer.Start(ctx, "GetWeatherByCepUseCase.Execute",
+		trace.WithAttributes(telemetry.AttrCep.String(input.CepString)),
+	)
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	log := gwbc.logger.WithContext(ctx)
+	log.Debug("Executing get weather by cep use case for CEP: %s", input.CepString)
 
 	cep, err := valueObjects.NewCep(input.CepString)
 	if err != nil {
-		gwbc.logger.Error("Invalid CEP format: %s", input.CepString)
+		log.Error("Invalid CEP format: %s", input.CepString)
+		gwbc.metrics.IncWeatherLookup(metrics.LookupInvalidCep)
 		return nil, NewInvalidZipcodeError()
 	}
 
 	address, err := gwbc.viaCepRepo.GetAddress(ctx, cep)
 	if err != nil {
-		gwbc.logger.Error("Error fetching address for CEP %s: %v", input.CepString, err)
-		return nil, NewZipcodeNotFoundError()
+		log.Error("Error fetching address for CEP %s: %v", input.CepString, err)
+		if errors.Is(err, viacep.ErrZipcodeNotFound) {
+			gwbc.metrics.IncWeatherLookup(metrics.LookupCepNotFound)
+		} else {
+			gwbc.metrics.IncWeatherLookup(metrics.LookupAddressFailure)
+		}
+		return nil, NewAddressLookupError(err)
 	}
 
-	gwbc.logger.Info("Address found for CEP %s: %s, %s", input.CepString, address.City, address.State)
+	log.Info("Address found for CEP %s: %s, %s", input.CepString, address.City, address.State)
+	span.SetAttributes(telemetry.AttrCity.String(address.City))
 
 	weatherData, err := gwbc.weatherRepo.GetWeather(ctx, address.City)
+	if errors.Is(err, weather.ErrBudgetExceeded) {
+		log.Warn("Weather API budget exhausted for city %s: %v", address.City, err)
+		gwbc.metrics.IncWeatherLookup(metrics.LookupBudgetExceeded)
+		return nil, NewWeatherBudgetExceededError()
+	}
 	if err != nil {
-		gwbc.logger.Error("Error fetching weather for city %s: %v", address.City, err)
+		log.Error("Error fetching weather for city %s: %v", address.City, err)
+		gwbc.metrics.IncWeatherLookup(metrics.LookupWeatherFailure)
 		return nil, NewWeatherServiceError()
 	}
 
-	gwbc.logger.Info("Weather data found for city %s: %.1f°C", address.City, weatherData.Current.TempC)
+	log.Info("Weather data found for city %s: %.1f°C", address.City, weatherData.Current.TempC)
+
+	gwbc.metrics.IncWeatherLookup(metrics.LookupSuccess)
 
 	tempKelvin := weatherData.Current.TempC + 273.15
 
-	return &GetWeatherByCepOutput{
-		TempC: weatherData.Current.TempC,
-		TempF: weatherData.Current.TempF,
-		TempK: tempKelvin,
-	}, nil
+	output := &GetWeatherByCepOutput{
+		Cep:           cep.String(),
+		City:          address.City,
+		State:         address.State,
+		Region:        weatherData.Location.Region,
+		Country:       weatherData.Location.Country,
+		Latitude:      weatherData.Location.Lat,
+		Longitude:     weatherData.Location.Lon,
+		TempC:         weatherData.Current.TempC,
+		TempF:         weatherData.Current.TempF,
+		TempK:         tempKelvin,
+		Condition:     weatherData.Current.Condition.Text,
+		ConditionCode: weatherData.Current.Condition.Code,
+	}
+	if epoch := weatherData.Current.LastUpdatedEpoch; epoch > 0 {
+		output.ObservedAt = time.Unix(epoch, 0).UTC()
+	}
+	return output, nil
 }
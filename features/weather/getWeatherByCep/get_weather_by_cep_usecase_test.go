@@ -3,15 +3,22 @@ package getWeatherByCep
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
 	loggerMocks "github.com/gerps2/desafio-cloud-run/shared/logger/mocks"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
 	viacep "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/viapcep"
 	viacepMocks "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/viapcep/mocks"
 	weather "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather"
 	weatherMocks "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather/mocks"
+	"github.com/gerps2/desafio-cloud-run/shared/telemetry"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -21,6 +28,7 @@ func TestGetWeatherByCepUseCaseExecuteSuccess(t *testing.T) {
 	mockViaCepRepo := viacepMocks.NewMockViaCepRepositoryInterface(t)
 	mockWeatherRepo := weatherMocks.NewMockWeatherRepositoryInterface(t)
 	mockLogger := loggerMocks.NewMockLogger(t)
+	mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
 
 	expectedAddress := &viacep.ViaCepResponse{
 		Cep:        "12345-678",
@@ -36,25 +44,34 @@ func TestGetWeatherByCepUseCaseExecuteSuccess(t *testing.T) {
 
 	expectedWeather := &weather.WeatherResponse{
 		Location: struct {
-			Name    string `json:"name"`
-			Region  string `json:"region"`
-			Country string `json:"country"`
+			Name    string  `json:"name"`
+			Region  string  `json:"region"`
+			Country string  `json:"country"`
+			Lat     float64 `json:"lat"`
+			Lon     float64 `json:"lon"`
 		}{
 			Name:    "São Paulo",
 			Region:  "Sao Paulo",
 			Country: "Brazil",
+			Lat:     -23.53,
+			Lon:     -46.62,
 		},
 		Current: struct {
-			TempC     float64 `json:"temp_c"`
-			TempF     float64 `json:"temp_f"`
-			Condition struct {
+			TempC            float64 `json:"temp_c"`
+			TempF            float64 `json:"temp_f"`
+			LastUpdatedEpoch int64   `json:"last_updated_epoch"`
+			Condition        struct {
 				Text string `json:"text"`
+				Code int    `json:"code"`
 			} `json:"condition"`
 		}{
-			TempC: 25.5,
-			TempF: 77.9,
+			TempC:            25.5,
+			TempF:            77.9,
+			LastUpdatedEpoch: 1760870700,
 		},
 	}
+	expectedWeather.Current.Condition.Text = "Partly cloudy"
+	expectedWeather.Current.Condition.Code = 1003
 
 	mockLogger.EXPECT().Debug("Executing get weather by cep use case for CEP: %s", "12345-678").Once()
 	mockLogger.EXPECT().Info("Address found for CEP %s: %s, %s", "12345-678", "São Paulo", "SP").Once()
@@ -63,7 +80,7 @@ func TestGetWeatherByCepUseCaseExecuteSuccess(t *testing.T) {
 	mockViaCepRepo.EXPECT().GetAddress(mock.Anything, mock.AnythingOfType("valueObjects.Cep")).Return(expectedAddress, nil).Once()
 	mockWeatherRepo.EXPECT().GetWeather(mock.Anything, "São Paulo").Return(expectedWeather, nil).Once()
 
-	useCase := NewGetWeatherByCepUseCase(mockViaCepRepo, mockWeatherRepo, mockLogger)
+	useCase := NewGetWeatherByCepUseCase(mockViaCepRepo, mockWeatherRepo, mockLogger, telemetry.NewNoop(), metrics.New())
 
 	input := GetWeatherByCepInput{
 		CepString: "12345-678",
@@ -78,6 +95,16 @@ func TestGetWeatherByCepUseCaseExecuteSuccess(t *testing.T) {
 	assert.Equal(t, 25.5, result.TempC)
 	assert.Equal(t, 77.9, result.TempF)
 	assert.Equal(t, 298.65, result.TempK) // 25.5 + 273.15
+	assert.Equal(t, time.Unix(1760870700, 0).UTC(), result.ObservedAt)
+	assert.Equal(t, "Partly cloudy", result.Condition)
+	assert.Equal(t, 1003, result.ConditionCode)
+	assert.Equal(t, "12345-678", result.Cep)
+	assert.Equal(t, "São Paulo", result.City)
+	assert.Equal(t, "SP", result.State)
+	assert.Equal(t, "Sao Paulo", result.Region)
+	assert.Equal(t, "Brazil", result.Country)
+	assert.Equal(t, -23.53, result.Latitude)
+	assert.Equal(t, -46.62, result.Longitude)
 
 	mockViaCepRepo.AssertExpectations(t)
 	mockWeatherRepo.AssertExpectations(t)
@@ -89,11 +116,13 @@ func TestGetWeatherByCepUseCaseExecuteInvalidCEP(t *testing.T) {
 	mockViaCepRepo := viacepMocks.NewMockViaCepRepositoryInterface(t)
 	mockWeatherRepo := weatherMocks.NewMockWeatherRepositoryInterface(t)
 	mockLogger := loggerMocks.NewMockLogger(t)
+	mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
 
 	mockLogger.EXPECT().Debug("Executing get weather by cep use case for CEP: %s", "invalid-cep").Once()
 	mockLogger.EXPECT().Error("Invalid CEP format: %s", "invalid-cep").Once()
 
-	useCase := NewGetWeatherByCepUseCase(mockViaCepRepo, mockWeatherRepo, mockLogger)
+	m := metrics.New()
+	useCase := NewGetWeatherByCepUseCase(mockViaCepRepo, mockWeatherRepo, mockLogger, telemetry.NewNoop(), m)
 
 	input := GetWeatherByCepInput{
 		CepString: "invalid-cep",
@@ -111,6 +140,13 @@ func TestGetWeatherByCepUseCaseExecuteInvalidCEP(t *testing.T) {
 	assert.Equal(t, CodeInvalidZipcode, apiErr.Code)
 	assert.Equal(t, "invalid zipcode", apiErr.Message)
 
+	expectedLookups := `
+# HELP weather_api_weather_lookups_total Weather by CEP lookups, by result.
+# TYPE weather_api_weather_lookups_total counter
+weather_api_weather_lookups_total{result="invalid_cep"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(m.Registry(), strings.NewReader(expectedLookups), "weather_api_weather_lookups_total"))
+
 	mockLogger.AssertExpectations(t)
 	mockViaCepRepo.AssertNotCalled(t, "GetAddress")
 	mockWeatherRepo.AssertNotCalled(t, "GetWeather")
@@ -121,13 +157,14 @@ func TestGetWeatherByCepUseCaseExecuteAddressNotFound(t *testing.T) {
 	mockViaCepRepo := viacepMocks.NewMockViaCepRepositoryInterface(t)
 	mockWeatherRepo := weatherMocks.NewMockWeatherRepositoryInterface(t)
 	mockLogger := loggerMocks.NewMockLogger(t)
+	mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
 
 	mockLogger.EXPECT().Debug("Executing get weather by cep use case for CEP: %s", "99999-999").Once()
 	mockLogger.EXPECT().Error("Error fetching address for CEP %s: %v", "99999-999", mock.AnythingOfType("*errors.errorString")).Once()
 
-	mockViaCepRepo.EXPECT().GetAddress(mock.Anything, mock.AnythingOfType("valueObjects.Cep")).Return(nil, errors.New("address not found")).Once()
+	mockViaCepRepo.EXPECT().GetAddress(mock.Anything, mock.AnythingOfType("valueObjects.Cep")).Return(nil, viacep.ErrZipcodeNotFound).Once()
 
-	useCase := NewGetWeatherByCepUseCase(mockViaCepRepo, mockWeatherRepo, mockLogger)
+	useCase := NewGetWeatherByCepUseCase(mockViaCepRepo, mockWeatherRepo, mockLogger, telemetry.NewNoop(), metrics.New())
 
 	input := GetWeatherByCepInput{
 		CepString: "99999-999",
@@ -150,11 +187,54 @@ func TestGetWeatherByCepUseCaseExecuteAddressNotFound(t *testing.T) {
 	mockWeatherRepo.AssertNotCalled(t, "GetWeather")
 }
 
+func TestGetWeatherByCepUseCaseExecuteAddressServiceError(t *testing.T) {
+	// Arrange
+	mockViaCepRepo := viacepMocks.NewMockViaCepRepositoryInterface(t)
+	mockWeatherRepo := weatherMocks.NewMockWeatherRepositoryInterface(t)
+	mockLogger := loggerMocks.NewMockLogger(t)
+	mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
+
+	mockLogger.EXPECT().Debug("Executing get weather by cep use case for CEP: %s", "01001-000").Once()
+	mockLogger.EXPECT().Error("Error fetching address for CEP %s: %v", "01001-000", mock.AnythingOfType("*errors.errorString")).Once()
+
+	mockViaCepRepo.EXPECT().GetAddress(mock.Anything, mock.AnythingOfType("valueObjects.Cep")).Return(nil, errors.New("failed to fetch address")).Once()
+
+	m := metrics.New()
+	useCase := NewGetWeatherByCepUseCase(mockViaCepRepo, mockWeatherRepo, mockLogger, telemetry.NewNoop(), m)
+
+	input := GetWeatherByCepInput{
+		CepString: "01001-000",
+	}
+
+	// Act
+	result, err := useCase.Execute(context.Background(), input)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+
+	apiErr, ok := err.(*sharedErrors.APIError)
+	assert.True(t, ok, "Expected APIError")
+	assert.Equal(t, sharedErrors.CodeExternalService, apiErr.Code)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+
+	expectedLookups := `
+# HELP weather_api_weather_lookups_total Weather by CEP lookups, by result.
+# TYPE weather_api_weather_lookups_total counter
+weather_api_weather_lookups_total{result="address_failure"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(m.Registry(), strings.NewReader(expectedLookups), "weather_api_weather_lookups_total"))
+
+	mockLogger.AssertExpectations(t)
+	mockWeatherRepo.AssertNotCalled(t, "GetWeather")
+}
+
 func TestGetWeatherByCepUseCaseExecuteWeatherServiceError(t *testing.T) {
 	// Arrange
 	mockViaCepRepo := viacepMocks.NewMockViaCepRepositoryInterface(t)
 	mockWeatherRepo := weatherMocks.NewMockWeatherRepositoryInterface(t)
 	mockLogger := loggerMocks.NewMockLogger(t)
+	mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
 
 	expectedAddress := &viacep.ViaCepResponse{
 		Cep:   "12345-678",
@@ -169,7 +249,7 @@ func TestGetWeatherByCepUseCaseExecuteWeatherServiceError(t *testing.T) {
 	mockViaCepRepo.EXPECT().GetAddress(mock.Anything, mock.AnythingOfType("valueObjects.Cep")).Return(expectedAddress, nil).Once()
 	mockWeatherRepo.EXPECT().GetWeather(mock.Anything, "São Paulo").Return(nil, errors.New("weather service error")).Once()
 
-	useCase := NewGetWeatherByCepUseCase(mockViaCepRepo, mockWeatherRepo, mockLogger)
+	useCase := NewGetWeatherByCepUseCase(mockViaCepRepo, mockWeatherRepo, mockLogger, telemetry.NewNoop(), metrics.New())
 
 	input := GetWeatherByCepInput{
 		CepString: "12345-678",
@@ -191,11 +271,56 @@ func TestGetWeatherByCepUseCaseExecuteWeatherServiceError(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
+func TestGetWeatherByCepUseCaseExecuteWeatherBudgetExceeded(t *testing.T) {
+	// Arrange
+	mockViaCepRepo := viacepMocks.NewMockViaCepRepositoryInterface(t)
+	mockWeatherRepo := weatherMocks.NewMockWeatherRepositoryInterface(t)
+	mockLogger := loggerMocks.NewMockLogger(t)
+	mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
+
+	expectedAddress := &viacep.ViaCepResponse{
+		Cep:   "12345-678",
+		City:  "São Paulo",
+		State: "SP",
+	}
+	budgetErr := fmt.Errorf("%w: 1000 calls per month used", weather.ErrBudgetExceeded)
+
+	mockLogger.EXPECT().Debug("Executing get weather by cep use case for CEP: %s", "12345-678").Once()
+	mockLogger.EXPECT().Info("Address found for CEP %s: %s, %s", "12345-678", "São Paulo", "SP").Once()
+	mockLogger.EXPECT().Warn("Weather API budget exhausted for city %s: %v", "São Paulo", budgetErr).Once()
+
+	mockViaCepRepo.EXPECT().GetAddress(mock.Anything, mock.AnythingOfType("valueObjects.Cep")).Return(expectedAddress, nil).Once()
+	mockWeatherRepo.EXPECT().GetWeather(mock.Anything, "São Paulo").Return(nil, budgetErr).Once()
+
+	useCase := NewGetWeatherByCepUseCase(mockViaCepRepo, mockWeatherRepo, mockLogger, telemetry.NewNoop(), metrics.New())
+
+	input := GetWeatherByCepInput{
+		CepString: "12345-678",
+	}
+
+	// Act
+	result, err := useCase.Execute(context.Background(), input)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+
+	apiErr, ok := err.(*sharedErrors.APIError)
+	assert.True(t, ok, "Expected APIError")
+	assert.Equal(t, "SERVICE_UNAVAILABLE", apiErr.Code)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+
+	mockViaCepRepo.AssertExpectations(t)
+	mockWeatherRepo.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+}
+
 func TestGetWeatherByCepUseCaseExecuteContextCancellation(t *testing.T) {
 	// Arrange
 	mockViaCepRepo := viacepMocks.NewMockViaCepRepositoryInterface(t)
 	mockWeatherRepo := weatherMocks.NewMockWeatherRepositoryInterface(t)
 	mockLogger := loggerMocks.NewMockLogger(t)
+	mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
@@ -205,7 +330,7 @@ func TestGetWeatherByCepUseCaseExecuteContextCancellation(t *testing.T) {
 
 	mockViaCepRepo.EXPECT().GetAddress(mock.Anything, mock.AnythingOfType("valueObjects.Cep")).Return(nil, context.Canceled).Once()
 
-	useCase := NewGetWeatherByCepUseCase(mockViaCepRepo, mockWeatherRepo, mockLogger)
+	useCase := NewGetWeatherByCepUseCase(mockViaCepRepo, mockWeatherRepo, mockLogger, telemetry.NewNoop(), metrics.New())
 
 	input := GetWeatherByCepInput{
 		CepString: "12345-678",
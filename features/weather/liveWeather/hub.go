@@ -0,0 +1,291 @@
+package liveWeather
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/features/weather/getWeatherByCep"
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/domain/valueObjects"
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+	viacep "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/viapcep"
+	weatherRepo "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather"
+)
+
+var (
+	msgShuttingDown        = sharedErrors.NewMessage("live.shutting_down", "Server is shutting down")
+	msgSubscriptionsClosed = sharedErrors.NewMessage("live.subscriptions_closed", "Live weather subscriptions are closed")
+)
+
+type Reading struct {
+	Cep   string  `json:"cep"`
+	City  string  `json:"city"`
+	TempC float64 `json:"temp_C"`
+	TempF float64 `json:"temp_F"`
+	TempK float64 `json:"temp_K"`
+	// Condition is the provider's description; subscribers localize it from
+	// ConditionCode.
+	Condition     string    `json:"condition,omitempty"`
+	ConditionCode int       `json:"-"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Update carries either a new reading or the error that replaced it.
+type Update struct {
+	Cep     string
+	Reading *Reading
+	Err     *sharedErrors.APIError
+}
+
+// Hub polls the weather of every subscribed city, once per interval no matter
+// how many subscribers share the city, and notifies them only when the
+// reading changes. A city stops being polled with its last unsubscribe.
+type Hub struct {
+	viaCepRepo  viacep.ViaCepRepositoryInterface
+	weatherRepo weatherRepo.WeatherRepositoryInterface
+	interval    time.Duration
+	metrics     *metrics.Metrics
+	logger      logger.Logger
+
+	mu            sync.Mutex
+	feeds         map[string]*feed
+	subscriptions int
+	done          chan struct{}
+}
+
+type feed struct {
+	city    string
+	subs    map[*Subscription]struct{}
+	cancel  context.CancelFunc
+	last    *weatherRepo.WeatherResponse
+	at      time.Time
+	lastErr *sharedErrors.APIError
+}
+
+func NewHub(cfg *config.Config, viaCepRepo viacep.ViaCepRepositoryInterface, weatherRepo weatherRepo.WeatherRepositoryInterface, m *metrics.Metrics, log logger.Logger) *Hub {
+	return newHub(viaCepRepo, weatherRepo, time.Duration(cfg.Stream.PollIntervalSec)*time.Second, m, log)
+}
+
+func newHub(viaCepRepo viacep.ViaCepRepositoryInterface, weatherRepo weatherRepo.WeatherRepositoryInterface, interval time.Duration, m *metrics.Metrics, log logger.Logger) *Hub {
+	return &Hub{
+		viaCepRepo:  viaCepRepo,
+		weatherRepo: weatherRepo,
+		interval:    interval,
+		metrics:     m,
+		logger:      log,
+		feeds:       map[string]*feed{},
+		done:        make(chan struct{}),
+	}
+}
+
+// Subscribe resolves the city of cep and joins its feed. The latest reading,
+// if any, is delivered right away.
+func (h *Hub) Subscribe(ctx context.Context, cepString string) (*Subscription, error) {
+	cep, err := valueObjects.NewCep(cepString)
+	if err != nil {
+		return nil, getWeatherByCep.NewInvalidZipcodeError()
+	}
+
+	address, err := h.viaCepRepo.GetAddress(ctx, cep)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("Error fetching address for CEP %s: %v", cep, err)
+		return nil, getWeatherByCep.NewAddressLookupError(err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	select {
+	case <-h.done:
+		return nil, sharedErrors.NewServiceUnavailableError(msgShuttingDown.Format(), []sharedErrors.Text{msgSubscriptionsClosed.Format()})
+	default:
+	}
+
+	f, ok := h.feeds[address.City]
+	if !ok {
+		pollCtx, cancel := context.WithCancel(context.Background())
+		f = &feed{city: address.City, subs: map[*Subscription]struct{}{}, cancel: cancel}
+		h.feeds[address.City] = f
+		go h.poll(logger.ContextWithFields(pollCtx, "city", address.City), f)
+	}
+
+	sub := &Subscription{hub: h, feed: f, cep: cep.String(), updates: make(chan Update, 1)}
+	f.subs[sub] = struct{}{}
+	h.subscriptions++
+	h.metrics.SetStreamSubscriptions(h.subscriptions, len(h.feeds))
+
+	if f.lastErr != nil {
+		sub.send(Update{Cep: sub.cep, Err: f.lastErr})
+	} else if f.last != nil {
+		sub.send(Update{Cep: sub.cep, Reading: f.reading(sub.cep)})
+	}
+
+	return sub, nil
+}
+
+// Done is closed by Shutdown.
+func (h *Hub) Done() <-chan struct{} {
+	return h.done
+}
+
+// Shutdown stops every poller and closes every subscription, which ends the
+// open streams.
+func (h *Hub) Shutdown() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	select {
+	case <-h.done:
+		return
+	default:
+		close(h.done)
+	}
+
+	for city, f := range h.feeds {
+		f.cancel()
+		for sub := range f.subs {
+			sub.close()
+			delete(f.subs, sub)
+		}
+		delete(h.feeds, city)
+	}
+	h.subscriptions = 0
+	h.metrics.SetStreamSubscriptions(0, 0)
+}
+
+func (h *Hub) unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	f := sub.feed
+	if _, ok := f.subs[sub]; !ok {
+		return
+	}
+	delete(f.subs, sub)
+	sub.close()
+	h.subscriptions--
+
+	if len(f.subs) == 0 {
+		f.cancel()
+		delete(h.feeds, f.city)
+	}
+	h.metrics.SetStreamSubscriptions(h.subscriptions, len(h.feeds))
+}
+
+func (h *Hub) poll(ctx context.Context, f *feed) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		response, err := h.weatherRepo.GetWeather(ctx, f.city)
+		if ctx.Err() != nil {
+			return
+		}
+		h.publish(ctx, f, response, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *Hub) publish(ctx context.Context, f *feed, response *weatherRepo.WeatherResponse, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err != nil {
+		apiErr := getWeatherByCep.NewWeatherServiceError()
+		if errors.Is(err, weatherRepo.ErrBudgetExceeded) {
+			apiErr = getWeatherByCep.NewWeatherBudgetExceededError()
+		}
+		h.logger.WithContext(ctx).Warn("Live weather poll failed: %v", err)
+		if f.lastErr != nil && f.lastErr.Code == apiErr.Code {
+			return
+		}
+		f.lastErr = apiErr
+		for sub := range f.subs {
+			sub.send(Update{Cep: sub.cep, Err: apiErr})
+		}
+		return
+	}
+
+	changed := f.last == nil || f.lastErr != nil ||
+		f.last.Current.TempC != response.Current.TempC || f.last.Current.TempF != response.Current.TempF ||
+		f.last.Current.Condition.Code != response.Current.Condition.Code
+	f.lastErr = nil
+	if !changed {
+		return
+	}
+
+	f.last = response
+	f.at = time.Now().UTC()
+	for sub := range f.subs {
+		sub.send(Update{Cep: sub.cep, Reading: f.reading(sub.cep)})
+	}
+}
+
+func (f *feed) reading(cep string) *Reading {
+	return &Reading{
+		Cep:           cep,
+		City:          f.city,
+		TempC:         f.last.Current.TempC,
+		TempF:         f.last.Current.TempF,
+		TempK:         f.last.Current.TempC + 273.15,
+		Condition:     f.last.Current.Condition.Text,
+		ConditionCode: f.last.Current.Condition.Code,
+		UpdatedAt:     f.at,
+	}
+}
+
+// Subscription receives the updates of one CEP until it is closed.
+type Subscription struct {
+	hub     *Hub
+	feed    *feed
+	cep     string
+	updates chan Update
+	closed  bool
+}
+
+func (s *Subscription) Cep() string {
+	return s.cep
+}
+
+// Updates is closed when the subscription is closed or the hub shuts down.
+// A slow reader only misses intermediate updates, never the latest one.
+func (s *Subscription) Updates() <-chan Update {
+	return s.updates
+}
+
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s)
+}
+
+// send and close run with the hub lock held.
+func (s *Subscription) send(u Update) {
+	if s.closed {
+		return
+	}
+	select {
+	case s.updates <- u:
+		return
+	default:
+	}
+	select {
+	case <-s.updates:
+	default:
+	}
+	s.updates <- u
+}
+
+func (s *Subscription) close() {
+	if !s.closed {
+		s.closed = true
+		close(s.updates)
+	}
+}
@@ -0,0 +1,207 @@
+package liveWeather
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/domain/valueObjects"
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+	viacep "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/viapcep"
+	viaCepMocks "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/viapcep/mocks"
+	weatherRepo "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWeather replays temps, repeating the last one, and counts calls per city.
+type fakeWeather struct {
+	mu    sync.Mutex
+	temps []float64
+	err   error
+	calls map[string]int
+}
+
+func (f *fakeWeather) GetWeather(_ context.Context, city string) (*weatherRepo.WeatherResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.calls == nil {
+		f.calls = map[string]int{}
+	}
+	f.calls[city]++
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	response := &weatherRepo.WeatherResponse{}
+	response.Current.TempC = f.temps[0]
+	if len(f.temps) > 1 {
+		f.temps = f.temps[1:]
+	}
+	return response, nil
+}
+
+func (f *fakeWeather) callsFor(city string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[city]
+}
+
+func setupHub(t *testing.T, weather *fakeWeather) (*Hub, *metrics.Metrics) {
+	t.Helper()
+
+	viaCepRepo := viaCepMocks.NewMockViaCepRepositoryInterface(t)
+	cities := map[valueObjects.Cep]string{"01001-000": "São Paulo", "01310-100": "São Paulo", "20040-002": "Rio de Janeiro"}
+	viaCepRepo.EXPECT().GetAddress(mock.Anything, mock.Anything).RunAndReturn(
+		func(_ context.Context, cep valueObjects.Cep) (*viacep.ViaCepResponse, error) {
+			if cep == "30140-071" {
+				return nil, errors.New("connection refused")
+			}
+			city, ok := cities[cep]
+			if !ok {
+				return nil, viacep.ErrZipcodeNotFound
+			}
+			return &viacep.ViaCepResponse{Cep: cep.String(), City: city}, nil
+		}).Maybe()
+
+	cfg := &config.Config{Log: config.LogConfig{Level: "error", Format: "json"}}
+	m := metrics.New()
+	hub := newHub(viaCepRepo, weather, 10*time.Millisecond, m, logger.NewWithWriter(io.Discard, cfg.Log, logger.NewLevel(cfg)))
+	t.Cleanup(hub.Shutdown)
+
+	return hub, m
+}
+
+func next(t *testing.T, sub *Subscription) Update {
+	t.Helper()
+	select {
+	case update, ok := <-sub.Updates():
+		require.True(t, ok, "subscription closed")
+		return update
+	case <-time.After(time.Second):
+		t.Fatal("no update received")
+		return Update{}
+	}
+}
+
+func TestHubSharesOnePollerPerCity(t *testing.T) {
+	weather := &fakeWeather{temps: []float64{25}}
+	hub, _ := setupHub(t, weather)
+
+	first, err := hub.Subscribe(context.Background(), "01001000")
+	require.NoError(t, err)
+	second, err := hub.Subscribe(context.Background(), "01310-100")
+	require.NoError(t, err)
+
+	assert.Equal(t, "01001-000", next(t, first).Reading.Cep)
+	update := next(t, second)
+	assert.Equal(t, "01310-100", update.Reading.Cep)
+	assert.Equal(t, "São Paulo", update.Reading.City)
+	assert.Equal(t, 298.15, update.Reading.TempK)
+
+	time.Sleep(55 * time.Millisecond)
+	// Polling per subscriber would make about 12 calls by now.
+	assert.LessOrEqual(t, weather.callsFor("São Paulo"), 7)
+}
+
+func TestHubNotifiesOnlyChanges(t *testing.T) {
+	weather := &fakeWeather{temps: []float64{25, 25, 25, 26}}
+	hub, _ := setupHub(t, weather)
+
+	sub, err := hub.Subscribe(context.Background(), "20040002")
+	require.NoError(t, err)
+
+	assert.Equal(t, 25.0, next(t, sub).Reading.TempC)
+	assert.Equal(t, 26.0, next(t, sub).Reading.TempC)
+	select {
+	case update := <-sub.Updates():
+		t.Fatalf("unexpected update %+v", update)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestHubReportsPollErrorsOnce(t *testing.T) {
+	weather := &fakeWeather{err: weatherRepo.ErrBudgetExceeded}
+	hub, _ := setupHub(t, weather)
+
+	sub, err := hub.Subscribe(context.Background(), "20040002")
+	require.NoError(t, err)
+
+	update := next(t, sub)
+	require.NotNil(t, update.Err)
+	assert.Equal(t, sharedErrors.CodeServiceUnavailable, update.Err.Code)
+	select {
+	case update := <-sub.Updates():
+		t.Fatalf("unexpected update %+v", update)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestHubStopsPollingAfterLastUnsubscribe(t *testing.T) {
+	weather := &fakeWeather{temps: []float64{25}}
+	hub, m := setupHub(t, weather)
+
+	sub, err := hub.Subscribe(context.Background(), "20040002")
+	require.NoError(t, err)
+	next(t, sub)
+	require.NoError(t, testutil.GatherAndCompare(m.Registry(), strings.NewReader(`
+# HELP weather_api_stream_subscriptions Live weather subscriptions open over SSE and WebSocket.
+# TYPE weather_api_stream_subscriptions gauge
+weather_api_stream_subscriptions 1
+`), "weather_api_stream_subscriptions"))
+
+	sub.Close()
+	sub.Close()
+	_, open := <-sub.Updates()
+	assert.False(t, open)
+
+	time.Sleep(20 * time.Millisecond)
+	calls := weather.callsFor("Rio de Janeiro")
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, calls, weather.callsFor("Rio de Janeiro"))
+	assert.NoError(t, testutil.GatherAndCompare(m.Registry(), strings.NewReader(`
+# HELP weather_api_stream_polled_cities Cities polled for live weather subscribers.
+# TYPE weather_api_stream_polled_cities gauge
+weather_api_stream_polled_cities 0
+# HELP weather_api_stream_subscriptions Live weather subscriptions open over SSE and WebSocket.
+# TYPE weather_api_stream_subscriptions gauge
+weather_api_stream_subscriptions 0
+`), "weather_api_stream_subscriptions", "weather_api_stream_polled_cities"))
+}
+
+func TestHubSubscribeErrors(t *testing.T) {
+	hub, _ := setupHub(t, &fakeWeather{temps: []float64{25}})
+
+	_, err := hub.Subscribe(context.Background(), "123")
+	assert.EqualError(t, err, "invalid zipcode")
+
+	_, err = hub.Subscribe(context.Background(), "99999999")
+	assert.EqualError(t, err, "can not find zipcode")
+
+	_, err = hub.Subscribe(context.Background(), "30140071")
+	assert.EqualError(t, err, "Address service temporarily unavailable")
+}
+
+func TestHubShutdownClosesSubscriptions(t *testing.T) {
+	hub, _ := setupHub(t, &fakeWeather{temps: []float64{25}})
+	sub, err := hub.Subscribe(context.Background(), "20040002")
+	require.NoError(t, err)
+
+	hub.Shutdown()
+
+	for range sub.Updates() {
+	}
+	sub.Close()
+	_, err = hub.Subscribe(context.Background(), "20040002")
+	assert.EqualError(t, err, "Server is shutting down")
+}
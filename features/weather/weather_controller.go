@@ -2,41 +2,131 @@ package weather
 
 import (
 	"context"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gerps2/desafio-cloud-run/features/weather/getWeatherByCep"
+	"github.com/gerps2/desafio-cloud-run/shared/auth"
+	"github.com/gerps2/desafio-cloud-run/shared/config"
 	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
 	httpShared "github.com/gerps2/desafio-cloud-run/shared/http"
+	"github.com/gerps2/desafio-cloud-run/shared/i18n"
 	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/openapi"
 
 	"github.com/gin-gonic/gin"
 )
 
+var (
+	msgCepRequired       = sharedErrors.NewMessage("cep.required", "CEP parameter is required")
+	msgCepRequiredInPath = sharedErrors.NewMessage("cep.required_in_path", "CEP parameter must be provided in the URL path")
+	msgGetWeatherFailed  = sharedErrors.NewMessage("weather.get_failed", "Failed to get weather data")
+)
+
 type WeatherController struct {
 	getWeatherByCepUseCase getWeatherByCep.GetWeatherByCepUseCaseInterface
+	store                  *config.Store
+	versioningConfig       config.VersioningConfig
 	logger                 logger.Logger
 }
 
-func NewWeatherController(getWeatherByCepUseCase getWeatherByCep.GetWeatherByCepUseCaseInterface, logger logger.Logger) *WeatherController {
+func NewWeatherController(getWeatherByCepUseCase getWeatherByCep.GetWeatherByCepUseCaseInterface, store *config.Store, logger logger.Logger) *WeatherController {
 	return &WeatherController{
 		getWeatherByCepUseCase: getWeatherByCepUseCase,
+		store:                  store,
+		versioningConfig:       store.Current().Versioning,
 		logger:                 logger,
 	}
 }
 
+// weatherRoutes serves the weather lookup under each versioned prefix, and
+// under /api for clients choosing the version with the API-Version header.
+var weatherRoutes = []struct {
+	prefix  string
+	version string
+}{
+	{prefix: "/api/v1", version: httpShared.APIVersion1},
+	{prefix: "/api/v2", version: httpShared.APIVersion2},
+	{prefix: "/api"},
+}
+
 func (wc *WeatherController) RegisterRoutes(router *gin.Engine) {
-	api := router.Group("/api/v1")
-	{
-		api.GET("/weather/:cep", wc.GetWeatherByCep)
+	for _, route := range weatherRoutes {
+		api := router.Group(route.prefix, httpShared.NegotiateMiddleware(), httpShared.VersionMiddleware(wc.versioningConfig, route.version))
+		api.GET("/weather/:cep", auth.RequireScopes(auth.ScopeWeatherRead), httpShared.CacheMiddleware(wc.store, weatherMaxAge), wc.GetWeatherByCep)
 	}
 }
 
-func (wc *WeatherController) GetWeatherByCep(c *gin.Context) {
-	wc.logger.Info("GetWeatherByCep endpoint called")
+func weatherMaxAge(cfg config.HTTPCacheConfig) time.Duration {
+	return time.Duration(cfg.WeatherMaxAgeSec) * time.Second
+}
+
+// DescribeRoutes documents the routes of RegisterRoutes in the OpenAPI spec.
+func (wc *WeatherController) DescribeRoutes(doc *openapi.Document) {
+	schemas := map[string]*openapi.Schema{
+		httpShared.APIVersion1: doc.Schema("GetWeatherByCepOutput", WeatherV1{}),
+		httpShared.APIVersion2: doc.Schema("WeatherV2", WeatherV2{}),
+	}
+	descriptions := map[string]string{
+		httpShared.APIVersion1: "Resolves the CEP city through ViaCep and returns its current temperature in Celsius, Fahrenheit and Kelvin. " +
+			"This is the original contract, kept as is; it is deprecated in favour of /api/v2 and answers with Deprecation and Sunset headers.",
+		httpShared.APIVersion2: "Resolves the CEP city through ViaCep and returns its location, current temperature, weather condition and response metadata in snake_case.",
+		"":                     "Same lookup with the version chosen by the API-Version header, else by VERSIONING_DEFAULT; the data follows the v1 or v2 contract accordingly.",
+	}
 
+	for _, route := range weatherRoutes {
+		data := schemas[route.version]
+		operationID := "getWeatherByCep"
+		if route.version == "" {
+			data = &openapi.Schema{OneOf: []*openapi.Schema{schemas[httpShared.APIVersion1], schemas[httpShared.APIVersion2]}}
+			operationID += "ByHeader"
+		} else if route.version != httpShared.APIVersion1 {
+			operationID += strings.ToUpper(route.version)
+		}
+
+		doc.Add(http.MethodGet, route.prefix+"/weather/:cep", openapi.Versioned(openapi.Localized(openapi.Negotiated(openapi.Operation{
+			Tags:        []string{"weather"},
+			Summary:     "Current temperature for a Brazilian zipcode",
+			Description: descriptions[route.version],
+			OperationID: operationID,
+			Parameters: []openapi.Parameter{{
+				Name:        "cep",
+				In:          "path",
+				Description: "8-digit CEP, with or without hyphen",
+				Required:    true,
+				Schema:      &openapi.Schema{Type: "string"},
+				Example:     "01001-000",
+			}},
+			Responses: openapi.Responses(openapi.CommonErrors(true), map[string]openapi.Response{
+				openapi.Status(http.StatusOK):          openapi.Success("Weather data retrieved successfully", data),
+				openapi.Status(http.StatusNotModified): openapi.NotModified(),
+				openapi.Status(http.StatusUnprocessableEntity): openapi.Error("Invalid zipcode",
+					openapi.ErrorCode{Code: getWeatherByCep.CodeInvalidZipcode, Message: "the CEP is not 8 digits"}),
+				openapi.Status(http.StatusNotFound): openapi.Error("Zipcode not found",
+					openapi.ErrorCode{Code: getWeatherByCep.CodeZipcodeNotFound, Message: "ViaCep does not know the CEP"}),
+				openapi.Status(http.StatusBadGateway): openapi.Error("Upstream failure",
+					openapi.ErrorCode{Code: sharedErrors.CodeExternalService, Message: "ViaCep or WeatherAPI failed"}),
+				openapi.Status(http.StatusServiceUnavailable): openapi.Error("WeatherAPI call budget exhausted",
+					openapi.ErrorCode{Code: sharedErrors.CodeServiceUnavailable, Message: "the plan budget is used up, retry later"}),
+			}),
+			Security: openapi.Secured(auth.ScopeWeatherRead),
+		}, httpShared.SupportedMediaTypes()), i18n.Locales()), httpShared.VersionHeader, httpShared.APIVersions(), route.version == httpShared.APIVersion1))
+	}
+}
+
+func (wc *WeatherController) GetWeatherByCep(c *gin.Context) {
 	cepParam := c.Param("cep")
+
+	ctx := logger.ContextWithFields(c.Request.Context(), "cep", cepParam)
+	c.Request = c.Request.WithContext(ctx)
+	log := wc.logger.WithContext(ctx)
+
+	log.Info("GetWeatherByCep endpoint called")
+
 	if cepParam == "" {
-		wc.logger.Error("CEP parameter is required")
-		httpShared.RespondWithValidationError(c, "CEP parameter is required", []string{"CEP parameter must be provided in the URL path"})
+		log.Error("CEP parameter is required")
+		httpShared.RespondWithValidationError(c, msgCepRequired.Format(), []sharedErrors.Text{msgCepRequiredInPath.Format()})
 		return
 	}
 
@@ -44,22 +134,24 @@ func (wc *WeatherController) GetWeatherByCep(c *gin.Context) {
 		CepString: cepParam,
 	}
 
-	result, err := wc.getWeatherByCepUseCase.Execute(c.Request.Context(), input)
+	result, err := wc.getWeatherByCepUseCase.Execute(ctx, input)
 	if err != nil {
-		if c.Request.Context().Err() == context.DeadlineExceeded {
-			wc.logger.Error("Request timeout exceeded for CEP: %s", cepParam)
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Error("Request timeout exceeded for CEP: %s", cepParam)
 			return
 		}
-		wc.logger.Error("Error executing GetWeatherByCep use case: %v", err)
+		log.Error("Error executing GetWeatherByCep use case: %v", err)
 
 		if apiErr, ok := err.(*sharedErrors.APIError); ok {
 			httpShared.RespondWithAPIError(c, apiErr)
 		} else {
-			httpShared.RespondWithInternalError(c, "Failed to get weather data", []string{err.Error()})
+			httpShared.RespondWithInternalError(c, msgGetWeatherFailed.Format(), []sharedErrors.Text{sharedErrors.Raw(err.Error())})
 		}
 		return
 	}
 
-	wc.logger.Info("Weather data retrieved successfully for CEP: %s", cepParam)
-	httpShared.RespondWithSuccess(c, result, "Weather data retrieved successfully")
+	log.Info("Weather data retrieved successfully for CEP: %s", cepParam)
+	present := weatherPresenters[httpShared.APIVersion(c)]
+	httpShared.SetLastModified(c, result.ObservedAt)
+	httpShared.RespondWithSuccess(c, present(result, httpShared.Locale(c)), "Weather data retrieved successfully")
 }
@@ -6,15 +6,19 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gerps2/desafio-cloud-run/features/weather/getWeatherByCep"
 	getWeatherByCepMocks "github.com/gerps2/desafio-cloud-run/features/weather/getWeatherByCep/mocks"
+	"github.com/gerps2/desafio-cloud-run/shared/config"
 	httpShared "github.com/gerps2/desafio-cloud-run/shared/http"
 	loggerMocks "github.com/gerps2/desafio-cloud-run/shared/logger/mocks"
+	"github.com/gerps2/desafio-cloud-run/shared/openapi"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func setupTestRouter(controller *WeatherController) *gin.Engine {
@@ -30,6 +34,7 @@ func TestWeatherControllerGetWeatherByCepSuccess(t *testing.T) {
 	// Arrange
 	mockUseCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
 	mockLogger := loggerMocks.NewMockLogger(t)
+	mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
 
 	expectedResult := &getWeatherByCep.GetWeatherByCepOutput{
 		TempC: 25.5,
@@ -46,7 +51,7 @@ func TestWeatherControllerGetWeatherByCepSuccess(t *testing.T) {
 		getWeatherByCep.GetWeatherByCepInput{CepString: "12345-678"},
 	).Return(expectedResult, nil).Once()
 
-	controller := NewWeatherController(mockUseCase, mockLogger)
+	controller := NewWeatherController(mockUseCase, config.NewStore(&config.Config{}, config.Options{}), mockLogger)
 	router := setupTestRouter(controller)
 
 	// Act
@@ -80,6 +85,7 @@ func TestWeatherControllerGetWeatherByCepInvalidCEP(t *testing.T) {
 	// Arrange
 	mockUseCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
 	mockLogger := loggerMocks.NewMockLogger(t)
+	mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
 
 	expectedError := getWeatherByCep.NewInvalidZipcodeError()
 
@@ -92,7 +98,7 @@ func TestWeatherControllerGetWeatherByCepInvalidCEP(t *testing.T) {
 		getWeatherByCep.GetWeatherByCepInput{CepString: "invalid-cep"},
 	).Return(nil, expectedError).Once()
 
-	controller := NewWeatherController(mockUseCase, mockLogger)
+	controller := NewWeatherController(mockUseCase, config.NewStore(&config.Config{}, config.Options{}), mockLogger)
 	router := setupTestRouter(controller)
 
 	// Act
@@ -118,6 +124,7 @@ func TestWeatherControllerGetWeatherByCepCEPNotFound(t *testing.T) {
 	// Arrange
 	mockUseCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
 	mockLogger := loggerMocks.NewMockLogger(t)
+	mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
 
 	expectedError := getWeatherByCep.NewZipcodeNotFoundError()
 
@@ -130,7 +137,7 @@ func TestWeatherControllerGetWeatherByCepCEPNotFound(t *testing.T) {
 		getWeatherByCep.GetWeatherByCepInput{CepString: "99999-999"},
 	).Return(nil, expectedError).Once()
 
-	controller := NewWeatherController(mockUseCase, mockLogger)
+	controller := NewWeatherController(mockUseCase, config.NewStore(&config.Config{}, config.Options{}), mockLogger)
 	router := setupTestRouter(controller)
 
 	// Act
@@ -156,6 +163,7 @@ func TestWeatherControllerGetWeatherByCepWeatherServiceError(t *testing.T) {
 	// Arrange
 	mockUseCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
 	mockLogger := loggerMocks.NewMockLogger(t)
+	mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
 
 	expectedError := getWeatherByCep.NewWeatherServiceError()
 
@@ -168,7 +176,7 @@ func TestWeatherControllerGetWeatherByCepWeatherServiceError(t *testing.T) {
 		getWeatherByCep.GetWeatherByCepInput{CepString: "12345-678"},
 	).Return(nil, expectedError).Once()
 
-	controller := NewWeatherController(mockUseCase, mockLogger)
+	controller := NewWeatherController(mockUseCase, config.NewStore(&config.Config{}, config.Options{}), mockLogger)
 	router := setupTestRouter(controller)
 
 	// Act
@@ -192,6 +200,7 @@ func TestWeatherControllerGetWeatherByCepUnknownError(t *testing.T) {
 	// Arrange
 	mockUseCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
 	mockLogger := loggerMocks.NewMockLogger(t)
+	mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
 
 	unknownError := errors.New("unknown error")
 
@@ -204,7 +213,7 @@ func TestWeatherControllerGetWeatherByCepUnknownError(t *testing.T) {
 		getWeatherByCep.GetWeatherByCepInput{CepString: "12345-678"},
 	).Return(nil, unknownError).Once()
 
-	controller := NewWeatherController(mockUseCase, mockLogger)
+	controller := NewWeatherController(mockUseCase, config.NewStore(&config.Config{}, config.Options{}), mockLogger)
 	router := setupTestRouter(controller)
 
 	// Act
@@ -231,7 +240,7 @@ func TestWeatherControllerGetWeatherByCepMissingCEPParameter(t *testing.T) {
 	mockUseCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
 	mockLogger := loggerMocks.NewMockLogger(t)
 
-	controller := NewWeatherController(mockUseCase, mockLogger)
+	controller := NewWeatherController(mockUseCase, config.NewStore(&config.Config{}, config.Options{}), mockLogger)
 	router := setupTestRouter(controller)
 
 	// Act
@@ -246,3 +255,323 @@ func TestWeatherControllerGetWeatherByCepMissingCEPParameter(t *testing.T) {
 	// Use case should not be called for missing parameter
 	mockUseCase.AssertNotCalled(t, "Execute")
 }
+
+func TestWeatherControllerGetWeatherByCepXML(t *testing.T) {
+	// Arrange
+	mockUseCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
+	mockLogger := loggerMocks.NewMockLogger(t)
+	mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
+	mockLogger.EXPECT().Info("GetWeatherByCep endpoint called").Once()
+	mockLogger.EXPECT().Info("Weather data retrieved successfully for CEP: %s", "12345-678").Once()
+
+	mockUseCase.EXPECT().Execute(mock.Anything, mock.Anything).
+		Return(&getWeatherByCep.GetWeatherByCepOutput{TempC: 25.5, TempF: 77.9, TempK: 298.65}, nil).Once()
+
+	controller := NewWeatherController(mockUseCase, config.NewStore(&config.Config{}, config.Options{}), mockLogger)
+	router := setupTestRouter(controller)
+
+	// Act
+	req, _ := http.NewRequest("GET", "/api/v1/weather/12345-678", nil)
+	req.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<data><temp_C>25.5</temp_C><temp_F>77.9</temp_F><temp_K>298.65</temp_K></data>")
+}
+
+func TestWeatherControllerGetWeatherByCepNotAcceptable(t *testing.T) {
+	// Arrange
+	mockUseCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
+	mockLogger := loggerMocks.NewMockLogger(t)
+
+	controller := NewWeatherController(mockUseCase, config.NewStore(&config.Config{}, config.Options{}), mockLogger)
+	router := setupTestRouter(controller)
+
+	// Act
+	req, _ := http.NewRequest("GET", "/api/v1/weather/12345-678?format=yaml", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	mockUseCase.AssertNotCalled(t, "Execute")
+}
+
+func TestWeatherControllerGetWeatherByCepNotModified(t *testing.T) {
+	// Arrange
+	mockUseCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
+	mockLogger := loggerMocks.NewMockLogger(t)
+	mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
+	mockLogger.EXPECT().Info("GetWeatherByCep endpoint called").Once()
+	mockLogger.EXPECT().Info("Weather data retrieved successfully for CEP: %s", "12345-678").Once()
+
+	mockUseCase.EXPECT().Execute(mock.Anything, mock.Anything).Return(&getWeatherByCep.GetWeatherByCepOutput{
+		TempC:      25.5,
+		TempF:      77.9,
+		TempK:      298.65,
+		ObservedAt: time.Date(2026, 10, 19, 12, 30, 0, 0, time.UTC),
+	}, nil).Once()
+
+	cfg := &config.Config{HTTPCache: config.HTTPCacheConfig{Enabled: true, WeatherMaxAgeSec: 60}}
+	controller := NewWeatherController(mockUseCase, config.NewStore(cfg, config.Options{}), mockLogger)
+	router := setupTestRouter(controller)
+
+	// Act
+	req, _ := http.NewRequest("GET", "/api/v1/weather/12345-678", nil)
+	req.Header.Set("If-Modified-Since", "Mon, 19 Oct 2026 12:30:00 GMT")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, "private, max-age=60", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "Mon, 19 Oct 2026 12:30:00 GMT", w.Header().Get("Last-Modified"))
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+	assert.Empty(t, w.Body.String())
+}
+
+func TestWeatherControllerGetWeatherByCepLocalized(t *testing.T) {
+	// Arrange
+	mockUseCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
+	mockLogger := loggerMocks.NewMockLogger(t)
+	mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
+	mockLogger.EXPECT().Info("GetWeatherByCep endpoint called").Once()
+	mockLogger.EXPECT().Info("Weather data retrieved successfully for CEP: %s", "12345-678").Once()
+
+	mockUseCase.EXPECT().Execute(mock.Anything, mock.Anything).Return(&getWeatherByCep.GetWeatherByCepOutput{
+		TempC:         25.5,
+		TempF:         77.9,
+		TempK:         298.65,
+		Condition:     "Partly cloudy",
+		ConditionCode: 1003,
+	}, nil).Once()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(httpShared.LocaleMiddleware())
+	NewWeatherController(mockUseCase, config.NewStore(&config.Config{}, config.Options{}), mockLogger).RegisterRoutes(router)
+
+	// Act
+	req, _ := http.NewRequest("GET", "/api/v1/weather/12345-678", nil)
+	req.Header.Set("Accept-Language", "pt-BR")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "pt-BR", w.Header().Get("Content-Language"))
+	// The v1 contract has no condition; v2 carries it translated.
+	assert.JSONEq(t, `{"data":{"temp_C":25.5,"temp_F":77.9,"temp_K":298.65},"message":"Weather data retrieved successfully"}`, w.Body.String())
+}
+
+func TestWeatherControllerGetWeatherByCepLocalizedError(t *testing.T) {
+	// Arrange
+	mockUseCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
+	mockLogger := loggerMocks.NewMockLogger(t)
+	mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
+	mockLogger.EXPECT().Info("GetWeatherByCep endpoint called").Once()
+	mockLogger.EXPECT().Error("Error executing GetWeatherByCep use case: %v", mock.Anything).Once()
+
+	mockUseCase.EXPECT().Execute(mock.Anything, mock.Anything).Return(nil, getWeatherByCep.NewZipcodeNotFoundError()).Once()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(httpShared.LocaleMiddleware())
+	NewWeatherController(mockUseCase, config.NewStore(&config.Config{}, config.Options{}), mockLogger).RegisterRoutes(router)
+
+	// Act
+	req, _ := http.NewRequest("GET", "/api/v1/weather/12345-678", nil)
+	req.Header.Set("Accept-Language", "es-AR,es;q=0.9")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var response httpShared.APIResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "no se encontró el código postal", response.Message)
+	assert.Equal(t, []string{"El código postal informado no fue encontrado"}, response.Causes)
+}
+
+func weatherOutput() *getWeatherByCep.GetWeatherByCepOutput {
+	return &getWeatherByCep.GetWeatherByCepOutput{
+		Cep:           "12345-678",
+		City:          "São Paulo",
+		State:         "SP",
+		Region:        "Sao Paulo",
+		Country:       "Brazil",
+		Latitude:      -23.53,
+		Longitude:     -46.62,
+		TempC:         25.5,
+		TempF:         77.9,
+		TempK:         298.65,
+		Condition:     "Partly cloudy",
+		ConditionCode: 1003,
+		ObservedAt:    time.Date(2026, 10, 19, 12, 30, 0, 0, time.UTC),
+	}
+}
+
+func TestWeatherControllerGetWeatherByCepV2(t *testing.T) {
+	// Arrange
+	mockUseCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
+	mockLogger := loggerMocks.NewMockLogger(t)
+	mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
+	mockLogger.EXPECT().Info("GetWeatherByCep endpoint called").Once()
+	mockLogger.EXPECT().Info("Weather data retrieved successfully for CEP: %s", "12345-678").Once()
+
+	mockUseCase.EXPECT().Execute(mock.Anything, getWeatherByCep.GetWeatherByCepInput{CepString: "12345-678"}).Return(weatherOutput(), nil).Once()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(httpShared.LocaleMiddleware())
+	NewWeatherController(mockUseCase, config.NewStore(&config.Config{}, config.Options{}), mockLogger).RegisterRoutes(router)
+
+	// Act
+	req, _ := http.NewRequest("GET", "/api/v2/weather/12345-678", nil)
+	req.Header.Set("Accept-Language", "pt-BR")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "v2", w.Header().Get(httpShared.VersionHeader))
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	assert.JSONEq(t, `{"data":{
+		"cep":"12345-678",
+		"location":{"city":"São Paulo","state":"SP","region":"Sao Paulo","country":"Brazil","latitude":-23.53,"longitude":-46.62},
+		"temperature":{"celsius":25.5,"fahrenheit":77.9,"kelvin":298.65},
+		"condition":{"code":1003,"description":"Parcialmente nublado"},
+		"metadata":{"observed_at":"2026-10-19T12:30:00Z","api_version":"v2","locale":"pt-BR"}
+	},"message":"Weather data retrieved successfully"}`, w.Body.String())
+}
+
+func TestWeatherControllerGetWeatherByCepVersions(t *testing.T) {
+	v1Body := `{"data":{"temp_C":25.5,"temp_F":77.9,"temp_K":298.65},"message":"Weather data retrieved successfully"}`
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		version    string
+		deprecated bool
+		body       string
+	}{
+		{name: "v1 path keeps the original contract", path: "/api/v1/weather/12345-678", version: "v1", deprecated: true, body: v1Body},
+		{name: "Unversioned route defaults to v1", path: "/api/weather/12345-678", version: "v1", deprecated: true, body: v1Body},
+		{name: "Unversioned route with API-Version", path: "/api/weather/12345-678", header: "2", version: "v2"},
+	}
+
+	cfg := &config.Config{Versioning: config.VersioningConfig{
+		Default:        "v1",
+		V1DeprecatedAt: "2026-10-19T00:00:00Z",
+		V1SunsetAt:     "2027-10-19T00:00:00Z",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockUseCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
+			mockLogger := loggerMocks.NewMockLogger(t)
+			mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
+			mockLogger.EXPECT().Info("GetWeatherByCep endpoint called").Once()
+			mockLogger.EXPECT().Info("Weather data retrieved successfully for CEP: %s", "12345-678").Once()
+			mockUseCase.EXPECT().Execute(mock.Anything, mock.Anything).Return(weatherOutput(), nil).Once()
+
+			router := setupTestRouter(NewWeatherController(mockUseCase, config.NewStore(cfg, config.Options{}), mockLogger))
+
+			// Act
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(httpShared.VersionHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.version, w.Header().Get(httpShared.VersionHeader))
+			if tt.deprecated {
+				assert.Equal(t, "@1792368000", w.Header().Get("Deprecation"))
+				assert.Equal(t, "Tue, 19 Oct 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+			} else {
+				assert.Empty(t, w.Header().Get("Deprecation"))
+			}
+			if tt.body != "" {
+				assert.JSONEq(t, tt.body, w.Body.String())
+			} else {
+				assert.Contains(t, w.Body.String(), `"temperature":{"celsius":25.5`)
+			}
+		})
+	}
+}
+
+func TestWeatherControllerGetWeatherByCepConflictingVersion(t *testing.T) {
+	// Arrange
+	mockUseCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
+	mockLogger := loggerMocks.NewMockLogger(t)
+
+	router := setupTestRouter(NewWeatherController(mockUseCase, config.NewStore(&config.Config{}, config.Options{}), mockLogger))
+
+	// Act
+	req, _ := http.NewRequest("GET", "/api/v2/weather/12345-678", nil)
+	req.Header.Set(httpShared.VersionHeader, "v1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response httpShared.APIResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Requested API version is not supported", response.Message)
+	mockUseCase.AssertNotCalled(t, "Execute")
+}
+
+func TestWeatherControllerV1ContractIsFrozen(t *testing.T) {
+	baseline := []string{"temp_C", "temp_F", "temp_K"}
+
+	// Arrange
+	mockUseCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
+	mockLogger := loggerMocks.NewMockLogger(t)
+	mockLogger.EXPECT().WithContext(mock.Anything).Return(mockLogger).Once()
+	mockLogger.EXPECT().Info("GetWeatherByCep endpoint called").Once()
+	mockLogger.EXPECT().Info("Weather data retrieved successfully for CEP: %s", "12345-678").Once()
+	mockUseCase.EXPECT().Execute(mock.Anything, mock.Anything).Return(weatherOutput(), nil).Once()
+
+	controller := NewWeatherController(mockUseCase, config.NewStore(&config.Config{}, config.Options{}), mockLogger)
+	router := setupTestRouter(controller)
+
+	// Act
+	req, _ := http.NewRequest("GET", "/api/v1/weather/12345-678", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	doc := openapi.New(openapi.Info{}, "X-API-Key", "api_key")
+	controller.DescribeRoutes(doc)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.ElementsMatch(t, baseline, mapKeys(response.Data))
+
+	schema := doc.Components.Schemas["GetWeatherByCepOutput"]
+	require.NotNil(t, schema)
+	assert.ElementsMatch(t, baseline, mapKeys(schema.Properties))
+	assert.ElementsMatch(t, baseline, schema.Required)
+
+	op, ok := doc.Operation(http.MethodGet, "/api/v1/weather/:cep")
+	require.True(t, ok)
+	assert.True(t, op.Deprecated)
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
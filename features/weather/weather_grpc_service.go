@@ -0,0 +1,184 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/gerps2/desafio-cloud-run/features/weather/getWeatherByCep"
+	weatherv1 "github.com/gerps2/desafio-cloud-run/gen/weather/v1"
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+	"github.com/gerps2/desafio-cloud-run/shared/grpcserver"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// batchConcurrency caps the lookups in flight for one batch or stream, so a
+// single call cannot use up the upstream budget on its own.
+const batchConcurrency = 4
+
+// WeatherGRPCService serves weather.v1.WeatherService on top of the same use
+// case as the HTTP controller.
+type WeatherGRPCService struct {
+	weatherv1.UnimplementedWeatherServiceServer
+
+	getWeatherByCepUseCase getWeatherByCep.GetWeatherByCepUseCaseInterface
+	maxBatchSize           int
+	logger                 logger.Logger
+}
+
+func NewWeatherGRPCService(getWeatherByCepUseCase getWeatherByCep.GetWeatherByCepUseCaseInterface, cfg *config.Config, logger logger.Logger) *WeatherGRPCService {
+	return &WeatherGRPCService{
+		getWeatherByCepUseCase: getWeatherByCepUseCase,
+		maxBatchSize:           cfg.GRPC.MaxBatchSize,
+		logger:                 logger,
+	}
+}
+
+func (s *WeatherGRPCService) Register(registrar grpc.ServiceRegistrar) {
+	weatherv1.RegisterWeatherServiceServer(registrar, s)
+}
+
+func (s *WeatherGRPCService) GetWeatherByCep(ctx context.Context, req *weatherv1.GetWeatherByCepRequest) (*weatherv1.GetWeatherByCepResponse, error) {
+	ctx = logger.ContextWithFields(ctx, "cep", req.GetCep())
+
+	output, err := s.lookup(ctx, req.GetCep())
+	if err != nil {
+		return nil, grpcserver.Error(err)
+	}
+
+	return &weatherv1.GetWeatherByCepResponse{Weather: toWeather(output)}, nil
+}
+
+func (s *WeatherGRPCService) BatchGetWeather(ctx context.Context, req *weatherv1.BatchGetWeatherRequest) (*weatherv1.BatchGetWeatherResponse, error) {
+	if err := s.validateBatch(req.GetCeps()); err != nil {
+		return nil, err
+	}
+
+	results := make([]*weatherv1.WeatherResult, len(req.GetCeps()))
+	s.lookupAll(ctx, req.GetCeps(), func(i int, result *weatherv1.WeatherResult) {
+		results[i] = result
+	})
+
+	return &weatherv1.BatchGetWeatherResponse{Results: results}, nil
+}
+
+// StreamWeather sends each result as soon as its lookup finishes, so the
+// order of the responses does not follow the request.
+func (s *WeatherGRPCService) StreamWeather(req *weatherv1.StreamWeatherRequest, stream weatherv1.WeatherService_StreamWeatherServer) error {
+	if err := s.validateBatch(req.GetCeps()); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	results := make(chan *weatherv1.WeatherResult)
+	go func() {
+		defer close(results)
+		s.lookupAll(ctx, req.GetCeps(), func(_ int, result *weatherv1.WeatherResult) {
+			select {
+			case results <- result:
+			case <-ctx.Done():
+			}
+		})
+	}()
+
+	var sendErr error
+	for result := range results {
+		if sendErr != nil {
+			continue
+		}
+		if sendErr = stream.Send(&weatherv1.StreamWeatherResponse{Result: result}); sendErr != nil {
+			cancel()
+		}
+	}
+	if sendErr != nil {
+		return sendErr
+	}
+	return grpcserver.Error(ctx.Err())
+}
+
+func (s *WeatherGRPCService) validateBatch(ceps []string) error {
+	if len(ceps) == 0 {
+		return status.Error(codes.InvalidArgument, "at least one CEP is required")
+	}
+	if len(ceps) > s.maxBatchSize {
+		return status.Errorf(codes.InvalidArgument, "at most %d CEPs are allowed per call, got %d", s.maxBatchSize, len(ceps))
+	}
+	return nil
+}
+
+// lookupAll resolves every CEP with bounded concurrency and hands each
+// result, success or failure, to emit. emit may be called concurrently.
+func (s *WeatherGRPCService) lookupAll(ctx context.Context, ceps []string, emit func(i int, result *weatherv1.WeatherResult)) {
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+
+	for i, cep := range ceps {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+
+		wg.Add(1)
+		go func(i int, cep string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			cepCtx := logger.ContextWithFields(ctx, "cep", cep)
+			output, err := s.lookup(cepCtx, cep)
+			if err != nil {
+				emit(i, &weatherv1.WeatherResult{Cep: cep, Result: &weatherv1.WeatherResult_Error{Error: toError(err)}})
+				return
+			}
+			emit(i, &weatherv1.WeatherResult{Cep: cep, Result: &weatherv1.WeatherResult_Weather{Weather: toWeather(output)}})
+		}(i, cep)
+	}
+
+	wg.Wait()
+}
+
+func (s *WeatherGRPCService) lookup(ctx context.Context, cep string) (*getWeatherByCep.GetWeatherByCepOutput, error) {
+	log := s.logger.WithContext(ctx)
+
+	output, err := s.getWeatherByCepUseCase.Execute(ctx, getWeatherByCep.GetWeatherByCepInput{CepString: cep})
+	if err != nil {
+		log.Error("Error executing GetWeatherByCep use case: %v", err)
+		return nil, err
+	}
+
+	return output, nil
+}
+
+func toWeather(output *getWeatherByCep.GetWeatherByCepOutput) *weatherv1.Weather {
+	return &weatherv1.Weather{
+		TempC: output.TempC,
+		TempF: output.TempF,
+		TempK: output.TempK,
+	}
+}
+
+func toError(err error) *weatherv1.Error {
+	st := grpcserver.Status(err)
+	result := &weatherv1.Error{
+		Code:     sharedErrors.CodeInternalError,
+		Message:  st.Message(),
+		GrpcCode: int32(st.Code()),
+	}
+	var apiErr *sharedErrors.APIError
+	switch {
+	case errors.As(err, &apiErr):
+		result.Code = apiErr.Code
+		result.Causes = apiErr.Causes
+	case st.Code() == codes.DeadlineExceeded:
+		result.Code = sharedErrors.CodeServiceTimeout
+	}
+	return result
+}
@@ -0,0 +1,154 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sort"
+	"testing"
+
+	"github.com/gerps2/desafio-cloud-run/features/weather/getWeatherByCep"
+	getWeatherByCepMocks "github.com/gerps2/desafio-cloud-run/features/weather/getWeatherByCep/mocks"
+	weatherv1 "github.com/gerps2/desafio-cloud-run/gen/weather/v1"
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/grpcserver"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func setupGRPCClient(t *testing.T, useCase getWeatherByCep.GetWeatherByCepUseCaseInterface) *grpc.ClientConn {
+	t.Helper()
+
+	cfg := &config.Config{
+		App:  config.AppConfig{RequestTimeoutSec: 5},
+		Log:  config.LogConfig{Level: "error", Format: "json"},
+		GRPC: config.GRPCConfig{Enabled: true, MaxBatchSize: 3},
+	}
+	log := logger.NewWithWriter(io.Discard, cfg.Log, logger.NewLevel(cfg))
+
+	server := grpcserver.NewServer(cfg, log)
+	NewWeatherGRPCService(useCase, cfg, log).Register(server)
+
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Shutdown(context.Background()) })
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func expectCep(useCase *getWeatherByCepMocks.MockGetWeatherByCepUseCaseInterface, cep string, output *getWeatherByCep.GetWeatherByCepOutput, err error) {
+	useCase.EXPECT().Execute(mock.Anything, getWeatherByCep.GetWeatherByCepInput{CepString: cep}).Return(output, err).Once()
+}
+
+func TestWeatherGRPCServiceGetWeatherByCepSuccess(t *testing.T) {
+	useCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
+	expectCep(useCase, "01001000", &getWeatherByCep.GetWeatherByCepOutput{TempC: 25, TempF: 77, TempK: 298.15}, nil)
+	client := weatherv1.NewWeatherServiceClient(setupGRPCClient(t, useCase))
+
+	resp, err := client.GetWeatherByCep(context.Background(), &weatherv1.GetWeatherByCepRequest{Cep: "01001000"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 25.0, resp.GetWeather().GetTempC())
+	assert.Equal(t, 77.0, resp.GetWeather().GetTempF())
+	assert.Equal(t, 298.15, resp.GetWeather().GetTempK())
+}
+
+func TestWeatherGRPCServiceGetWeatherByCepMapsAPIError(t *testing.T) {
+	useCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
+	expectCep(useCase, "99999999", nil, getWeatherByCep.NewZipcodeNotFoundError())
+	client := weatherv1.NewWeatherServiceClient(setupGRPCClient(t, useCase))
+
+	_, err := client.GetWeatherByCep(context.Background(), &weatherv1.GetWeatherByCepRequest{Cep: "99999999"})
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "can not find zipcode", st.Message())
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, getWeatherByCep.CodeZipcodeNotFound, info.GetReason())
+	assert.Equal(t, grpcserver.ErrorDomain, info.GetDomain())
+}
+
+func TestWeatherGRPCServiceBatchGetWeather(t *testing.T) {
+	useCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
+	expectCep(useCase, "01001000", &getWeatherByCep.GetWeatherByCepOutput{TempC: 25}, nil)
+	expectCep(useCase, "123", nil, getWeatherByCep.NewInvalidZipcodeError())
+	expectCep(useCase, "20040002", nil, errors.New("boom"))
+	client := weatherv1.NewWeatherServiceClient(setupGRPCClient(t, useCase))
+
+	resp, err := client.BatchGetWeather(context.Background(), &weatherv1.BatchGetWeatherRequest{Ceps: []string{"01001000", "123", "20040002"}})
+
+	require.NoError(t, err)
+	require.Len(t, resp.GetResults(), 3)
+	assert.Equal(t, "01001000", resp.GetResults()[0].GetCep())
+	assert.Equal(t, 25.0, resp.GetResults()[0].GetWeather().GetTempC())
+	assert.Equal(t, getWeatherByCep.CodeInvalidZipcode, resp.GetResults()[1].GetError().GetCode())
+	assert.Equal(t, int32(codes.InvalidArgument), resp.GetResults()[1].GetError().GetGrpcCode())
+	assert.Equal(t, int32(codes.Internal), resp.GetResults()[2].GetError().GetGrpcCode())
+}
+
+func TestWeatherGRPCServiceBatchGetWeatherRejectsOversizedBatch(t *testing.T) {
+	useCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
+	client := weatherv1.NewWeatherServiceClient(setupGRPCClient(t, useCase))
+
+	_, err := client.BatchGetWeather(context.Background(), &weatherv1.BatchGetWeatherRequest{Ceps: []string{"1", "2", "3", "4"}})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestWeatherGRPCServiceStreamWeather(t *testing.T) {
+	useCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
+	expectCep(useCase, "01001000", &getWeatherByCep.GetWeatherByCepOutput{TempC: 25}, nil)
+	expectCep(useCase, "99999999", nil, getWeatherByCep.NewZipcodeNotFoundError())
+	client := weatherv1.NewWeatherServiceClient(setupGRPCClient(t, useCase))
+
+	stream, err := client.StreamWeather(context.Background(), &weatherv1.StreamWeatherRequest{Ceps: []string{"01001000", "99999999"}})
+	require.NoError(t, err)
+
+	var ceps []string
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		ceps = append(ceps, resp.GetResult().GetCep())
+		if resp.GetResult().GetCep() == "99999999" {
+			assert.Equal(t, int32(codes.NotFound), resp.GetResult().GetError().GetGrpcCode())
+		}
+	}
+	sort.Strings(ceps)
+	assert.Equal(t, []string{"01001000", "99999999"}, ceps)
+}
+
+func TestWeatherGRPCServiceReportsHealth(t *testing.T) {
+	useCase := getWeatherByCepMocks.NewMockGetWeatherByCepUseCaseInterface(t)
+	client := healthpb.NewHealthClient(setupGRPCClient(t, useCase))
+
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: weatherv1.WeatherService_ServiceDesc.ServiceName})
+
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+}
@@ -0,0 +1,105 @@
+package weather
+
+import (
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/features/weather/getWeatherByCep"
+	httpShared "github.com/gerps2/desafio-cloud-run/shared/http"
+	"github.com/gerps2/desafio-cloud-run/shared/i18n"
+)
+
+// WeatherV1 is the contract of the original challenge and must not change:
+// the temperatures sit at the top of data with their unit upper-cased.
+type WeatherV1 struct {
+	TempC float64 `json:"temp_C" xml:"temp_C"`
+	TempF float64 `json:"temp_F" xml:"temp_F"`
+	TempK float64 `json:"temp_K" xml:"temp_K"`
+}
+
+// WeatherV2 groups the reading by subject and names every field in
+// snake_case.
+type WeatherV2 struct {
+	Cep         string             `json:"cep" xml:"cep"`
+	Location    WeatherLocation    `json:"location" xml:"location"`
+	Temperature WeatherTemperature `json:"temperature" xml:"temperature"`
+	Condition   *WeatherCondition  `json:"condition,omitempty" xml:"condition,omitempty"`
+	Metadata    WeatherMetadata    `json:"metadata" xml:"metadata"`
+}
+
+type WeatherLocation struct {
+	City      string  `json:"city" xml:"city"`
+	State     string  `json:"state" xml:"state"`
+	Region    string  `json:"region,omitempty" xml:"region,omitempty"`
+	Country   string  `json:"country,omitempty" xml:"country,omitempty"`
+	Latitude  float64 `json:"latitude" xml:"latitude"`
+	Longitude float64 `json:"longitude" xml:"longitude"`
+}
+
+type WeatherTemperature struct {
+	Celsius    float64 `json:"celsius" xml:"celsius"`
+	Fahrenheit float64 `json:"fahrenheit" xml:"fahrenheit"`
+	Kelvin     float64 `json:"kelvin" xml:"kelvin"`
+}
+
+type WeatherCondition struct {
+	Code        int    `json:"code" xml:"code"`
+	Description string `json:"description" xml:"description"`
+}
+
+type WeatherMetadata struct {
+	// ObservedAt is left out when the provider did not say when it measured.
+	ObservedAt *time.Time `json:"observed_at,omitempty" xml:"observed_at,omitempty"`
+	APIVersion string     `json:"api_version" xml:"api_version"`
+	Locale     string     `json:"locale" xml:"locale"`
+}
+
+// weatherPresenter shapes the use case output into the contract of one API
+// version, describing the condition in locale where the contract has one.
+type weatherPresenter func(output *getWeatherByCep.GetWeatherByCepOutput, locale string) interface{}
+
+var weatherPresenters = map[string]weatherPresenter{
+	httpShared.APIVersion1: presentWeatherV1,
+	httpShared.APIVersion2: presentWeatherV2,
+}
+
+func presentWeatherV1(output *getWeatherByCep.GetWeatherByCepOutput, _ string) interface{} {
+	return WeatherV1{
+		TempC: output.TempC,
+		TempF: output.TempF,
+		TempK: output.TempK,
+	}
+}
+
+func presentWeatherV2(output *getWeatherByCep.GetWeatherByCepOutput, locale string) interface{} {
+	weather := WeatherV2{
+		Cep: output.Cep,
+		Location: WeatherLocation{
+			City:      output.City,
+			State:     output.State,
+			Region:    output.Region,
+			Country:   output.Country,
+			Latitude:  output.Latitude,
+			Longitude: output.Longitude,
+		},
+		Temperature: WeatherTemperature{
+			Celsius:    output.TempC,
+			Fahrenheit: output.TempF,
+			Kelvin:     output.TempK,
+		},
+		Metadata: WeatherMetadata{
+			APIVersion: httpShared.APIVersion2,
+			Locale:     locale,
+		},
+	}
+	if output.ConditionCode != 0 || output.Condition != "" {
+		weather.Condition = &WeatherCondition{
+			Code:        output.ConditionCode,
+			Description: i18n.Condition(locale, output.ConditionCode, output.Condition),
+		}
+	}
+	if !output.ObservedAt.IsZero() {
+		observedAt := output.ObservedAt.UTC()
+		weather.Metadata.ObservedAt = &observedAt
+	}
+	return weather
+}
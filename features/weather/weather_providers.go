@@ -3,14 +3,18 @@ package weather
 import (
 	"github.com/gerps2/desafio-cloud-run/features/weather/getWeatherByCep"
 	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
 	viacep "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/viapcep"
 	weather "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather"
+	"github.com/gerps2/desafio-cloud-run/shared/telemetry"
 )
 
 func ProvideGetWeatherByCepUseCase(
 	viaCepRepo viacep.ViaCepRepositoryInterface,
 	weatherRepo weather.WeatherRepositoryInterface,
 	logger logger.Logger,
+	tel *telemetry.Telemetry,
+	m *metrics.Metrics,
 ) getWeatherByCep.GetWeatherByCepUseCaseInterface {
-	return getWeatherByCep.NewGetWeatherByCepUseCase(viaCepRepo, weatherRepo, logger)
+	return getWeatherByCep.NewGetWeatherByCepUseCase(viaCepRepo, weatherRepo, logger, tel, m)
 }
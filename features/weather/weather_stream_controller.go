@@ -0,0 +1,371 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/features/weather/getWeatherByCep"
+	"github.com/gerps2/desafio-cloud-run/features/weather/liveWeather"
+	"github.com/gerps2/desafio-cloud-run/shared/auth"
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+	httpShared "github.com/gerps2/desafio-cloud-run/shared/http"
+	"github.com/gerps2/desafio-cloud-run/shared/i18n"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/openapi"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait    = 10 * time.Second
+	wsMaxMessage   = 4096
+	wsOutboundSize = 16
+)
+
+var (
+	msgCepsPerConnection = sharedErrors.NewMessage("ceps.per_connection", "at most %d CEPs can be followed per connection")
+	msgUnknownAction     = sharedErrors.NewMessage("stream.unknown_action", "Unknown action")
+	msgActionExpected    = sharedErrors.NewMessage("stream.action_expected", `action must be "subscribe" or "unsubscribe"`)
+)
+
+// StreamMessage is what a WebSocket client sends: {"action": "subscribe",
+// "ceps": ["01001000"]}, or "unsubscribe".
+type StreamMessage struct {
+	Action string   `json:"action"`
+	Ceps   []string `json:"ceps"`
+}
+
+// StreamEvent is what the server sends over WebSocket. Type is weather,
+// error, subscribed or unsubscribed.
+type StreamEvent struct {
+	Type    string                 `json:"type"`
+	Cep     string                 `json:"cep,omitempty"`
+	Weather *liveWeather.Reading   `json:"weather,omitempty"`
+	Error   *sharedErrors.APIError `json:"error,omitempty"`
+}
+
+type WeatherStreamController struct {
+	hub              *liveWeather.Hub
+	config           config.StreamConfig
+	versioningConfig config.VersioningConfig
+	upgrader         websocket.Upgrader
+	logger           logger.Logger
+}
+
+func NewWeatherStreamController(hub *liveWeather.Hub, cfg *config.Config, logger logger.Logger) *WeatherStreamController {
+	return &WeatherStreamController{
+		hub:              hub,
+		config:           cfg.Stream,
+		versioningConfig: cfg.Versioning,
+		upgrader: websocket.Upgrader{
+			// Credentials travel as API key or bearer token, never cookies,
+			// so cross-origin pages cannot ride on a visitor's session.
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+		logger: logger,
+	}
+}
+
+func (sc *WeatherStreamController) RegisterRoutes(router *gin.Engine) {
+	if !sc.config.Enabled {
+		return
+	}
+
+	api := router.Group("/api/v1", httpShared.V1OnlyVersionMiddleware(sc.versioningConfig), auth.RequireScopes(auth.ScopeWeatherRead))
+	{
+		api.GET("/weather/:cep/stream", sc.StreamWeather)
+		api.GET("/weather/ws", sc.WeatherSocket)
+	}
+}
+
+// DescribeRoutes documents the routes of RegisterRoutes in the OpenAPI spec.
+func (sc *WeatherStreamController) DescribeRoutes(doc *openapi.Document) {
+	if !sc.config.Enabled {
+		return
+	}
+
+	reading := doc.Schema("LiveWeatherReading", liveWeather.Reading{})
+	doc.Schema("StreamMessage", StreamMessage{})
+	doc.Schema("StreamEvent", StreamEvent{})
+
+	doc.Add(http.MethodGet, httpShared.StreamRoute, openapi.Localized(openapi.Operation{
+		Tags:    []string{"weather"},
+		Summary: "Live temperature for a zipcode (Server-Sent Events)",
+		Description: fmt.Sprintf("Streams a weather event with the current reading and another each time it changes; "+
+			"an error event replaces it while WeatherAPI fails. A comment line is sent every %ds as heartbeat.", sc.config.HeartbeatSec),
+		OperationID: "streamWeatherByCep",
+		Parameters: []openapi.Parameter{{
+			Name: "cep", In: "path", Description: "8-digit CEP, with or without hyphen", Required: true,
+			Schema: &openapi.Schema{Type: "string"}, Example: "01001-000",
+		}},
+		Responses: openapi.Responses(openapi.CommonErrors(true), map[string]openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "Event stream; each data line is a LiveWeatherReading, or an ErrorResponse-like error for error events",
+				Content:     map[string]openapi.MediaType{"text/event-stream": {Schema: reading}},
+			},
+			openapi.Status(http.StatusUnprocessableEntity): openapi.Error("Invalid zipcode",
+				openapi.ErrorCode{Code: "INVALID_ZIPCODE", Message: "the CEP is not 8 digits"}),
+			openapi.Status(http.StatusNotFound): openapi.Error("Zipcode not found",
+				openapi.ErrorCode{Code: "ZIPCODE_NOT_FOUND", Message: "ViaCep does not know the CEP"}),
+			openapi.Status(http.StatusBadGateway): openapi.Error("Upstream failure",
+				openapi.ErrorCode{Code: sharedErrors.CodeExternalService, Message: "ViaCep failed"}),
+		}),
+		Security: openapi.Secured(auth.ScopeWeatherRead),
+	}, i18n.Locales()))
+
+	doc.Add(http.MethodGet, httpShared.SocketRoute, openapi.Localized(openapi.Operation{
+		Tags:    []string{"weather"},
+		Summary: "Live temperatures for several zipcodes (WebSocket)",
+		Description: fmt.Sprintf("After the upgrade, send StreamMessage frames to subscribe to or unsubscribe from up to %d CEPs; "+
+			"the server answers with StreamEvent frames. Browsers pass the API key as query parameter.", sc.config.MaxCeps),
+		OperationID: "weatherSocket",
+		Responses: openapi.Responses(openapi.CommonErrors(true), map[string]openapi.Response{
+			openapi.Status(http.StatusSwitchingProtocols): {Description: "Upgraded to WebSocket"},
+		}),
+		Security: openapi.Secured(auth.ScopeWeatherRead),
+	}, i18n.Locales()))
+}
+
+// StreamWeather serves the updates of one CEP as Server-Sent Events until the
+// client goes away or the server drains.
+func (sc *WeatherStreamController) StreamWeather(c *gin.Context) {
+	cepParam := c.Param("cep")
+	ctx := logger.ContextWithFields(c.Request.Context(), "cep", cepParam)
+	log := sc.logger.WithContext(ctx)
+
+	sub, err := sc.hub.Subscribe(ctx, cepParam)
+	if err != nil {
+		log.Error("Error subscribing to live weather: %v", err)
+		respondWithError(c, err)
+		return
+	}
+	defer sub.Close()
+
+	// The server write timeout is meant for ordinary responses.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Debug("Could not clear the write deadline of the stream: %v", err)
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	log.Info("Live weather stream opened")
+	defer log.Info("Live weather stream closed")
+
+	heartbeat := time.NewTicker(time.Duration(sc.config.HeartbeatSec) * time.Second)
+	defer heartbeat.Stop()
+
+	locale := httpShared.Locale(c)
+	id := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case update, ok := <-sub.Updates():
+			if !ok {
+				return
+			}
+			id++
+			update.Reading = localizeReading(update.Reading, locale)
+			update.Err = i18n.LocalizeError(locale, update.Err)
+			if err := writeSSE(c.Writer, id, update); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+func writeSSE(w gin.ResponseWriter, id int, update liveWeather.Update) error {
+	event, payload := "weather", interface{}(update.Reading)
+	if update.Err != nil {
+		event, payload = "error", update.Err
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event, data)
+	return err
+}
+
+// WeatherSocket lets a client follow several CEPs over one WebSocket. Reads
+// happen on the handler goroutine and all writes on a single writer
+// goroutine, as gorilla/websocket requires.
+func (sc *WeatherStreamController) WeatherSocket(c *gin.Context) {
+	// The upgrader writes the 101 itself, so hand it the headers set by the
+	// middlewares, such as the v1 deprecation ones.
+	conn, err := sc.upgrader.Upgrade(c.Writer, c.Request, c.Writer.Header())
+	if err != nil {
+		// The upgrader has already answered with an HTTP error.
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	log := sc.logger.WithContext(ctx)
+	log.Info("Live weather socket opened")
+	defer log.Info("Live weather socket closed")
+
+	out := make(chan StreamEvent, wsOutboundSize)
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		sc.writeSocket(ctx, conn, out, httpShared.Locale(c))
+		// Unblocks ReadJSON when the writer stops first.
+		conn.Close()
+	}()
+
+	subs := map[string]*liveWeather.Subscription{}
+	defer func() {
+		for _, sub := range subs {
+			sub.Close()
+		}
+		cancel()
+		<-writerDone
+	}()
+
+	emit := func(event StreamEvent) bool {
+		select {
+		case out <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	heartbeat := time.Duration(sc.config.HeartbeatSec) * time.Second
+	conn.SetReadLimit(wsMaxMessage)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+	})
+
+	for {
+		var msg StreamMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Debug("Live weather socket read failed: %v", err)
+			}
+			return
+		}
+
+		switch msg.Action {
+		case "subscribe":
+			for _, cep := range msg.Ceps {
+				if _, ok := subs[cep]; ok {
+					continue
+				}
+				if len(subs) >= sc.config.MaxCeps {
+					emit(StreamEvent{Type: "error", Cep: cep, Error: sharedErrors.NewValidationError(getWeatherByCep.MsgTooManyCeps.Format(),
+						[]sharedErrors.Text{msgCepsPerConnection.Format(sc.config.MaxCeps)})})
+					continue
+				}
+
+				sub, err := sc.hub.Subscribe(logger.ContextWithFields(ctx, "cep", cep), cep)
+				if err != nil {
+					emit(StreamEvent{Type: "error", Cep: cep, Error: toAPIError(err)})
+					continue
+				}
+				subs[cep] = sub
+				if !emit(StreamEvent{Type: "subscribed", Cep: cep}) {
+					return
+				}
+				go forward(ctx, cep, sub, out)
+			}
+		case "unsubscribe":
+			for _, cep := range msg.Ceps {
+				if sub, ok := subs[cep]; ok {
+					sub.Close()
+					delete(subs, cep)
+				}
+				emit(StreamEvent{Type: "unsubscribed", Cep: cep})
+			}
+		default:
+			emit(StreamEvent{Type: "error", Error: sharedErrors.NewValidationError(msgUnknownAction.Format(),
+				[]sharedErrors.Text{msgActionExpected.Format()})})
+		}
+	}
+}
+
+// forward relays the updates of one subscription, labelled with the CEP the
+// client used, until it is closed.
+func forward(ctx context.Context, cep string, sub *liveWeather.Subscription, out chan<- StreamEvent) {
+	for update := range sub.Updates() {
+		event := StreamEvent{Type: "weather", Cep: cep, Weather: update.Reading}
+		if update.Err != nil {
+			event = StreamEvent{Type: "error", Cep: cep, Error: update.Err}
+		}
+		select {
+		case out <- event:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (sc *WeatherStreamController) writeSocket(ctx context.Context, conn *websocket.Conn, out <-chan StreamEvent, locale string) {
+	ping := time.NewTicker(time.Duration(sc.config.HeartbeatSec) * time.Second)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sc.hub.Done():
+			_ = conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"), time.Now().Add(wsWriteWait))
+			return
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		case event := <-out:
+			event.Weather = localizeReading(event.Weather, locale)
+			event.Error = i18n.LocalizeError(locale, event.Error)
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// localizeReading copies the reading, which the hub shares between the
+// subscribers of a city, with its condition in the client's locale.
+func localizeReading(reading *liveWeather.Reading, locale string) *liveWeather.Reading {
+	if reading == nil {
+		return nil
+	}
+	localized := *reading
+	localized.Condition = i18n.Condition(locale, reading.ConditionCode, reading.Condition)
+	return &localized
+}
+
+func toAPIError(err error) *sharedErrors.APIError {
+	var apiErr *sharedErrors.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return sharedErrors.NewInternalError(sharedErrors.MsgInternalError.Format(), nil)
+}
+
+func respondWithError(c *gin.Context, err error) {
+	httpShared.RespondWithAPIError(c, toAPIError(err))
+}
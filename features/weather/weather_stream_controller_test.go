@@ -0,0 +1,173 @@
+package weather
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/features/weather/liveWeather"
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/domain/valueObjects"
+	httpShared "github.com/gerps2/desafio-cloud-run/shared/http"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+	viacep "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/viapcep"
+	viaCepMocks "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/viapcep/mocks"
+	weatherRepo "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather"
+	weatherMocks "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStreamServer(t *testing.T) (*httptest.Server, *liveWeather.Hub) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	viaCepRepo := viaCepMocks.NewMockViaCepRepositoryInterface(t)
+	viaCepRepo.EXPECT().GetAddress(mock.Anything, valueObjects.Cep("01001-000")).
+		Return(&viacep.ViaCepResponse{Cep: "01001-000", City: "São Paulo"}, nil).Maybe()
+	viaCepRepo.EXPECT().GetAddress(mock.Anything, valueObjects.Cep("99999-999")).
+		Return(nil, viacep.ErrZipcodeNotFound).Maybe()
+
+	response := &weatherRepo.WeatherResponse{}
+	response.Current.TempC = 25
+	response.Current.Condition.Text = "Sunny"
+	response.Current.Condition.Code = 1000
+	weatherRepository := weatherMocks.NewMockWeatherRepositoryInterface(t)
+	weatherRepository.EXPECT().GetWeather(mock.Anything, "São Paulo").Return(response, nil).Maybe()
+
+	cfg := &config.Config{
+		Log:    config.LogConfig{Level: "error", Format: "json"},
+		Stream: config.StreamConfig{Enabled: true, PollIntervalSec: 60, HeartbeatSec: 15, MaxCeps: 1},
+		Versioning: config.VersioningConfig{
+			Default:        "v1",
+			V1DeprecatedAt: "2026-10-19T00:00:00Z",
+			V1SunsetAt:     "2027-10-19T00:00:00Z",
+		},
+	}
+	log := logger.NewWithWriter(io.Discard, cfg.Log, logger.NewLevel(cfg))
+	hub := liveWeather.NewHub(cfg, viaCepRepo, weatherRepository, metrics.New(), log)
+
+	router := gin.New()
+	router.Use(httpShared.LocaleMiddleware())
+	NewWeatherStreamController(hub, cfg, log).RegisterRoutes(router)
+	server := httptest.NewServer(router)
+	t.Cleanup(func() {
+		hub.Shutdown()
+		server.Close()
+	})
+
+	return server, hub
+}
+
+func TestWeatherStreamSSE(t *testing.T) {
+	server, hub := setupStreamServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/weather/01001000/stream", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assertDeprecatedV1(t, resp.Header)
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+	assert.Equal(t, "id: 1", lines[0])
+	assert.Equal(t, "event: weather", lines[1])
+	assert.Contains(t, lines[2], `"cep":"01001-000","city":"São Paulo","temp_C":25`)
+	assert.Contains(t, lines[2], `"condition":"Clear"`)
+
+	hub.Shutdown()
+	_, err = io.ReadAll(reader)
+	assert.NoError(t, err, "the stream ends cleanly when the server drains")
+}
+
+func TestWeatherStreamSSERejectsUnknownCep(t *testing.T) {
+	server, _ := setupStreamServer(t)
+
+	resp, err := http.Get(server.URL + "/api/v1/weather/99999999/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestWeatherSocket(t *testing.T) {
+	server, _ := setupStreamServer(t)
+
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/v1/weather/ws",
+		http.Header{"Accept-Language": {"es"}})
+	require.NoError(t, err)
+	defer conn.Close()
+	assertDeprecatedV1(t, resp.Header)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	require.NoError(t, conn.WriteJSON(StreamMessage{Action: "subscribe", Ceps: []string{"99999999", "01001000", "20040002"}}))
+
+	var events []StreamEvent
+	for len(events) < 4 {
+		var event StreamEvent
+		require.NoError(t, conn.ReadJSON(&event))
+		events = append(events, event)
+	}
+
+	assert.Equal(t, "error", events[0].Type)
+	assert.Equal(t, "ZIPCODE_NOT_FOUND", events[0].Error.Code)
+	assert.Equal(t, "no se encontró el código postal", events[0].Error.Message)
+	assert.Equal(t, StreamEvent{Type: "subscribed", Cep: "01001000"}, events[1])
+	byType := map[string]StreamEvent{events[2].Type: events[2], events[3].Type: events[3]}
+	assert.Equal(t, "INVALID_INPUT", byType["error"].Error.Code, "MaxCeps is 1")
+	require.NotNil(t, byType["weather"].Weather)
+	assert.Equal(t, 25.0, byType["weather"].Weather.TempC)
+	assert.Equal(t, "Despejado", byType["weather"].Weather.Condition)
+
+	require.NoError(t, conn.WriteJSON(StreamMessage{Action: "unsubscribe", Ceps: []string{"01001000"}}))
+	var event StreamEvent
+	require.NoError(t, conn.ReadJSON(&event))
+	assert.Equal(t, StreamEvent{Type: "unsubscribed", Cep: "01001000"}, event)
+}
+
+func TestWeatherStreamRejectsConflictingVersion(t *testing.T) {
+	server, _ := setupStreamServer(t)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/weather/01001000/stream", nil)
+	require.NoError(t, err)
+	req.Header.Set(httpShared.VersionHeader, "2")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// assertDeprecatedV1 checks the stream routes announce the v1 deprecation,
+// without a successor since v2 has no streams.
+func assertDeprecatedV1(t *testing.T, header http.Header) {
+	t.Helper()
+	assert.Equal(t, "v1", header.Get(httpShared.VersionHeader))
+	assert.Equal(t, "@1792368000", header.Get("Deprecation"))
+	assert.Equal(t, "Tue, 19 Oct 2027 00:00:00 GMT", header.Get("Sunset"))
+	assert.Empty(t, header.Get("Link"))
+}
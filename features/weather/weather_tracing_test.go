@@ -0,0 +1,165 @@
+package weather
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gerps2/desafio-cloud-run/features/weather/getWeatherByCep"
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	httpShared "github.com/gerps2/desafio-cloud-run/shared/http"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+	viacep "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/viapcep"
+	weather "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather"
+	"github.com/gerps2/desafio-cloud-run/shared/telemetry"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	incomingTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	incomingSpanID  = "00f067aa0ba902b7"
+)
+
+type tracingFixture struct {
+	router         *gin.Engine
+	exporter       *tracetest.InMemoryExporter
+	upstreamTraces []string
+}
+
+func setupTracingFixture(t *testing.T, weatherStatus int) *tracingFixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	fixture := &tracingFixture{exporter: tracetest.NewInMemoryExporter()}
+	tel := &telemetry.Telemetry{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(fixture.exporter)),
+		Propagator:     telemetry.NewPropagator(),
+	}
+
+	viaCepServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fixture.upstreamTraces = append(fixture.upstreamTraces, r.Header.Get("traceparent"))
+		_, _ = w.Write([]byte(`{"cep":"01001-000","localidade":"São Paulo","uf":"SP"}`))
+	}))
+	t.Cleanup(viaCepServer.Close)
+
+	weatherServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fixture.upstreamTraces = append(fixture.upstreamTraces, r.Header.Get("traceparent"))
+		w.WriteHeader(weatherStatus)
+		_, _ = w.Write([]byte(`{"current":{"temp_c":25.5,"temp_f":77.9}}`))
+	}))
+	t.Cleanup(weatherServer.Close)
+
+	m := metrics.New()
+	cfg := &config.Config{Log: config.LogConfig{Level: "error"}}
+	log := logger.NewWithWriter(io.Discard, cfg.Log, logger.NewLevel(cfg))
+
+	useCase := getWeatherByCep.NewGetWeatherByCepUseCase(
+		viacep.NewViaCepRepository(viacep.NewClient(viaCepServer.URL+"/", viaCepServer.Client(), tel, m)),
+		weather.NewWeatherRepository(weather.NewClient(weatherServer.URL+"/?key=", "test-key", weatherServer.Client(), tel, m)),
+		log,
+		tel,
+		m,
+	)
+
+	fixture.router = gin.New()
+	fixture.router.Use(httpShared.TracingMiddleware(tel))
+	NewWeatherController(useCase, config.NewStore(&config.Config{}, config.Options{}), log).RegisterRoutes(fixture.router)
+
+	return fixture
+}
+
+func (f *tracingFixture) get(path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("traceparent", "00-"+incomingTraceID+"-"+incomingSpanID+"-01")
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+	return w
+}
+
+func spansByName(spans tracetest.SpanStubs) map[string]tracetest.SpanStub {
+	byName := make(map[string]tracetest.SpanStub, len(spans))
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+	return byName
+}
+
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestWeatherTracingSpanStructure(t *testing.T) {
+	fixture := setupTracingFixture(t, http.StatusOK)
+
+	w := fixture.get("/api/v1/weather/01001000")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	spans := spansByName(fixture.exporter.GetSpans())
+	require.Len(t, spans, 4)
+
+	server := spans["GET /api/v1/weather/:cep"]
+	useCase := spans["GetWeatherByCepUseCase.Execute"]
+	viaCep := spans["viacep.GetAddress"]
+	weatherSpan := spans["weather.GetWeather"]
+
+	// All spans continue the incoming W3C trace.
+	for name, span := range spans {
+		assert.Equal(t, incomingTraceID, span.SpanContext.TraceID().String(), name)
+	}
+
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, incomingSpanID, server.Parent.SpanID().String())
+	assert.True(t, server.Parent.IsRemote())
+	assert.Equal(t, int64(http.StatusOK), spanAttribute(server, "http.response.status_code").AsInt64())
+	assert.Equal(t, "/api/v1/weather/:cep", spanAttribute(server, "http.route").AsString())
+
+	assert.Equal(t, server.SpanContext.SpanID(), useCase.Parent.SpanID())
+	assert.Equal(t, "01001000", spanAttribute(useCase, telemetry.AttrCep).AsString())
+	assert.Equal(t, "São Paulo", spanAttribute(useCase, telemetry.AttrCity).AsString())
+
+	assert.Equal(t, useCase.SpanContext.SpanID(), viaCep.Parent.SpanID())
+	assert.Equal(t, trace.SpanKindClient, viaCep.SpanKind)
+	assert.Equal(t, "01001-000", spanAttribute(viaCep, telemetry.AttrCep).AsString())
+	assert.Equal(t, int64(http.StatusOK), spanAttribute(viaCep, "http.response.status_code").AsInt64())
+
+	assert.Equal(t, useCase.SpanContext.SpanID(), weatherSpan.Parent.SpanID())
+	assert.Equal(t, trace.SpanKindClient, weatherSpan.SpanKind)
+	assert.Equal(t, "São Paulo", spanAttribute(weatherSpan, telemetry.AttrCity).AsString())
+
+	// Outbound requests carry the client span as parent.
+	require.Len(t, fixture.upstreamTraces, 2)
+	assert.Equal(t, "00-"+incomingTraceID+"-"+viaCep.SpanContext.SpanID().String()+"-01", fixture.upstreamTraces[0])
+	assert.Equal(t, "00-"+incomingTraceID+"-"+weatherSpan.SpanContext.SpanID().String()+"-01", fixture.upstreamTraces[1])
+}
+
+func TestWeatherTracingRecordsUpstreamFailure(t *testing.T) {
+	fixture := setupTracingFixture(t, http.StatusServiceUnavailable)
+
+	w := fixture.get("/api/v1/weather/01001000")
+	require.Equal(t, http.StatusBadGateway, w.Code)
+
+	spans := spansByName(fixture.exporter.GetSpans())
+	require.Len(t, spans, 4)
+
+	weatherSpan := spans["weather.GetWeather"]
+	assert.Equal(t, codes.Error, weatherSpan.Status.Code)
+	assert.Equal(t, int64(http.StatusServiceUnavailable), spanAttribute(weatherSpan, "http.response.status_code").AsInt64())
+
+	assert.Equal(t, codes.Error, spans["GetWeatherByCepUseCase.Execute"].Status.Code)
+	assert.Equal(t, codes.Error, spans["GET /api/v1/weather/:cep"].Status.Code)
+}
@@ -0,0 +1,653 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.1
+// 	protoc        (unknown)
+// source: weather/v1/weather.proto
+
+package weatherv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetWeatherByCepRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 8-digit CEP, with or without hyphen.
+	Cep           string `protobuf:"bytes,1,opt,name=cep,proto3" json:"cep,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWeatherByCepRequest) Reset() {
+	*x = GetWeatherByCepRequest{}
+	mi := &file_weather_v1_weather_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWeatherByCepRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWeatherByCepRequest) ProtoMessage() {}
+
+func (x *GetWeatherByCepRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWeatherByCepRequest.ProtoReflect.Descriptor instead.
+func (*GetWeatherByCepRequest) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetWeatherByCepRequest) GetCep() string {
+	if x != nil {
+		return x.Cep
+	}
+	return ""
+}
+
+type GetWeatherByCepResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Weather       *Weather               `protobuf:"bytes,1,opt,name=weather,proto3" json:"weather,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWeatherByCepResponse) Reset() {
+	*x = GetWeatherByCepResponse{}
+	mi := &file_weather_v1_weather_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWeatherByCepResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWeatherByCepResponse) ProtoMessage() {}
+
+func (x *GetWeatherByCepResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWeatherByCepResponse.ProtoReflect.Descriptor instead.
+func (*GetWeatherByCepResponse) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetWeatherByCepResponse) GetWeather() *Weather {
+	if x != nil {
+		return x.Weather
+	}
+	return nil
+}
+
+type BatchGetWeatherRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ceps          []string               `protobuf:"bytes,1,rep,name=ceps,proto3" json:"ceps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetWeatherRequest) Reset() {
+	*x = BatchGetWeatherRequest{}
+	mi := &file_weather_v1_weather_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetWeatherRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetWeatherRequest) ProtoMessage() {}
+
+func (x *BatchGetWeatherRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetWeatherRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetWeatherRequest) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchGetWeatherRequest) GetCeps() []string {
+	if x != nil {
+		return x.Ceps
+	}
+	return nil
+}
+
+type BatchGetWeatherResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One result per requested CEP, in request order.
+	Results       []*WeatherResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetWeatherResponse) Reset() {
+	*x = BatchGetWeatherResponse{}
+	mi := &file_weather_v1_weather_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetWeatherResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetWeatherResponse) ProtoMessage() {}
+
+func (x *BatchGetWeatherResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetWeatherResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetWeatherResponse) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchGetWeatherResponse) GetResults() []*WeatherResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type StreamWeatherRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ceps          []string               `protobuf:"bytes,1,rep,name=ceps,proto3" json:"ceps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamWeatherRequest) Reset() {
+	*x = StreamWeatherRequest{}
+	mi := &file_weather_v1_weather_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamWeatherRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamWeatherRequest) ProtoMessage() {}
+
+func (x *StreamWeatherRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamWeatherRequest.ProtoReflect.Descriptor instead.
+func (*StreamWeatherRequest) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StreamWeatherRequest) GetCeps() []string {
+	if x != nil {
+		return x.Ceps
+	}
+	return nil
+}
+
+type StreamWeatherResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        *WeatherResult         `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamWeatherResponse) Reset() {
+	*x = StreamWeatherResponse{}
+	mi := &file_weather_v1_weather_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamWeatherResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamWeatherResponse) ProtoMessage() {}
+
+func (x *StreamWeatherResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamWeatherResponse.ProtoReflect.Descriptor instead.
+func (*StreamWeatherResponse) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StreamWeatherResponse) GetResult() *WeatherResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type Weather struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TempC         float64                `protobuf:"fixed64,1,opt,name=temp_c,json=tempC,proto3" json:"temp_c,omitempty"`
+	TempF         float64                `protobuf:"fixed64,2,opt,name=temp_f,json=tempF,proto3" json:"temp_f,omitempty"`
+	TempK         float64                `protobuf:"fixed64,3,opt,name=temp_k,json=tempK,proto3" json:"temp_k,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Weather) Reset() {
+	*x = Weather{}
+	mi := &file_weather_v1_weather_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Weather) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Weather) ProtoMessage() {}
+
+func (x *Weather) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Weather.ProtoReflect.Descriptor instead.
+func (*Weather) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Weather) GetTempC() float64 {
+	if x != nil {
+		return x.TempC
+	}
+	return 0
+}
+
+func (x *Weather) GetTempF() float64 {
+	if x != nil {
+		return x.TempF
+	}
+	return 0
+}
+
+func (x *Weather) GetTempK() float64 {
+	if x != nil {
+		return x.TempK
+	}
+	return 0
+}
+
+type WeatherResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Cep   string                 `protobuf:"bytes,1,opt,name=cep,proto3" json:"cep,omitempty"`
+	// Types that are valid to be assigned to Result:
+	//
+	//	*WeatherResult_Weather
+	//	*WeatherResult_Error
+	Result        isWeatherResult_Result `protobuf_oneof:"result"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WeatherResult) Reset() {
+	*x = WeatherResult{}
+	mi := &file_weather_v1_weather_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WeatherResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WeatherResult) ProtoMessage() {}
+
+func (x *WeatherResult) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WeatherResult.ProtoReflect.Descriptor instead.
+func (*WeatherResult) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *WeatherResult) GetCep() string {
+	if x != nil {
+		return x.Cep
+	}
+	return ""
+}
+
+func (x *WeatherResult) GetResult() isWeatherResult_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *WeatherResult) GetWeather() *Weather {
+	if x != nil {
+		if x, ok := x.Result.(*WeatherResult_Weather); ok {
+			return x.Weather
+		}
+	}
+	return nil
+}
+
+func (x *WeatherResult) GetError() *Error {
+	if x != nil {
+		if x, ok := x.Result.(*WeatherResult_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+type isWeatherResult_Result interface {
+	isWeatherResult_Result()
+}
+
+type WeatherResult_Weather struct {
+	Weather *Weather `protobuf:"bytes,2,opt,name=weather,proto3,oneof"`
+}
+
+type WeatherResult_Error struct {
+	Error *Error `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*WeatherResult_Weather) isWeatherResult_Result() {}
+
+func (*WeatherResult_Error) isWeatherResult_Result() {}
+
+type Error struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// API error code, e.g. INVALID_ZIPCODE or ZIPCODE_NOT_FOUND.
+	Code    string   `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Causes  []string `protobuf:"bytes,3,rep,name=causes,proto3" json:"causes,omitempty"`
+	// gRPC status code the error maps to.
+	GrpcCode      int32 `protobuf:"varint,4,opt,name=grpc_code,json=grpcCode,proto3" json:"grpc_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_weather_v1_weather_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Error) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Error) GetCauses() []string {
+	if x != nil {
+		return x.Causes
+	}
+	return nil
+}
+
+func (x *Error) GetGrpcCode() int32 {
+	if x != nil {
+		return x.GrpcCode
+	}
+	return 0
+}
+
+var File_weather_v1_weather_proto protoreflect.FileDescriptor
+
+var file_weather_v1_weather_proto_rawDesc = []byte{
+	0x0a, 0x18, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2f, 0x76, 0x31, 0x2f, 0x77, 0x65, 0x61,
+	0x74, 0x68, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x77, 0x65, 0x61, 0x74,
+	0x68, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x22, 0x2a, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x57, 0x65, 0x61,
+	0x74, 0x68, 0x65, 0x72, 0x42, 0x79, 0x43, 0x65, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x10, 0x0a, 0x03, 0x63, 0x65, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x63,
+	0x65, 0x70, 0x22, 0x48, 0x0a, 0x17, 0x47, 0x65, 0x74, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72,
+	0x42, 0x79, 0x43, 0x65, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2d, 0x0a,
+	0x07, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13,
+	0x2e, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x65, 0x61, 0x74,
+	0x68, 0x65, 0x72, 0x52, 0x07, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x22, 0x2c, 0x0a, 0x16,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x74, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x65, 0x70, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x63, 0x65, 0x70, 0x73, 0x22, 0x4e, 0x0a, 0x17, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x47, 0x65, 0x74, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x33, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x22, 0x2a, 0x0a, 0x14, 0x53, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x65, 0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x04, 0x63, 0x65, 0x70, 0x73, 0x22, 0x4a, 0x0a, 0x15, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x31, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x65, 0x61,
+	0x74, 0x68, 0x65, 0x72, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x22, 0x4e, 0x0a, 0x07, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x12, 0x15, 0x0a,
+	0x06, 0x74, 0x65, 0x6d, 0x70, 0x5f, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x74,
+	0x65, 0x6d, 0x70, 0x43, 0x12, 0x15, 0x0a, 0x06, 0x74, 0x65, 0x6d, 0x70, 0x5f, 0x66, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x74, 0x65, 0x6d, 0x70, 0x46, 0x12, 0x15, 0x0a, 0x06, 0x74,
+	0x65, 0x6d, 0x70, 0x5f, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x74, 0x65, 0x6d,
+	0x70, 0x4b, 0x22, 0x87, 0x01, 0x0a, 0x0d, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x52, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x65, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x63, 0x65, 0x70, 0x12, 0x2f, 0x0a, 0x07, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65,
+	0x72, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x48, 0x00, 0x52, 0x07,
+	0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x12, 0x29, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x48, 0x00, 0x52, 0x05, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x42, 0x08, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x6a, 0x0a, 0x05,
+	0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x61, 0x75, 0x73, 0x65, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x06, 0x63, 0x61, 0x75, 0x73, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x67,
+	0x72, 0x70, 0x63, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08,
+	0x67, 0x72, 0x70, 0x63, 0x43, 0x6f, 0x64, 0x65, 0x32, 0xa0, 0x02, 0x0a, 0x0e, 0x57, 0x65, 0x61,
+	0x74, 0x68, 0x65, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x5a, 0x0a, 0x0f, 0x47,
+	0x65, 0x74, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x42, 0x79, 0x43, 0x65, 0x70, 0x12, 0x22,
+	0x2e, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x57,
+	0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x42, 0x79, 0x43, 0x65, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x23, 0x2e, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x47, 0x65, 0x74, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x42, 0x79, 0x43, 0x65, 0x70, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5a, 0x0a, 0x0f, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x47, 0x65, 0x74, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x12, 0x22, 0x2e, 0x77, 0x65, 0x61,
+	0x74, 0x68, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x74,
+	0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23,
+	0x2e, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x47, 0x65, 0x74, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x56, 0x0a, 0x0d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x57, 0x65, 0x61,
+	0x74, 0x68, 0x65, 0x72, 0x12, 0x20, 0x2e, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65,
+	0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42, 0x3e, 0x5a, 0x3c, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x65, 0x72, 0x70, 0x73, 0x32,
+	0x2f, 0x64, 0x65, 0x73, 0x61, 0x66, 0x69, 0x6f, 0x2d, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x2d, 0x72,
+	0x75, 0x6e, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x2f, 0x76,
+	0x31, 0x3b, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_weather_v1_weather_proto_rawDescOnce sync.Once
+	file_weather_v1_weather_proto_rawDescData = file_weather_v1_weather_proto_rawDesc
+)
+
+func file_weather_v1_weather_proto_rawDescGZIP() []byte {
+	file_weather_v1_weather_proto_rawDescOnce.Do(func() {
+		file_weather_v1_weather_proto_rawDescData = protoimpl.X.CompressGZIP(file_weather_v1_weather_proto_rawDescData)
+	})
+	return file_weather_v1_weather_proto_rawDescData
+}
+
+var file_weather_v1_weather_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_weather_v1_weather_proto_goTypes = []any{
+	(*GetWeatherByCepRequest)(nil),  // 0: weather.v1.GetWeatherByCepRequest
+	(*GetWeatherByCepResponse)(nil), // 1: weather.v1.GetWeatherByCepResponse
+	(*BatchGetWeatherRequest)(nil),  // 2: weather.v1.BatchGetWeatherRequest
+	(*BatchGetWeatherResponse)(nil), // 3: weather.v1.BatchGetWeatherResponse
+	(*StreamWeatherRequest)(nil),    // 4: weather.v1.StreamWeatherRequest
+	(*StreamWeatherResponse)(nil),   // 5: weather.v1.StreamWeatherResponse
+	(*Weather)(nil),                 // 6: weather.v1.Weather
+	(*WeatherResult)(nil),           // 7: weather.v1.WeatherResult
+	(*Error)(nil),                   // 8: weather.v1.Error
+}
+var file_weather_v1_weather_proto_depIdxs = []int32{
+	6, // 0: weather.v1.GetWeatherByCepResponse.weather:type_name -> weather.v1.Weather
+	7, // 1: weather.v1.BatchGetWeatherResponse.results:type_name -> weather.v1.WeatherResult
+	7, // 2: weather.v1.StreamWeatherResponse.result:type_name -> weather.v1.WeatherResult
+	6, // 3: weather.v1.WeatherResult.weather:type_name -> weather.v1.Weather
+	8, // 4: weather.v1.WeatherResult.error:type_name -> weather.v1.Error
+	0, // 5: weather.v1.WeatherService.GetWeatherByCep:input_type -> weather.v1.GetWeatherByCepRequest
+	2, // 6: weather.v1.WeatherService.BatchGetWeather:input_type -> weather.v1.BatchGetWeatherRequest
+	4, // 7: weather.v1.WeatherService.StreamWeather:input_type -> weather.v1.StreamWeatherRequest
+	1, // 8: weather.v1.WeatherService.GetWeatherByCep:output_type -> weather.v1.GetWeatherByCepResponse
+	3, // 9: weather.v1.WeatherService.BatchGetWeather:output_type -> weather.v1.BatchGetWeatherResponse
+	5, // 10: weather.v1.WeatherService.StreamWeather:output_type -> weather.v1.StreamWeatherResponse
+	8, // [8:11] is the sub-list for method output_type
+	5, // [5:8] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_weather_v1_weather_proto_init() }
+func file_weather_v1_weather_proto_init() {
+	if File_weather_v1_weather_proto != nil {
+		return
+	}
+	file_weather_v1_weather_proto_msgTypes[7].OneofWrappers = []any{
+		(*WeatherResult_Weather)(nil),
+		(*WeatherResult_Error)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_weather_v1_weather_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_weather_v1_weather_proto_goTypes,
+		DependencyIndexes: file_weather_v1_weather_proto_depIdxs,
+		MessageInfos:      file_weather_v1_weather_proto_msgTypes,
+	}.Build()
+	File_weather_v1_weather_proto = out.File
+	file_weather_v1_weather_proto_rawDesc = nil
+	file_weather_v1_weather_proto_goTypes = nil
+	file_weather_v1_weather_proto_depIdxs = nil
+}
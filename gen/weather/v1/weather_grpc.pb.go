@@ -0,0 +1,232 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: weather/v1/weather.proto
+
+package weatherv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	WeatherService_GetWeatherByCep_FullMethodName = "/weather.v1.WeatherService/GetWeatherByCep"
+	WeatherService_BatchGetWeather_FullMethodName = "/weather.v1.WeatherService/BatchGetWeather"
+	WeatherService_StreamWeather_FullMethodName   = "/weather.v1.WeatherService/StreamWeather"
+)
+
+// WeatherServiceClient is the client API for WeatherService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// WeatherService exposes the weather by CEP lookup to internal services.
+// Errors use the gRPC status codes mapped from the API error codes; the code
+// itself travels as the reason of a google.rpc.ErrorInfo detail.
+type WeatherServiceClient interface {
+	// GetWeatherByCep returns the current temperature of the CEP's city.
+	GetWeatherByCep(ctx context.Context, in *GetWeatherByCepRequest, opts ...grpc.CallOption) (*GetWeatherByCepResponse, error)
+	// BatchGetWeather looks up several CEPs; a failed CEP does not fail the
+	// batch and is reported in its result instead.
+	BatchGetWeather(ctx context.Context, in *BatchGetWeatherRequest, opts ...grpc.CallOption) (*BatchGetWeatherResponse, error)
+	// StreamWeather looks up several CEPs and streams each result as soon as
+	// it is ready, in completion order.
+	StreamWeather(ctx context.Context, in *StreamWeatherRequest, opts ...grpc.CallOption) (WeatherService_StreamWeatherClient, error)
+}
+
+type weatherServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWeatherServiceClient(cc grpc.ClientConnInterface) WeatherServiceClient {
+	return &weatherServiceClient{cc}
+}
+
+func (c *weatherServiceClient) GetWeatherByCep(ctx context.Context, in *GetWeatherByCepRequest, opts ...grpc.CallOption) (*GetWeatherByCepResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetWeatherByCepResponse)
+	err := c.cc.Invoke(ctx, WeatherService_GetWeatherByCep_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherServiceClient) BatchGetWeather(ctx context.Context, in *BatchGetWeatherRequest, opts ...grpc.CallOption) (*BatchGetWeatherResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetWeatherResponse)
+	err := c.cc.Invoke(ctx, WeatherService_BatchGetWeather_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherServiceClient) StreamWeather(ctx context.Context, in *StreamWeatherRequest, opts ...grpc.CallOption) (WeatherService_StreamWeatherClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WeatherService_ServiceDesc.Streams[0], WeatherService_StreamWeather_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &weatherServiceStreamWeatherClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type WeatherService_StreamWeatherClient interface {
+	Recv() (*StreamWeatherResponse, error)
+	grpc.ClientStream
+}
+
+type weatherServiceStreamWeatherClient struct {
+	grpc.ClientStream
+}
+
+func (x *weatherServiceStreamWeatherClient) Recv() (*StreamWeatherResponse, error) {
+	m := new(StreamWeatherResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// WeatherServiceServer is the server API for WeatherService service.
+// All implementations must embed UnimplementedWeatherServiceServer
+// for forward compatibility
+//
+// WeatherService exposes the weather by CEP lookup to internal services.
+// Errors use the gRPC status codes mapped from the API error codes; the code
+// itself travels as the reason of a google.rpc.ErrorInfo detail.
+type WeatherServiceServer interface {
+	// GetWeatherByCep returns the current temperature of the CEP's city.
+	GetWeatherByCep(context.Context, *GetWeatherByCepRequest) (*GetWeatherByCepResponse, error)
+	// BatchGetWeather looks up several CEPs; a failed CEP does not fail the
+	// batch and is reported in its result instead.
+	BatchGetWeather(context.Context, *BatchGetWeatherRequest) (*BatchGetWeatherResponse, error)
+	// StreamWeather looks up several CEPs and streams each result as soon as
+	// it is ready, in completion order.
+	StreamWeather(*StreamWeatherRequest, WeatherService_StreamWeatherServer) error
+	mustEmbedUnimplementedWeatherServiceServer()
+}
+
+// UnimplementedWeatherServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedWeatherServiceServer struct {
+}
+
+func (UnimplementedWeatherServiceServer) GetWeatherByCep(context.Context, *GetWeatherByCepRequest) (*GetWeatherByCepResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWeatherByCep not implemented")
+}
+func (UnimplementedWeatherServiceServer) BatchGetWeather(context.Context, *BatchGetWeatherRequest) (*BatchGetWeatherResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetWeather not implemented")
+}
+func (UnimplementedWeatherServiceServer) StreamWeather(*StreamWeatherRequest, WeatherService_StreamWeatherServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamWeather not implemented")
+}
+func (UnimplementedWeatherServiceServer) mustEmbedUnimplementedWeatherServiceServer() {}
+
+// UnsafeWeatherServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WeatherServiceServer will
+// result in compilation errors.
+type UnsafeWeatherServiceServer interface {
+	mustEmbedUnimplementedWeatherServiceServer()
+}
+
+func RegisterWeatherServiceServer(s grpc.ServiceRegistrar, srv WeatherServiceServer) {
+	s.RegisterService(&WeatherService_ServiceDesc, srv)
+}
+
+func _WeatherService_GetWeatherByCep_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWeatherByCepRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServiceServer).GetWeatherByCep(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WeatherService_GetWeatherByCep_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServiceServer).GetWeatherByCep(ctx, req.(*GetWeatherByCepRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WeatherService_BatchGetWeather_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetWeatherRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServiceServer).BatchGetWeather(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WeatherService_BatchGetWeather_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServiceServer).BatchGetWeather(ctx, req.(*BatchGetWeatherRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WeatherService_StreamWeather_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamWeatherRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WeatherServiceServer).StreamWeather(m, &weatherServiceStreamWeatherServer{ServerStream: stream})
+}
+
+type WeatherService_StreamWeatherServer interface {
+	Send(*StreamWeatherResponse) error
+	grpc.ServerStream
+}
+
+type weatherServiceStreamWeatherServer struct {
+	grpc.ServerStream
+}
+
+func (x *weatherServiceStreamWeatherServer) Send(m *StreamWeatherResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// WeatherService_ServiceDesc is the grpc.ServiceDesc for WeatherService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WeatherService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "weather.v1.WeatherService",
+	HandlerType: (*WeatherServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetWeatherByCep",
+			Handler:    _WeatherService_GetWeatherByCep_Handler,
+		},
+		{
+			MethodName: "BatchGetWeather",
+			Handler:    _WeatherService_BatchGetWeather_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamWeather",
+			Handler:       _WeatherService_StreamWeather_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "weather/v1/weather.proto",
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// APIKey describes a client key. Only the SHA-256 hash of the key is stored,
+// so configuration files and env vars never hold usable credentials.
+type APIKey struct {
+	Name         string   `yaml:"name"`
+	Hash         string   `yaml:"hash"`
+	DailyQuota   int64    `yaml:"daily_quota"`
+	MonthlyQuota int64    `yaml:"monthly_quota"`
+	Scopes       []string `yaml:"scopes"`
+	Disabled     bool     `yaml:"disabled"`
+}
+
+type keyFile struct {
+	Keys []APIKey `yaml:"keys"`
+}
+
+type KeyStore struct {
+	byHash map[string]APIKey
+}
+
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewKeyStore loads keys from the inline list and from the keys file (YAML
+// or JSON). Keys without explicit quotas or scopes get the configured
+// defaults.
+func NewKeyStore(cfg config.APIKeyConfig) (*KeyStore, error) {
+	var keys []APIKey
+
+	for _, entry := range strings.Split(cfg.Keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, hash, found := strings.Cut(entry, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid API key entry %q: expected name:sha256", name)
+		}
+		keys = append(keys, APIKey{Name: name, Hash: hash})
+	}
+
+	if cfg.File != "" {
+		content, err := os.ReadFile(cfg.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read API keys file: %w", err)
+		}
+		var file keyFile
+		if err := yaml.Unmarshal(content, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse API keys file: %w", err)
+		}
+		keys = append(keys, file.Keys...)
+	}
+
+	store := &KeyStore{byHash: make(map[string]APIKey, len(keys))}
+	for _, key := range keys {
+		key.Hash = strings.ToLower(strings.TrimSpace(key.Hash))
+		if len(key.Hash) != sha256.Size*2 {
+			return nil, fmt.Errorf("API key %q: hash must be a hex-encoded SHA-256", key.Name)
+		}
+		if _, err := hex.DecodeString(key.Hash); err != nil {
+			return nil, fmt.Errorf("API key %q: hash must be a hex-encoded SHA-256", key.Name)
+		}
+		if key.DailyQuota == 0 {
+			key.DailyQuota = cfg.DefaultDailyQuota
+		}
+		if key.MonthlyQuota == 0 {
+			key.MonthlyQuota = cfg.DefaultMonthlyQuota
+		}
+		if len(key.Scopes) == 0 {
+			key.Scopes = cfg.DefaultScopes
+		}
+		store.byHash[key.Hash] = key
+	}
+
+	return store, nil
+}
+
+func (s *KeyStore) Lookup(rawKey string) (APIKey, bool) {
+	key, ok := s.byHash[HashKey(rawKey)]
+	return key, ok
+}
+
+func (s *KeyStore) Len() int {
+	return len(s.byHash)
+}
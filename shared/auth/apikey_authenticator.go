@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	msgAPIKeyHint     = sharedErrors.NewMessage("auth.api_key_hint", "Provide an API key in the %s header or the %s query parameter")
+	msgInvalidAPIKey  = sharedErrors.NewMessage("auth.invalid_api_key", "Invalid API key")
+	msgAPIKeyNotValid = sharedErrors.NewMessage("auth.api_key_not_valid", "The provided API key is not valid")
+	msgQuotaExceeded  = sharedErrors.NewMessage("auth.quota_exceeded", "API key quota exceeded")
+	msgDailyQuota     = sharedErrors.NewMessage("auth.daily_quota", "Daily quota of %d requests exceeded")
+	msgMonthlyQuota   = sharedErrors.NewMessage("auth.monthly_quota", "Monthly quota of %d requests exceeded")
+)
+
+type APIKeyAuthenticator struct {
+	cfg    config.APIKeyConfig
+	keys   *KeyStore
+	usage  UsageStore
+	logger logger.Logger
+	now    func() time.Time
+}
+
+func NewAPIKeyAuthenticator(cfg *config.Config, usage UsageStore, log logger.Logger) (*APIKeyAuthenticator, error) {
+	keys, err := NewKeyStore(cfg.Auth.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Auth.APIKey.Enabled && keys.Len() == 0 {
+		return nil, fmt.Errorf("API key authentication is enabled but no keys are configured")
+	}
+
+	return &APIKeyAuthenticator{
+		cfg:    cfg.Auth.APIKey,
+		keys:   keys,
+		usage:  usage,
+		logger: log,
+		now:    time.Now,
+	}, nil
+}
+
+func (a *APIKeyAuthenticator) Enabled() bool {
+	return a.cfg.Enabled
+}
+
+func (a *APIKeyAuthenticator) Hint() sharedErrors.Text {
+	return msgAPIKeyHint.Format(a.cfg.Header, a.cfg.QueryParam)
+}
+
+// Authenticate rejects unknown keys with 401 and keys over their daily or
+// monthly quota with 403.
+func (a *APIKeyAuthenticator) Authenticate(c *gin.Context) (Principal, error) {
+	rawKey := c.GetHeader(a.cfg.Header)
+	if rawKey == "" && a.cfg.QueryParam != "" {
+		rawKey = c.Query(a.cfg.QueryParam)
+	}
+	if rawKey == "" {
+		return Principal{}, ErrNoCredentials
+	}
+
+	ctx := c.Request.Context()
+	log := a.logger.WithContext(ctx)
+
+	key, ok := a.keys.Lookup(rawKey)
+	if !ok || key.Disabled {
+		log.Warn("Rejected invalid or disabled API key")
+		return Principal{}, sharedErrors.NewUnauthorizedError(msgInvalidAPIKey.Format(), []sharedErrors.Text{msgAPIKeyNotValid.Format()})
+	}
+
+	usage, err := a.usage.Increment(ctx, key.Name, a.now())
+	if err != nil {
+		return Principal{}, fmt.Errorf("failed to record usage for API key %s: %w", key.Name, err)
+	}
+
+	if cause, exceeded := quotaExceeded(key, usage); exceeded {
+		log.Warn("API key %s exceeded its quota: %s", key.Name, cause)
+		return Principal{}, sharedErrors.NewForbiddenError(msgQuotaExceeded.Format(), []sharedErrors.Text{cause})
+	}
+
+	return Principal{Subject: key.Name, Method: MethodAPIKey, Scopes: key.Scopes}, nil
+}
+
+func quotaExceeded(key APIKey, usage Usage) (sharedErrors.Text, bool) {
+	if key.DailyQuota > 0 && usage.Daily > key.DailyQuota {
+		return msgDailyQuota.Format(key.DailyQuota), true
+	}
+	if key.MonthlyQuota > 0 && usage.Monthly > key.MonthlyQuota {
+		return msgMonthlyQuota.Format(key.MonthlyQuota), true
+	}
+	return sharedErrors.Text{}, false
+}
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	httpShared "github.com/gerps2/desafio-cloud-run/shared/http"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConfig(apiKey config.APIKeyConfig) *config.Config {
+	apiKey.Header = "X-API-Key"
+	apiKey.QueryParam = "api_key"
+	return &config.Config{
+		Log:  config.LogConfig{Level: "error"},
+		Auth: config.AuthConfig{PublicPaths: []string{"/health"}, APIKey: apiKey},
+	}
+}
+
+func newTestLogger(cfg *config.Config) logger.Logger {
+	return logger.NewWithWriter(io.Discard, cfg.Log, logger.NewLevel(cfg))
+}
+
+func newTestAuthenticator(t *testing.T, apiKey config.APIKeyConfig) *APIKeyAuthenticator {
+	t.Helper()
+
+	apiKey.Enabled = true
+	cfg := newTestConfig(apiKey)
+	authenticator, err := NewAPIKeyAuthenticator(cfg, NewMemoryUsageStore(), newTestLogger(cfg))
+	require.NoError(t, err)
+	return authenticator
+}
+
+func newTestRouter(guard *Guard, principal *Principal) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(guard.Middleware())
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/api/v1/weather/:cep", RequireScopes(ScopeWeatherRead), func(c *gin.Context) {
+		*principal, _ = PrincipalFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	authenticator := newTestAuthenticator(t, config.APIKeyConfig{
+		Keys:          "partner:" + HashKey("secret") + ",revoked:" + HashKey("revoked-secret") + ",metrics:" + HashKey("metrics-secret"),
+		DefaultScopes: []string{ScopeWeatherRead},
+	})
+	authenticator.keys.byHash[HashKey("revoked-secret")] = APIKey{Name: "revoked", Disabled: true}
+	authenticator.keys.byHash[HashKey("metrics-secret")] = APIKey{Name: "metrics", Scopes: []string{"metrics:read"}}
+	guard := NewGuard(newTestConfig(config.APIKeyConfig{}), authenticator.logger, authenticator)
+
+	tests := []struct {
+		name     string
+		path     string
+		header   string
+		status   int
+		message  string
+		expected string
+	}{
+		{name: "Public path without key", path: "/health", status: http.StatusOK},
+		{name: "Missing key", path: "/api/v1/weather/01001000", status: http.StatusUnauthorized, message: "Authentication is required"},
+		{name: "Unknown key", path: "/api/v1/weather/01001000", header: "wrong", status: http.StatusUnauthorized, message: "Invalid API key"},
+		{name: "Disabled key", path: "/api/v1/weather/01001000", header: "revoked-secret", status: http.StatusUnauthorized, message: "Invalid API key"},
+		{name: "Key without required scope", path: "/api/v1/weather/01001000", header: "metrics-secret", status: http.StatusForbidden, message: "Insufficient scope"},
+		{name: "Valid key in header", path: "/api/v1/weather/01001000", header: "secret", status: http.StatusOK, expected: "partner"},
+		{name: "Valid key in query", path: "/api/v1/weather/01001000?api_key=secret", status: http.StatusOK, expected: "partner"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var principal Principal
+			router := newTestRouter(guard, &principal)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("X-API-Key", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+			if tt.message != "" {
+				var response httpShared.APIResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.message, response.Message)
+			}
+			assert.Equal(t, tt.expected, principal.Subject)
+		})
+	}
+}
+
+func TestAPIKeyMiddlewareQuota(t *testing.T) {
+	authenticator := newTestAuthenticator(t, config.APIKeyConfig{
+		Keys:              "partner:" + HashKey("secret"),
+		DefaultDailyQuota: 2,
+		DefaultScopes:     []string{ScopeWeatherRead},
+	})
+	now := time.Date(2024, 3, 31, 23, 59, 0, 0, time.UTC)
+	authenticator.now = func() time.Time { return now }
+
+	var principal Principal
+	guard := NewGuard(newTestConfig(config.APIKeyConfig{}), authenticator.logger, authenticator)
+	router := newTestRouter(guard, &principal)
+	request := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/weather/01001000", nil)
+		req.Header.Set("X-API-Key", "secret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, request().Code)
+	assert.Equal(t, http.StatusOK, request().Code)
+
+	w := request()
+	require.Equal(t, http.StatusForbidden, w.Code)
+	var response httpShared.APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "API key quota exceeded", response.Message)
+	assert.Equal(t, []string{"Daily quota of 2 requests exceeded"}, response.Causes)
+
+	// The daily window resets at midnight UTC.
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, http.StatusOK, request().Code)
+}
+
+func TestNewKeyStoreFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	content := "keys:\n" +
+		"  - name: mobile\n" +
+		"    hash: " + HashKey("mobile-secret") + "\n" +
+		"    monthly_quota: 1000\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	store, err := NewKeyStore(config.APIKeyConfig{File: path, DefaultDailyQuota: 50})
+	require.NoError(t, err)
+
+	key, ok := store.Lookup("mobile-secret")
+	require.True(t, ok)
+	assert.Equal(t, "mobile", key.Name)
+	assert.Equal(t, int64(50), key.DailyQuota)
+	assert.Equal(t, int64(1000), key.MonthlyQuota)
+
+	_, ok = store.Lookup("other")
+	assert.False(t, ok)
+}
+
+func TestNewKeyStoreRejectsInvalidEntries(t *testing.T) {
+	_, err := NewKeyStore(config.APIKeyConfig{Keys: "partner"})
+	assert.Error(t, err)
+
+	_, err = NewKeyStore(config.APIKeyConfig{Keys: "partner:not-a-hash"})
+	assert.Error(t, err)
+}
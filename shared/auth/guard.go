@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+	httpShared "github.com/gerps2/desafio-cloud-run/shared/http"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrNoCredentials is returned by an Authenticator when the request carries
+// no credentials of its kind, so the next one can be tried.
+var ErrNoCredentials = errors.New("no credentials provided")
+
+var (
+	msgAuthenticationRequired = sharedErrors.NewMessage("auth.required", "Authentication is required")
+	msgAuthenticationFailed   = sharedErrors.NewMessage("auth.failed", "Unable to authenticate the request")
+)
+
+type Authenticator interface {
+	Enabled() bool
+	// Authenticate returns the caller, ErrNoCredentials, or an
+	// *errors.APIError describing why the credentials were rejected.
+	Authenticate(c *gin.Context) (Principal, error)
+	// Hint tells clients how to provide credentials for this method.
+	Hint() sharedErrors.Text
+}
+
+// challenger is implemented by authenticators that advertise a
+// WWW-Authenticate challenge on 401 responses.
+type challenger interface {
+	Challenge() string
+}
+
+// Guard runs the enabled authenticators in order and stores the first
+// successful principal in the request context.
+type Guard struct {
+	publicPaths    []string
+	authenticators []Authenticator
+	logger         logger.Logger
+}
+
+func NewGuard(cfg *config.Config, log logger.Logger, authenticators ...Authenticator) *Guard {
+	guard := &Guard{publicPaths: cfg.Auth.PublicPaths, logger: log}
+	for _, authenticator := range authenticators {
+		if authenticator.Enabled() {
+			guard.authenticators = append(guard.authenticators, authenticator)
+		}
+	}
+	return guard
+}
+
+// Enforced reports whether at least one authentication method is enabled.
+func (g *Guard) Enforced() bool {
+	return len(g.authenticators) > 0
+}
+
+func (g *Guard) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !g.Enforced() || IsPublicPath(c.Request.URL.Path, g.publicPaths) {
+			c.Next()
+			return
+		}
+
+		for _, authenticator := range g.authenticators {
+			principal, err := authenticator.Authenticate(c)
+			if errors.Is(err, ErrNoCredentials) {
+				continue
+			}
+			if err != nil {
+				g.reject(c, err)
+				return
+			}
+
+			ctx := ContextWithPrincipal(c.Request.Context(), principal)
+			ctx = logger.ContextWithFields(ctx, "principal", principal.Subject, "auth_method", principal.Method)
+			c.Request = c.Request.WithContext(ctx)
+			c.Next()
+			return
+		}
+
+		causes := make([]sharedErrors.Text, 0, len(g.authenticators))
+		var challenges []string
+		for _, authenticator := range g.authenticators {
+			causes = append(causes, authenticator.Hint())
+			if ch, ok := authenticator.(challenger); ok {
+				challenges = append(challenges, ch.Challenge())
+			}
+		}
+		if len(challenges) > 0 {
+			c.Header("WWW-Authenticate", strings.Join(challenges, ", "))
+		}
+		httpShared.RespondWithUnauthorized(c, msgAuthenticationRequired.Format(), causes)
+		c.Abort()
+	}
+}
+
+func (g *Guard) reject(c *gin.Context, err error) {
+	var apiErr *sharedErrors.APIError
+	if errors.As(err, &apiErr) {
+		httpShared.RespondWithAPIError(c, apiErr)
+	} else {
+		g.logger.WithContext(c.Request.Context()).Error("Authentication failed: %v", err)
+		httpShared.RespondWithInternalError(c, sharedErrors.Text{}, []sharedErrors.Text{msgAuthenticationFailed.Format()})
+	}
+	c.Abort()
+}
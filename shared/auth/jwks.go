@@ -0,0 +1,279 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minJWKSRefreshInterval bounds how often tokens with an unknown key ID can
+// force a JWKS download.
+const minJWKSRefreshInterval = 10 * time.Second
+
+var errKeysUnavailable = errors.New("verification keys unavailable")
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// keySet resolves verification keys by key ID. Keys fetched from a JWKS URL
+// are refreshed periodically and whenever a token references an unknown key,
+// which picks up rotations at the identity provider.
+type keySet struct {
+	url             string
+	client          *http.Client
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+func newRemoteKeySet(url string, client *http.Client, refreshInterval time.Duration) *keySet {
+	return &keySet{
+		url:             url,
+		client:          client,
+		refreshInterval: refreshInterval,
+		now:             time.Now,
+		keys:            map[string]crypto.PublicKey{},
+	}
+}
+
+// newFileKeySet loads a PEM encoded public key or certificate, or a JWKS
+// document, from path.
+func newFileKeySet(path string) (*keySet, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT key file: %w", err)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	if strings.HasPrefix(strings.TrimSpace(string(content)), "{") {
+		if keys, err = parseJWKS(content); err != nil {
+			return nil, err
+		}
+	} else {
+		key, err := parsePEMPublicKey(content)
+		if err != nil {
+			return nil, err
+		}
+		keys[""] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWT key file %s contains no usable keys", path)
+	}
+
+	return &keySet{keys: keys, now: time.Now}, nil
+}
+
+func (s *keySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.RLock()
+	key, ok := s.lookup(kid)
+	fresh := s.now().Sub(s.fetchedAt) < s.refreshInterval
+	s.mu.RUnlock()
+
+	if s.url == "" || (ok && fresh) {
+		if !ok {
+			return nil, fmt.Errorf("unknown key ID %q", kid)
+		}
+		return key, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Another request may have refreshed the set while this one waited.
+	key, ok = s.lookup(kid)
+	elapsed := s.now().Sub(s.fetchedAt)
+	if ok && elapsed < s.refreshInterval {
+		return key, nil
+	}
+	if !ok && elapsed < minJWKSRefreshInterval {
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+
+	if err := s.refresh(ctx); err != nil {
+		if ok {
+			// Keep verifying with the cached key while the provider is down.
+			return key, nil
+		}
+		return nil, fmt.Errorf("%w: %v", errKeysUnavailable, err)
+	}
+
+	if key, ok = s.lookup(kid); !ok {
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+	return key, nil
+}
+
+// lookup must be called with s.mu held. A set with a single key also serves
+// tokens without a key ID, and a key without ID (PEM) serves any token.
+func (s *keySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if key, ok := s.keys[kid]; ok {
+		return key, true
+	}
+	if len(s.keys) == 1 {
+		for id, key := range s.keys {
+			if kid == "" || id == "" {
+				return key, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// refresh must be called with s.mu held for writing.
+func (s *keySet) refresh(ctx context.Context) error {
+	s.fetchedAt = s.now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("JWKS endpoint returned status %d", resp.StatusCode)
+	}
+
+	var body json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys, err := parseJWKS(body)
+	if err != nil {
+		return err
+	}
+	s.keys = keys
+	return nil
+}
+
+func parseJWKS(content []byte) (map[string]crypto.PublicKey, error) {
+	var set jwkSet
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %q: %w", k.Kid, err)
+		}
+		if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+// publicKey returns nil for key types that cannot verify RS256/ES256 tokens.
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, nil
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil || len(raw) == 0 {
+		return nil, errors.New("invalid base64url value")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
+
+func parsePEMPublicKey(content []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("JWT key file is neither PEM nor JWKS")
+	}
+
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT certificate: %w", err)
+		}
+		return cert.PublicKey, nil
+	default:
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT public key: %w", err)
+		}
+		return key, nil
+	}
+}
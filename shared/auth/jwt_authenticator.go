@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var jwtSigningMethods = []string{"RS256", "ES256"}
+
+var (
+	msgBearerHint         = sharedErrors.NewMessage("auth.bearer_hint", "Provide a bearer token in the Authorization header")
+	msgInvalidBearerToken = sharedErrors.NewMessage("auth.invalid_bearer_token", "Invalid bearer token")
+	msgTokenNoSubject     = sharedErrors.NewMessage("auth.token_no_subject", "Token has no subject")
+	msgTokenExpired       = sharedErrors.NewMessage("auth.token_expired", "Token has expired")
+	msgTokenNotValidYet   = sharedErrors.NewMessage("auth.token_not_valid_yet", "Token is not valid yet")
+	msgTokenIssuer        = sharedErrors.NewMessage("auth.token_issuer", "Token issuer is not accepted")
+	msgTokenAudience      = sharedErrors.NewMessage("auth.token_audience", "Token audience is not accepted")
+	msgTokenMissingClaim  = sharedErrors.NewMessage("auth.token_missing_claim", "Token is missing a required claim")
+	msgTokenMalformed     = sharedErrors.NewMessage("auth.token_malformed", "Token is malformed")
+	msgTokenSignature     = sharedErrors.NewMessage("auth.token_signature", "Token signature could not be verified")
+)
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	// Scope is the OAuth 2.0 space separated form; some providers use an
+	// "scp" claim holding a string or an array instead.
+	Scope string      `json:"scope,omitempty"`
+	Scp   interface{} `json:"scp,omitempty"`
+}
+
+func (c tokenClaims) scopes() []string {
+	scopes := strings.Fields(c.Scope)
+	switch scp := c.Scp.(type) {
+	case string:
+		scopes = append(scopes, strings.Fields(scp)...)
+	case []interface{}:
+		for _, scope := range scp {
+			if s, ok := scope.(string); ok {
+				scopes = append(scopes, s)
+			}
+		}
+	}
+	return scopes
+}
+
+type JWTAuthenticator struct {
+	enabled bool
+	keys    *keySet
+	parser  *jwt.Parser
+	logger  logger.Logger
+}
+
+// NewJWTAuthenticator validates bearer tokens signed with RS256 or ES256
+// against the configured JWKS URL or key file. The JWKS is fetched lazily so
+// an unavailable identity provider does not prevent startup.
+func NewJWTAuthenticator(cfg *config.Config, client *http.Client, log logger.Logger) (*JWTAuthenticator, error) {
+	jwtCfg := cfg.Auth.JWT
+	authenticator := &JWTAuthenticator{enabled: jwtCfg.Enabled, logger: log}
+	if !jwtCfg.Enabled {
+		return authenticator, nil
+	}
+
+	if jwtCfg.Issuer == "" || jwtCfg.Audience == "" {
+		return nil, errors.New("JWT authentication requires AUTH_JWT_ISSUER and AUTH_JWT_AUDIENCE")
+	}
+
+	switch {
+	case jwtCfg.KeyFile != "":
+		keys, err := newFileKeySet(jwtCfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		authenticator.keys = keys
+	case jwtCfg.JWKSURL != "":
+		authenticator.keys = newRemoteKeySet(jwtCfg.JWKSURL, client, time.Duration(jwtCfg.JWKSRefreshSec)*time.Second)
+	default:
+		return nil, errors.New("JWT authentication requires AUTH_JWT_JWKS_URL or AUTH_JWT_KEY_FILE")
+	}
+
+	authenticator.parser = jwt.NewParser(
+		jwt.WithValidMethods(jwtSigningMethods),
+		jwt.WithIssuer(jwtCfg.Issuer),
+		jwt.WithAudience(jwtCfg.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Duration(jwtCfg.LeewaySec)*time.Second),
+	)
+
+	return authenticator, nil
+}
+
+func (a *JWTAuthenticator) Enabled() bool {
+	return a.enabled
+}
+
+func (a *JWTAuthenticator) Hint() sharedErrors.Text {
+	return msgBearerHint.Format()
+}
+
+func (a *JWTAuthenticator) Challenge() string {
+	return "Bearer"
+}
+
+func (a *JWTAuthenticator) Authenticate(c *gin.Context) (Principal, error) {
+	scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return Principal{}, ErrNoCredentials
+	}
+
+	ctx := c.Request.Context()
+	var claims tokenClaims
+	_, err := a.parser.ParseWithClaims(strings.TrimSpace(token), &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return a.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		if errors.Is(err, errKeysUnavailable) {
+			return Principal{}, fmt.Errorf("failed to load JWT verification keys: %w", err)
+		}
+		a.logger.WithContext(ctx).Warn("Rejected bearer token: %v", err)
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		return Principal{}, sharedErrors.NewUnauthorizedError(msgInvalidBearerToken.Format(), []sharedErrors.Text{tokenErrorCause(err).Format()})
+	}
+
+	if claims.Subject == "" {
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		return Principal{}, sharedErrors.NewUnauthorizedError(msgInvalidBearerToken.Format(), []sharedErrors.Text{msgTokenNoSubject.Format()})
+	}
+
+	return Principal{Subject: claims.Subject, Method: MethodJWT, Scopes: claims.scopes()}, nil
+}
+
+func tokenErrorCause(err error) sharedErrors.Message {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return msgTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return msgTokenNotValidYet
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return msgTokenIssuer
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return msgTokenAudience
+	case errors.Is(err, jwt.ErrTokenRequiredClaimMissing):
+		return msgTokenMissingClaim
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return msgTokenMalformed
+	default:
+		return msgTokenSignature
+	}
+}
@@ -0,0 +1,266 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	httpShared "github.com/gerps2/desafio-cloud-run/shared/http"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com"
+	testAudience = "weather-api"
+)
+
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.Signer
+}
+
+func newRSAKey(t *testing.T, kid string) signingKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return signingKey{kid: kid, method: jwt.SigningMethodRS256, private: key}
+}
+
+func newECKey(t *testing.T, kid string) signingKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return signingKey{kid: kid, method: jwt.SigningMethodES256, private: key}
+}
+
+func (k signingKey) jwk() map[string]string {
+	encode := func(n *big.Int) string { return base64.RawURLEncoding.EncodeToString(n.Bytes()) }
+
+	switch pub := k.private.Public().(type) {
+	case *rsa.PublicKey:
+		return map[string]string{"kty": "RSA", "kid": k.kid, "use": "sig", "n": encode(pub.N), "e": encode(big.NewInt(int64(pub.E)))}
+	case *ecdsa.PublicKey:
+		return map[string]string{"kty": "EC", "kid": k.kid, "crv": "P-256", "x": encode(pub.X), "y": encode(pub.Y)}
+	}
+	return nil
+}
+
+func (k signingKey) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = k.kid
+	signed, err := token.SignedString(k.private)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims(scope string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"sub":   "service-a",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+		"scope": scope,
+	}
+}
+
+type jwksServer struct {
+	*httptest.Server
+	keys     atomic.Value
+	requests atomic.Int32
+}
+
+func newJWKSServer(t *testing.T, keys ...signingKey) *jwksServer {
+	t.Helper()
+	server := &jwksServer{}
+	server.setKeys(keys...)
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.requests.Add(1)
+		_ = json.NewEncoder(w).Encode(server.keys.Load())
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (s *jwksServer) setKeys(keys ...signingKey) {
+	set := map[string][]map[string]string{"keys": {}}
+	for _, key := range keys {
+		set["keys"] = append(set["keys"], key.jwk())
+	}
+	s.keys.Store(set)
+}
+
+func newJWTGuard(t *testing.T, jwtCfg config.JWTConfig) (*Guard, *JWTAuthenticator) {
+	t.Helper()
+
+	jwtCfg.Enabled = true
+	jwtCfg.Issuer = testIssuer
+	jwtCfg.Audience = testAudience
+	cfg := newTestConfig(config.APIKeyConfig{})
+	cfg.Auth.JWT = jwtCfg
+	log := newTestLogger(cfg)
+
+	authenticator, err := NewJWTAuthenticator(cfg, http.DefaultClient, log)
+	require.NoError(t, err)
+	return NewGuard(cfg, log, authenticator), authenticator
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	rsaKey := newRSAKey(t, "rsa-1")
+	ecKey := newECKey(t, "ec-1")
+	server := newJWKSServer(t, rsaKey, ecKey)
+	guard, _ := newJWTGuard(t, config.JWTConfig{JWKSURL: server.URL, JWKSRefreshSec: 300})
+
+	expired := validClaims(ScopeWeatherRead)
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	wrongIssuer := validClaims(ScopeWeatherRead)
+	wrongIssuer["iss"] = "https://other.example.com"
+	wrongAudience := validClaims(ScopeWeatherRead)
+	wrongAudience["aud"] = "other-api"
+	scpArray := validClaims("")
+	scpArray["scp"] = []string{ScopeAdmin, ScopeWeatherRead}
+
+	tests := []struct {
+		name     string
+		header   string
+		status   int
+		cause    string
+		expected string
+	}{
+		{name: "RS256 token", header: "Bearer " + rsaKey.sign(t, validClaims("weather:read admin")), status: http.StatusOK, expected: "service-a"},
+		{name: "ES256 token", header: "Bearer " + ecKey.sign(t, validClaims(ScopeWeatherRead)), status: http.StatusOK, expected: "service-a"},
+		{name: "Scopes in scp array", header: "Bearer " + rsaKey.sign(t, scpArray), status: http.StatusOK, expected: "service-a"},
+		{name: "Missing scope", header: "Bearer " + rsaKey.sign(t, validClaims(ScopeAdmin)), status: http.StatusForbidden, cause: "Missing scope: weather:read"},
+		{name: "Missing token", status: http.StatusUnauthorized, cause: "Provide a bearer token in the Authorization header"},
+		{name: "Expired token", header: "Bearer " + rsaKey.sign(t, expired), status: http.StatusUnauthorized, cause: "Token has expired"},
+		{name: "Wrong issuer", header: "Bearer " + rsaKey.sign(t, wrongIssuer), status: http.StatusUnauthorized, cause: "Token issuer is not accepted"},
+		{name: "Wrong audience", header: "Bearer " + rsaKey.sign(t, wrongAudience), status: http.StatusUnauthorized, cause: "Token audience is not accepted"},
+		{name: "Untrusted key", header: "Bearer " + newRSAKey(t, "rsa-1").sign(t, validClaims(ScopeWeatherRead)), status: http.StatusUnauthorized, cause: "Token signature could not be verified"},
+		{name: "Malformed token", header: "Bearer not-a-token", status: http.StatusUnauthorized, cause: "Token is malformed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var principal Principal
+			router := newTestRouter(guard, &principal)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/weather/01001000", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.expected, principal.Subject)
+			if tt.cause != "" {
+				var response httpShared.APIResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, []string{tt.cause}, response.Causes)
+			}
+			if tt.status == http.StatusUnauthorized {
+				assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticatorKeyRotation(t *testing.T) {
+	oldKey := newRSAKey(t, "old")
+	newKey := newRSAKey(t, "new")
+	server := newJWKSServer(t, oldKey)
+	guard, authenticator := newJWTGuard(t, config.JWTConfig{JWKSURL: server.URL, JWKSRefreshSec: 300})
+
+	now := time.Now()
+	authenticator.keys.now = func() time.Time { return now }
+
+	var principal Principal
+	router := newTestRouter(guard, &principal)
+	request := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/weather/01001000", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request(oldKey.sign(t, validClaims(ScopeWeatherRead))))
+	assert.Equal(t, http.StatusOK, request(oldKey.sign(t, validClaims(ScopeWeatherRead))))
+	assert.Equal(t, int32(1), server.requests.Load(), "keys are cached")
+
+	// The provider rotates keys; an unknown kid triggers a refresh once the
+	// minimum refresh interval has passed.
+	server.setKeys(newKey)
+	assert.Equal(t, http.StatusUnauthorized, request(newKey.sign(t, validClaims(ScopeWeatherRead))))
+	assert.Equal(t, int32(1), server.requests.Load())
+
+	now = now.Add(minJWKSRefreshInterval)
+	assert.Equal(t, http.StatusOK, request(newKey.sign(t, validClaims(ScopeWeatherRead))))
+	assert.Equal(t, int32(2), server.requests.Load())
+}
+
+func TestJWTAuthenticatorKeyFile(t *testing.T) {
+	key := newECKey(t, "")
+	der, err := x509.MarshalPKIXPublicKey(key.private.Public())
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwt.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	guard, _ := newJWTGuard(t, config.JWTConfig{KeyFile: path})
+
+	var principal Principal
+	router := newTestRouter(guard, &principal)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/weather/01001000", nil)
+	req.Header.Set("Authorization", "Bearer "+key.sign(t, validClaims(ScopeWeatherRead)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, Principal{Subject: "service-a", Method: MethodJWT, Scopes: []string{ScopeWeatherRead}}, principal)
+}
+
+func TestJWTAuthenticatorJWKSUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	guard, _ := newJWTGuard(t, config.JWTConfig{JWKSURL: server.URL, JWKSRefreshSec: 300})
+
+	var principal Principal
+	router := newTestRouter(guard, &principal)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/weather/01001000", nil)
+	req.Header.Set("Authorization", "Bearer "+newRSAKey(t, "rsa-1").sign(t, validClaims(ScopeWeatherRead)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestNewJWTAuthenticatorRequiresKeys(t *testing.T) {
+	cfg := newTestConfig(config.APIKeyConfig{})
+	cfg.Auth.JWT = config.JWTConfig{Enabled: true, Issuer: testIssuer, Audience: testAudience}
+
+	_, err := NewJWTAuthenticator(cfg, http.DefaultClient, newTestLogger(cfg))
+	assert.Error(t, err)
+}
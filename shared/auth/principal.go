@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"strings"
+)
+
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string
+	Method  string
+	Scopes  []string
+}
+
+// MissingScopes returns the required scopes the principal was not granted.
+func (p Principal) MissingScopes(required []string) []string {
+	var missing []string
+	for _, scope := range required {
+		if !p.HasScope(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+func (p Principal) HasScope(scope string) bool {
+	for _, granted := range p.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+func ContextWithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+// IsPublicPath reports whether path matches one of the public paths, either
+// exactly or as a sub-path ("/docs" also matches "/docs/index.html").
+func IsPublicPath(path string, publicPaths []string) bool {
+	for _, public := range publicPaths {
+		if path == public || strings.HasPrefix(path, strings.TrimSuffix(public, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
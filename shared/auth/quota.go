@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type Usage struct {
+	Daily   int64
+	Monthly int64
+}
+
+// UsageStore counts requests per key and period. The in-memory store is local
+// to one instance; a shared implementation can be plugged in when quotas must
+// hold across Cloud Run instances.
+type UsageStore interface {
+	// Increment records one request for key at now and returns the usage
+	// including it.
+	Increment(ctx context.Context, key string, now time.Time) (Usage, error)
+	Get(ctx context.Context, key string, now time.Time) (Usage, error)
+}
+
+type usageCounter struct {
+	day     string
+	month   string
+	daily   int64
+	monthly int64
+}
+
+type MemoryUsageStore struct {
+	mu       sync.Mutex
+	counters map[string]*usageCounter
+}
+
+func NewMemoryUsageStore() *MemoryUsageStore {
+	return &MemoryUsageStore{counters: make(map[string]*usageCounter)}
+}
+
+func (s *MemoryUsageStore) Increment(_ context.Context, key string, now time.Time) (Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counter := s.counter(key, now)
+	counter.daily++
+	counter.monthly++
+	return Usage{Daily: counter.daily, Monthly: counter.monthly}, nil
+}
+
+func (s *MemoryUsageStore) Get(_ context.Context, key string, now time.Time) (Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counter := s.counter(key, now)
+	return Usage{Daily: counter.daily, Monthly: counter.monthly}, nil
+}
+
+// counter returns the counter for key, resetting the windows that rolled over.
+func (s *MemoryUsageStore) counter(key string, now time.Time) *usageCounter {
+	now = now.UTC()
+	day := now.Format("2006-01-02")
+	month := now.Format("2006-01")
+
+	counter, ok := s.counters[key]
+	if !ok {
+		counter = &usageCounter{day: day, month: month}
+		s.counters[key] = counter
+	}
+	if counter.day != day {
+		counter.day = day
+		counter.daily = 0
+	}
+	if counter.month != month {
+		counter.month = month
+		counter.monthly = 0
+	}
+	return counter
+}
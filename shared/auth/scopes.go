@@ -0,0 +1,60 @@
+package auth
+
+import (
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+	httpShared "github.com/gerps2/desafio-cloud-run/shared/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ScopeWeatherRead = "weather:read"
+	ScopeAdmin       = "admin"
+)
+
+var (
+	msgPrincipalRequired = sharedErrors.NewMessage("auth.principal_required", "This route is only served to authenticated principals")
+	msgInsufficientScope = sharedErrors.NewMessage("auth.insufficient_scope", "Insufficient scope")
+	msgMissingScope      = sharedErrors.NewMessage("auth.missing_scope", "Missing scope: %s")
+)
+
+// RequireScopes is declared per route and rejects principals lacking any of
+// the scopes with 403. Requests without a principal pass through: either
+// authentication is disabled or the route is public.
+func RequireScopes(scopes ...string) gin.HandlerFunc {
+	return requireScopes(scopes, false)
+}
+
+// RequirePrincipalScopes is RequireScopes for routes that must never be
+// anonymous, such as /admin: requests without a principal get 403 too, so the
+// route stays closed while authentication is disabled.
+func RequirePrincipalScopes(scopes ...string) gin.HandlerFunc {
+	return requireScopes(scopes, true)
+}
+
+func requireScopes(scopes []string, principalRequired bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := PrincipalFromContext(c.Request.Context())
+		if !ok && principalRequired {
+			httpShared.RespondWithForbidden(c, msgAuthenticationRequired.Format(), []sharedErrors.Text{msgPrincipalRequired.Format()})
+			c.Abort()
+			return
+		}
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if missing := principal.MissingScopes(scopes); len(missing) > 0 {
+			causes := make([]sharedErrors.Text, 0, len(missing))
+			for _, scope := range missing {
+				causes = append(causes, msgMissingScope.Format(scope))
+			}
+			httpShared.RespondWithForbidden(c, msgInsufficientScope.Format(), causes)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
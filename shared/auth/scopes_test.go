@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequirePrincipalScopes(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *Principal
+		status    int
+	}{
+		{name: "No principal", status: http.StatusForbidden},
+		{name: "Principal without scope", principal: &Principal{Subject: "partner", Scopes: []string{ScopeWeatherRead}}, status: http.StatusForbidden},
+		{name: "Principal with scope", principal: &Principal{Subject: "ops", Scopes: []string{ScopeAdmin}}, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.principal != nil {
+					c.Request = c.Request.WithContext(ContextWithPrincipal(c.Request.Context(), *tt.principal))
+				}
+			})
+			router.GET("/admin/budgets", RequirePrincipalScopes(ScopeAdmin), func(c *gin.Context) { c.Status(http.StatusOK) })
+			router.GET("/api/v1/weather/:cep", RequireScopes(ScopeAdmin), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/budgets", nil))
+			assert.Equal(t, tt.status, w.Code)
+
+			// RequireScopes keeps letting anonymous requests through.
+			if tt.principal == nil {
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/weather/01001000", nil))
+				assert.Equal(t, http.StatusOK, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"strings"
+)
+
+var defaults = map[string]interface{}{
+	"server.port":                                      "8080",
+	"server.host":                                      "localhost",
+	"server.read_timeout_sec":                          15,
+	"server.read_header_timeout_sec":                   10,
+	"server.write_timeout_sec":                         310,
+	"server.idle_timeout_sec":                          60,
+	"server.max_header_bytes":                          1 << 20,
+	"server.max_body_bytes":                            1 << 20,
+	"app.env":                                          EnvDevelopment,
+	"app.shutdown_grace_sec":                           30,
+	"app.drain_delay_sec":                              0,
+	"app.request_timeout_sec":                          300, // 5 minutos
+	"external_apis.viacep.base_url":                    "https://viacep.com.br/ws/",
+	"external_apis.viacep.timeout_sec":                 10,
+	"external_apis.weather.base_url":                   "https://api.weatherapi.com/v1/current.json?key=",
+	"external_apis.weather.timeout_sec":                10,
+	"external_apis.weather.budget.burst":               1,
+	"external_apis.weather.budget.max_wait_ms":         1000,
+	"external_apis.weather.budget.period":              "month",
+	"external_apis.outbound.max_idle_conns":            100,
+	"external_apis.outbound.max_idle_conns_per_host":   10,
+	"external_apis.outbound.idle_conn_timeout_sec":     90,
+	"external_apis.outbound.tls_handshake_timeout_sec": 10,
+	"external_apis.outbound.http2_enabled":             true,
+	"log.level":                                        "info",
+	"log.format":                                       "json",
+	"telemetry.service_name":                           "weather-api",
+	"telemetry.exporter":                               "none",
+	"telemetry.sample_ratio":                           1.0,
+	"health.cache_ttl_sec":                             30,
+	"health.probe_timeout_sec":                         5,
+	"auth.public_paths":                                "/health,/livez,/readyz,/metrics,/openapi.json,/docs",
+	"auth.api_key.enabled":                             false,
+	"auth.api_key.header":                              "X-API-Key",
+	"auth.api_key.query_param":                         "api_key",
+	"auth.api_key.default_scopes":                      "weather:read",
+	"auth.jwt.enabled":                                 false,
+	"auth.jwt.jwks_refresh_sec":                        300,
+	"auth.jwt.leeway_sec":                              30,
+	"rate_limit.enabled":                               false,
+	"rate_limit.requests":                              60,
+	"rate_limit.period_sec":                            60,
+	"rate_limit.key_by":                                "principal",
+	"graphql.enabled":                                  true,
+	"graphql.max_depth":                                5,
+	"graphql.max_complexity":                           200,
+	"graphql.max_list_size":                            20,
+	"stream.enabled":                                   true,
+	"stream.poll_interval_sec":                         60,
+	"stream.heartbeat_sec":                             15,
+	"stream.max_ceps":                                  10,
+	"http_cache.enabled":                               true,
+	"http_cache.public":                                false,
+	"http_cache.weather_max_age_sec":                   60,
+	"http_cache.address_max_age_sec":                   86400,
+	"compression.enabled":                              true,
+	"compression.min_size_bytes":                       1024,
+	"compression.encodings":                            []string{"zstd", "br", "gzip"},
+	"versioning.default":                               "v1",
+	"versioning.v1_deprecated_at":                      "2026-10-19T00:00:00Z",
+	"versioning.v1_sunset_at":                          "2027-10-19T00:00:00Z",
+	"grpc.enabled":                                     false,
+	"grpc.port":                                        "9090",
+	"grpc.max_batch_size":                              50,
+	"grpc.reflection_enabled":                          true,
+	"rate_limit.exempt_paths":                          "/health,/livez,/readyz,/metrics",
+}
+
+// envBinding maps a nested config key to its environment variables: the
+// nested form (EXTERNAL_APIS_WEATHER_API_KEY) followed by the legacy flat
+// names kept for existing deployments (WEATHER_API_KEY).
+type envBinding struct {
+	key    string
+	legacy []string
+}
+
+func (b envBinding) envNames() []string {
+	names := []string{strings.ToUpper(strings.ReplaceAll(b.key, ".", "_"))}
+	for _, name := range b.legacy {
+		if name != names[0] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func bind(key string, legacy ...string) envBinding {
+	return envBinding{key: key, legacy: legacy}
+}
+
+var envBindings = []envBinding{
+	bind("server.port", "PORT"),
+	bind("server.host", "HOST"),
+	bind("server.trusted_proxies", "SERVER_TRUSTED_PROXIES"),
+	bind("server.read_timeout_sec", "SERVER_READ_TIMEOUT_SEC"),
+	bind("server.read_header_timeout_sec", "SERVER_READ_HEADER_TIMEOUT_SEC"),
+	bind("server.write_timeout_sec", "SERVER_WRITE_TIMEOUT_SEC"),
+	bind("server.idle_timeout_sec", "SERVER_IDLE_TIMEOUT_SEC"),
+	bind("server.max_header_bytes", "SERVER_MAX_HEADER_BYTES"),
+	bind("server.max_body_bytes", "SERVER_MAX_BODY_BYTES"),
+	bind("server.max_body_bytes_routes", "SERVER_MAX_BODY_BYTES_ROUTES"),
+	bind("server.tls_cert_file", "SERVER_TLS_CERT_FILE"),
+	bind("server.tls_key_file", "SERVER_TLS_KEY_FILE"),
+	bind("server.h2c_enabled", "SERVER_H2C_ENABLED"),
+	bind("app.env", "ENV"),
+	bind("app.request_timeout_sec", "REQUEST_TIMEOUT_SEC"),
+	bind("app.shutdown_grace_sec", "SHUTDOWN_GRACE_SEC"),
+	bind("app.drain_delay_sec", "SHUTDOWN_DRAIN_DELAY_SEC"),
+	bind("external_apis.viacep.base_url", "VIACEP_BASE_URL"),
+	bind("external_apis.viacep.timeout_sec", "VIACEP_TIMEOUT_SEC"),
+	bind("external_apis.weather.base_url", "WEATHER_BASE_URL"),
+	bind("external_apis.weather.api_key", "WEATHER_API_KEY"),
+	bind("external_apis.weather.timeout_sec", "WEATHER_TIMEOUT_SEC"),
+	bind("external_apis.weather.budget.calls_per_second", "WEATHER_BUDGET_CALLS_PER_SECOND"),
+	bind("external_apis.weather.budget.burst", "WEATHER_BUDGET_BURST"),
+	bind("external_apis.weather.budget.max_wait_ms", "WEATHER_BUDGET_MAX_WAIT_MS"),
+	bind("external_apis.weather.budget.period_calls", "WEATHER_BUDGET_PERIOD_CALLS"),
+	bind("external_apis.weather.budget.period", "WEATHER_BUDGET_PERIOD"),
+	bind("external_apis.outbound.ca_file", "OUTBOUND_CA_FILE"),
+	bind("external_apis.outbound.client_cert_file", "OUTBOUND_CLIENT_CERT_FILE"),
+	bind("external_apis.outbound.client_key_file", "OUTBOUND_CLIENT_KEY_FILE"),
+	bind("external_apis.outbound.proxy_url", "OUTBOUND_PROXY_URL"),
+	bind("external_apis.outbound.max_idle_conns", "OUTBOUND_MAX_IDLE_CONNS"),
+	bind("external_apis.outbound.max_idle_conns_per_host", "OUTBOUND_MAX_IDLE_CONNS_PER_HOST"),
+	bind("external_apis.outbound.idle_conn_timeout_sec", "OUTBOUND_IDLE_CONN_TIMEOUT_SEC"),
+	bind("external_apis.outbound.tls_handshake_timeout_sec", "OUTBOUND_TLS_HANDSHAKE_TIMEOUT_SEC"),
+	bind("external_apis.outbound.http2_enabled", "OUTBOUND_HTTP2_ENABLED"),
+	bind("log.level", "LOG_LEVEL"),
+	bind("log.format", "LOG_FORMAT"),
+	bind("log.project_id", "LOG_PROJECT_ID", "GOOGLE_CLOUD_PROJECT"),
+	bind("telemetry.service_name", "OTEL_SERVICE_NAME"),
+	bind("telemetry.exporter", "TRACING_EXPORTER"),
+	bind("telemetry.otlp_endpoint", "OTEL_EXPORTER_OTLP_ENDPOINT"),
+	bind("telemetry.sample_ratio", "TRACING_SAMPLE_RATIO"),
+	bind("health.cache_ttl_sec", "HEALTH_CACHE_TTL_SEC"),
+	bind("health.probe_timeout_sec", "HEALTH_PROBE_TIMEOUT_SEC"),
+	bind("auth.public_paths", "AUTH_PUBLIC_PATHS"),
+	bind("auth.api_key.enabled", "AUTH_API_KEY_ENABLED"),
+	bind("auth.api_key.header", "AUTH_API_KEY_HEADER"),
+	bind("auth.api_key.query_param", "AUTH_API_KEY_QUERY_PARAM"),
+	bind("auth.api_key.keys", "AUTH_API_KEYS"),
+	bind("auth.api_key.file", "AUTH_API_KEYS_FILE"),
+	bind("auth.api_key.default_daily_quota", "AUTH_API_KEY_DAILY_QUOTA"),
+	bind("auth.api_key.default_monthly_quota", "AUTH_API_KEY_MONTHLY_QUOTA"),
+	bind("auth.api_key.default_scopes", "AUTH_API_KEY_DEFAULT_SCOPES"),
+	bind("auth.jwt.enabled", "AUTH_JWT_ENABLED"),
+	bind("auth.jwt.issuer", "AUTH_JWT_ISSUER"),
+	bind("auth.jwt.audience", "AUTH_JWT_AUDIENCE"),
+	bind("auth.jwt.jwks_url", "AUTH_JWT_JWKS_URL"),
+	bind("auth.jwt.key_file", "AUTH_JWT_KEY_FILE"),
+	bind("auth.jwt.jwks_refresh_sec", "AUTH_JWT_JWKS_REFRESH_SEC"),
+	bind("auth.jwt.leeway_sec", "AUTH_JWT_LEEWAY_SEC"),
+	bind("rate_limit.enabled", "RATE_LIMIT_ENABLED"),
+	bind("rate_limit.requests", "RATE_LIMIT_REQUESTS"),
+	bind("rate_limit.period_sec", "RATE_LIMIT_PERIOD_SEC"),
+	bind("rate_limit.burst", "RATE_LIMIT_BURST"),
+	bind("rate_limit.key_by", "RATE_LIMIT_KEY_BY"),
+	bind("rate_limit.routes", "RATE_LIMIT_ROUTES"),
+	bind("rate_limit.exempt_paths", "RATE_LIMIT_EXEMPT_PATHS"),
+	bind("graphql.enabled", "GRAPHQL_ENABLED"),
+	bind("graphql.max_depth", "GRAPHQL_MAX_DEPTH"),
+	bind("graphql.max_complexity", "GRAPHQL_MAX_COMPLEXITY"),
+	bind("graphql.max_list_size", "GRAPHQL_MAX_LIST_SIZE"),
+	bind("stream.enabled", "STREAM_ENABLED"),
+	bind("stream.poll_interval_sec", "STREAM_POLL_INTERVAL_SEC"),
+	bind("stream.heartbeat_sec", "STREAM_HEARTBEAT_SEC"),
+	bind("stream.max_ceps", "STREAM_MAX_CEPS"),
+	bind("http_cache.enabled", "HTTP_CACHE_ENABLED"),
+	bind("http_cache.public", "HTTP_CACHE_PUBLIC"),
+	bind("http_cache.weather_max_age_sec", "HTTP_CACHE_WEATHER_MAX_AGE_SEC"),
+	bind("http_cache.address_max_age_sec", "HTTP_CACHE_ADDRESS_MAX_AGE_SEC"),
+	bind("compression.enabled", "COMPRESSION_ENABLED"),
+	bind("compression.min_size_bytes", "COMPRESSION_MIN_SIZE_BYTES"),
+	bind("compression.encodings", "COMPRESSION_ENCODINGS"),
+	bind("compression.routes", "COMPRESSION_ROUTES"),
+	bind("versioning.default", "VERSIONING_DEFAULT"),
+	bind("versioning.v1_deprecated_at", "VERSIONING_V1_DEPRECATED_AT"),
+	bind("versioning.v1_sunset_at", "VERSIONING_V1_SUNSET_AT"),
+	bind("grpc.enabled", "GRPC_ENABLED"),
+	bind("grpc.port", "GRPC_PORT"),
+	bind("grpc.max_batch_size", "GRPC_MAX_BATCH_SIZE"),
+	bind("grpc.reflection_enabled", "GRPC_REFLECTION_ENABLED"),
+}
@@ -1,8 +1,13 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 
 	"github.com/spf13/viper"
 )
@@ -11,64 +16,381 @@ type Config struct {
 	Server       ServerConfig       `mapstructure:"server"`
 	App          AppConfig          `mapstructure:"app"`
 	ExternalAPIs ExternalAPIsConfig `mapstructure:"external_apis"`
+	Log          LogConfig          `mapstructure:"log"`
+	Telemetry    TelemetryConfig    `mapstructure:"telemetry"`
+	Health       HealthConfig       `mapstructure:"health"`
+	Auth         AuthConfig         `mapstructure:"auth"`
+	RateLimit    RateLimitConfig    `mapstructure:"rate_limit"`
+	GRPC         GRPCConfig         `mapstructure:"grpc"`
+	GraphQL      GraphQLConfig      `mapstructure:"graphql"`
+	Stream       StreamConfig       `mapstructure:"stream"`
+	HTTPCache    HTTPCacheConfig    `mapstructure:"http_cache"`
+	Compression  CompressionConfig  `mapstructure:"compression"`
+	Versioning   VersioningConfig   `mapstructure:"versioning"`
 }
 
 type ServerConfig struct {
 	Port string `mapstructure:"port"`
 	Host string `mapstructure:"host"`
+	// TrustedProxies lists the proxy IPs/CIDRs whose X-Forwarded-For and
+	// X-Real-IP headers are honoured when resolving the client IP.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+	// Timeouts of the HTTP server; zero disables the corresponding timeout.
+	// WriteTimeoutSec must exceed REQUEST_TIMEOUT_SEC or slow responses are
+	// cut off before the request timeout answers them.
+	ReadTimeoutSec       int   `mapstructure:"read_timeout_sec"`
+	ReadHeaderTimeoutSec int   `mapstructure:"read_header_timeout_sec"`
+	WriteTimeoutSec      int   `mapstructure:"write_timeout_sec"`
+	IdleTimeoutSec       int   `mapstructure:"idle_timeout_sec"`
+	MaxHeaderBytes       int   `mapstructure:"max_header_bytes"`
+	MaxBodyBytes         int64 `mapstructure:"max_body_bytes"`
+	// MaxBodyBytesRoutes overrides MaxBodyBytes per route as "route=bytes"
+	// pairs. Gzip request bodies are held to the limit once inflated too.
+	MaxBodyBytesRoutes string `mapstructure:"max_body_bytes_routes"`
+	// TLSCertFile and TLSKeyFile serve HTTPS (with HTTP/2) when both are set.
+	TLSCertFile string `mapstructure:"tls_cert_file"`
+	TLSKeyFile  string `mapstructure:"tls_key_file"`
+	// H2CEnabled accepts cleartext HTTP/2, as sent by Cloud Run when
+	// end-to-end HTTP/2 is enabled.
+	H2CEnabled bool `mapstructure:"h2c_enabled"`
 }
 
 type AppConfig struct {
 	Env               string `mapstructure:"env"`
 	RequestTimeoutSec int    `mapstructure:"request_timeout_sec"`
+	// ShutdownGraceSec bounds how long shutdown waits for in-flight requests
+	// and background work; DrainDelaySec is how long readiness fails before
+	// the server stops accepting connections.
+	ShutdownGraceSec int `mapstructure:"shutdown_grace_sec"`
+	DrainDelaySec    int `mapstructure:"drain_delay_sec"`
+}
+
+type LogConfig struct {
+	Level     string `mapstructure:"level"`
+	Format    string `mapstructure:"format"`
+	ProjectID string `mapstructure:"project_id"`
+}
+
+type TelemetryConfig struct {
+	ServiceName  string  `mapstructure:"service_name"`
+	Exporter     string  `mapstructure:"exporter"`
+	OTLPEndpoint string  `mapstructure:"otlp_endpoint"`
+	SampleRatio  float64 `mapstructure:"sample_ratio"`
+}
+
+type HealthConfig struct {
+	CacheTTLSec     int `mapstructure:"cache_ttl_sec"`
+	ProbeTimeoutSec int `mapstructure:"probe_timeout_sec"`
+}
+
+type AuthConfig struct {
+	// PublicPaths are served without credentials.
+	PublicPaths []string     `mapstructure:"public_paths"`
+	APIKey      APIKeyConfig `mapstructure:"api_key"`
+	JWT         JWTConfig    `mapstructure:"jwt"`
+}
+
+type APIKeyConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	Header     string `mapstructure:"header"`
+	QueryParam string `mapstructure:"query_param"`
+	// Keys lists "name:sha256hex" pairs separated by commas.
+	Keys                string `mapstructure:"keys"`
+	File                string `mapstructure:"file"`
+	DefaultDailyQuota   int64  `mapstructure:"default_daily_quota"`
+	DefaultMonthlyQuota int64  `mapstructure:"default_monthly_quota"`
+	// DefaultScopes are granted to keys that do not list their own.
+	DefaultScopes []string `mapstructure:"default_scopes"`
+}
+
+type JWTConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Issuer   string `mapstructure:"issuer"`
+	Audience string `mapstructure:"audience"`
+	// JWKSURL or KeyFile (PEM public key or JWKS document) supplies the
+	// verification keys.
+	JWKSURL        string `mapstructure:"jwks_url"`
+	KeyFile        string `mapstructure:"key_file"`
+	JWKSRefreshSec int    `mapstructure:"jwks_refresh_sec"`
+	LeewaySec      int    `mapstructure:"leeway_sec"`
+}
+
+type RateLimitConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Requests per PeriodSec, refilled continuously; Burst is the bucket
+	// capacity and defaults to Requests.
+	Requests  int `mapstructure:"requests"`
+	PeriodSec int `mapstructure:"period_sec"`
+	Burst     int `mapstructure:"burst"`
+	// KeyBy selects the bucket: principal (limited by ip before authentication
+	// too), ip or route.
+	KeyBy string `mapstructure:"key_by"`
+	// Routes overrides Requests per route as "route=requests" pairs.
+	Routes      string   `mapstructure:"routes"`
+	ExemptPaths []string `mapstructure:"exempt_paths"`
+}
+
+// GRPCConfig configures the internal gRPC API, served on its own port and
+// without authentication.
+type GRPCConfig struct {
+	Enabled           bool   `mapstructure:"enabled"`
+	Port              string `mapstructure:"port"`
+	MaxBatchSize      int    `mapstructure:"max_batch_size"`
+	ReflectionEnabled bool   `mapstructure:"reflection_enabled"`
+}
+
+// GraphQLConfig bounds the cost of a /graphql request before it runs.
+// Complexity counts one per selected field, multiplied by the size of list
+// arguments.
+type GraphQLConfig struct {
+	Enabled       bool `mapstructure:"enabled"`
+	MaxDepth      int  `mapstructure:"max_depth"`
+	MaxComplexity int  `mapstructure:"max_complexity"`
+	MaxListSize   int  `mapstructure:"max_list_size"`
+}
+
+// StreamConfig configures the live weather endpoints (SSE and WebSocket).
+// Each subscribed city is polled once per interval whatever its number of
+// subscribers.
+type StreamConfig struct {
+	Enabled         bool `mapstructure:"enabled"`
+	PollIntervalSec int  `mapstructure:"poll_interval_sec"`
+	HeartbeatSec    int  `mapstructure:"heartbeat_sec"`
+	MaxCeps         int  `mapstructure:"max_ceps"`
+}
+
+// HTTPCacheConfig sets the Cache-Control max-age of the weather and address
+// responses. Public lets shared caches (CDNs) store them; leave it off when
+// they must not be served without authentication.
+type HTTPCacheConfig struct {
+	Enabled          bool `mapstructure:"enabled"`
+	Public           bool `mapstructure:"public"`
+	WeatherMaxAgeSec int  `mapstructure:"weather_max_age_sec"`
+	AddressMaxAgeSec int  `mapstructure:"address_max_age_sec"`
+}
+
+// CompressionConfig configures response compression. Encodings lists the
+// codings offered (gzip, br, zstd) in order of preference for ties in
+// Accept-Encoding; bodies under MinSizeBytes are sent as is.
+type CompressionConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	MinSizeBytes int      `mapstructure:"min_size_bytes"`
+	Encodings    []string `mapstructure:"encodings"`
+	// Routes overrides MinSizeBytes per route as "route=bytes" pairs;
+	// "route=off" disables compression for the route.
+	Routes string `mapstructure:"routes"`
+}
+
+// VersioningConfig sets the version served on unversioned routes when the
+// request sends no API-Version header, and the RFC 3339 dates announced in the
+// Deprecation and Sunset headers of v1; an empty date leaves its header out.
+type VersioningConfig struct {
+	Default        string `mapstructure:"default"`
+	V1DeprecatedAt string `mapstructure:"v1_deprecated_at"`
+	V1SunsetAt     string `mapstructure:"v1_sunset_at"`
 }
 
 type ExternalAPIsConfig struct {
-	ViaCep  ViaCepConfig  `mapstructure:"viacep"`
-	Weather WeatherConfig `mapstructure:"weather"`
+	ViaCep   ViaCepConfig   `mapstructure:"viacep"`
+	Weather  WeatherConfig  `mapstructure:"weather"`
+	Outbound OutboundConfig `mapstructure:"outbound"`
 }
 
 type ViaCepConfig struct {
-	BaseURL string `mapstructure:"base_url"`
+	BaseURL    string `mapstructure:"base_url"`
+	TimeoutSec int    `mapstructure:"timeout_sec"`
 }
 
 type WeatherConfig struct {
-	BaseURL string `mapstructure:"base_url"`
-	APIKey  string `mapstructure:"api_key"`
+	BaseURL    string              `mapstructure:"base_url"`
+	APIKey     string              `mapstructure:"api_key"`
+	TimeoutSec int                 `mapstructure:"timeout_sec"`
+	Budget     WeatherBudgetConfig `mapstructure:"budget"`
 }
 
-func Load() *Config {
-	viper.AutomaticEnv()
+// WeatherBudgetConfig caps the calls made to WeatherAPI to stay within the
+// plan. Zero values disable the corresponding limit.
+type WeatherBudgetConfig struct {
+	CallsPerSecond float64 `mapstructure:"calls_per_second"`
+	Burst          int     `mapstructure:"burst"`
+	// MaxWaitMs is how long a call may queue for a per-second slot before it
+	// is rejected.
+	MaxWaitMs   int    `mapstructure:"max_wait_ms"`
+	PeriodCalls int64  `mapstructure:"period_calls"`
+	Period      string `mapstructure:"period"`
+}
 
-	viper.SetDefault("PORT", "8080")
-	viper.SetDefault("HOST", "localhost")
-	viper.SetDefault("ENV", "development")
-	viper.SetDefault("REQUEST_TIMEOUT_SEC", 300) // 5 minutos
-	viper.SetDefault("VIACEP_BASE_URL", "https://viacep.com.br/ws/")
-	viper.SetDefault("WEATHER_BASE_URL", "https://api.weatherapi.com/v1/current.json?key=")
-	viper.SetDefault("WEATHER_API_KEY", "aa7fa70309da4bc39cd203930251108")
+// OutboundConfig configures the HTTP transport shared by the upstream clients.
+type OutboundConfig struct {
+	CAFile                 string `mapstructure:"ca_file"`
+	ClientCertFile         string `mapstructure:"client_cert_file"`
+	ClientKeyFile          string `mapstructure:"client_key_file"`
+	ProxyURL               string `mapstructure:"proxy_url"`
+	MaxIdleConns           int    `mapstructure:"max_idle_conns"`
+	MaxIdleConnsPerHost    int    `mapstructure:"max_idle_conns_per_host"`
+	IdleConnTimeoutSec     int    `mapstructure:"idle_conn_timeout_sec"`
+	TLSHandshakeTimeoutSec int    `mapstructure:"tls_handshake_timeout_sec"`
+	HTTP2Enabled           bool   `mapstructure:"http2_enabled"`
+}
 
-	if _, err := os.Stat(".env"); err == nil {
-		viper.SetConfigFile(".env")
-		viper.SetConfigType("env")
+// Options selects the configuration file. When File is empty the path is
+// taken from CONFIG_FILE; without either, only env vars and defaults apply.
+type Options struct {
+	File string
+}
 
-		if err := viper.ReadInConfig(); err != nil {
-			log.Printf("Warning: Found .env file but could not read it: %v", err)
-		} else {
-			log.Println("Configuration loaded from .env file")
+func (o Options) file() string {
+	if o.File != "" {
+		return o.File
+	}
+	return os.Getenv("CONFIG_FILE")
+}
+
+// Files returns the config file and its overlay for env, or nil when no file
+// is configured. The overlay is listed even if it does not exist yet.
+func (o Options) Files(env string) []string {
+	file := o.file()
+	if file == "" {
+		return nil
+	}
+	return []string{file, overlayPath(file, env)}
+}
+
+// Load merges, from lowest to highest precedence, the defaults, the config
+// file, its overlay for the current environment (config.<env>.yaml next to
+// it), the .env file and the environment. The result is validated so
+// misconfiguration fails at startup instead of at request time.
+func Load(opts Options) (*Config, error) {
+	if err := loadDotEnv(".env"); err != nil {
+		return nil, err
+	}
+
+	v := viper.New()
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+	for _, binding := range envBindings {
+		if err := v.BindEnv(append([]string{binding.key}, binding.envNames()...)...); err != nil {
+			return nil, err
+		}
+	}
+
+	if file := opts.file(); file != "" {
+		if err := readConfigFile(v, file); err != nil {
+			return nil, err
 		}
-	} else {
-		log.Println("No .env file found, using environment variables and defaults")
 	}
 
 	var config Config
-	config.Server.Port = viper.GetString("PORT")
-	config.Server.Host = viper.GetString("HOST")
-	config.App.Env = viper.GetString("ENV")
-	config.App.RequestTimeoutSec = viper.GetInt("REQUEST_TIMEOUT_SEC")
-	config.ExternalAPIs.ViaCep.BaseURL = viper.GetString("VIACEP_BASE_URL")
-	config.ExternalAPIs.Weather.BaseURL = viper.GetString("WEATHER_BASE_URL")
-	config.ExternalAPIs.Weather.APIKey = viper.GetString("WEATHER_API_KEY")
-
-	return &config
+	if err := v.UnmarshalExact(&config, viper.DecodeHook(stringToListHook)); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	if config.ExternalAPIs.Weather.APIKey == "" {
+		log.Println("Warning: WEATHER_API_KEY is not set, weather lookups will fail")
+	}
+
+	return &config, nil
+}
+
+func readConfigFile(v *viper.Viper, file string) error {
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", file, err)
+	}
+	log.Printf("Configuration loaded from %s", file)
+
+	overlay := overlayPath(file, v.GetString("app.env"))
+	if _, err := os.Stat(overlay); err != nil {
+		return nil
+	}
+	v.SetConfigFile(overlay)
+	if err := v.MergeInConfig(); err != nil {
+		return fmt.Errorf("failed to read config overlay %s: %w", overlay, err)
+	}
+	log.Printf("Configuration overlay loaded from %s", overlay)
+	return nil
+}
+
+// overlayPath returns the per-environment file next to the base one, e.g.
+// configs/config.production.yaml for configs/config.yaml.
+func overlayPath(file, env string) string {
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + "." + env + ext
+}
+
+// dotEnvValues records the variables loadDotEnv exported and their values,
+// so a reload overwrites them with the file's current values and unsets the
+// ones removed from it. Variables set by anything else are never touched.
+var (
+	dotEnvMu     sync.Mutex
+	dotEnvValues = map[string]string{}
+)
+
+// loadDotEnv exports the variables of a .env file that are not already set,
+// so real environment variables keep precedence. Variables it exported on a
+// previous load follow the file.
+func loadDotEnv(path string) error {
+	dotEnvMu.Lock()
+	defer dotEnvMu.Unlock()
+
+	settings := map[string]interface{}{}
+	if _, err := os.Stat(path); err != nil {
+		log.Println("No .env file found, using environment variables and defaults")
+	} else {
+		dotenv := viper.New()
+		dotenv.SetConfigFile(path)
+		dotenv.SetConfigType("env")
+		if err := dotenv.ReadInConfig(); err != nil {
+			return fmt.Errorf("failed to read .env file: %w", err)
+		}
+		settings = dotenv.AllSettings()
+		log.Println("Configuration loaded from .env file")
+	}
+
+	exported := make(map[string]string, len(settings))
+	for key, value := range settings {
+		name := strings.ToUpper(key)
+		if _, set := os.LookupEnv(name); set && !ownedDotEnv(name) {
+			continue
+		}
+		exported[name] = fmt.Sprint(value)
+		_ = os.Setenv(name, exported[name])
+	}
+	for name := range dotEnvValues {
+		if _, kept := exported[name]; !kept && ownedDotEnv(name) {
+			_ = os.Unsetenv(name)
+		}
+	}
+	dotEnvValues = exported
+	return nil
+}
+
+// ownedDotEnv reports whether name still holds the value loadDotEnv exported.
+// It must be called with dotEnvMu held.
+func ownedDotEnv(name string) bool {
+	value, ok := dotEnvValues[name]
+	current, set := os.LookupEnv(name)
+	return ok && set && current == value
+}
+
+// stringToListHook decodes comma-separated env values into string slices;
+// config files can use native lists.
+func stringToListHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf([]string{}) {
+		return data, nil
+	}
+	return splitList(data.(string)), nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
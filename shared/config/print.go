@@ -0,0 +1,52 @@
+package config
+
+import (
+	"io"
+	"net/url"
+
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[REDACTED]"
+
+// Redacted returns a copy with credentials replaced, safe to print or log.
+func (c Config) Redacted() Config {
+	redact := func(value *string) {
+		if *value != "" {
+			*value = redacted
+		}
+	}
+	redact(&c.ExternalAPIs.Weather.APIKey)
+	redact(&c.Auth.APIKey.Keys)
+	c.ExternalAPIs.Outbound.ProxyURL = redactURL(c.ExternalAPIs.Outbound.ProxyURL)
+	c.Telemetry.OTLPEndpoint = redactURL(c.Telemetry.OTLPEndpoint)
+	return c
+}
+
+// WriteYAML writes the redacted configuration keyed like the config file.
+func (c Config) WriteYAML(w io.Writer) error {
+	var settings map[string]interface{}
+	if err := mapstructure.Decode(c.Redacted(), &settings); err != nil {
+		return err
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(settings); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// redactURL hides the password of URLs carrying user info.
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	}
+	return u.String()
+}
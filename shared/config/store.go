@@ -0,0 +1,117 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// reloadable lists the keys a running process picks up on Reload. Everything
+// else is read once while wiring the app and needs a restart.
+var reloadable = map[string]bool{
+	"log.level":                      true,
+	"health.cache_ttl_sec":           true,
+	"rate_limit.enabled":             true,
+	"rate_limit.requests":            true,
+	"rate_limit.period_sec":          true,
+	"rate_limit.burst":               true,
+	"rate_limit.key_by":              true,
+	"rate_limit.routes":              true,
+	"rate_limit.exempt_paths":        true,
+	"http_cache.enabled":             true,
+	"http_cache.public":              true,
+	"http_cache.weather_max_age_sec": true,
+	"http_cache.address_max_age_sec": true,
+}
+
+// ReloadResult reports the keys applied by a reload and the changed keys that
+// were ignored because they are not reloadable.
+type ReloadResult struct {
+	Applied []string
+	Ignored []string
+}
+
+// Store holds the current configuration snapshot. Snapshots are never
+// mutated; Reload swaps in a new one and notifies the subscribers.
+type Store struct {
+	opts    Options
+	current atomic.Pointer[Config]
+
+	mu          sync.Mutex
+	subscribers []func(*Config)
+}
+
+func NewStore(cfg *Config, opts Options) *Store {
+	store := &Store{opts: opts}
+	store.current.Store(cfg)
+	return store
+}
+
+func (s *Store) Current() *Config {
+	return s.current.Load()
+}
+
+// Options returns the options the configuration was loaded with, so watchers
+// know which files to observe.
+func (s *Store) Options() Options {
+	return s.opts
+}
+
+// OnChange registers fn to be called with every snapshot that changes a
+// reloadable key.
+func (s *Store) OnChange(fn func(*Config)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, fn)
+}
+
+// Reload reads every source again. An invalid configuration is rejected and
+// the current snapshot is kept.
+func (s *Store) Reload() (ReloadResult, error) {
+	loaded, err := Load(s.opts)
+	if err != nil {
+		return ReloadResult{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := *s.Current()
+	var result ReloadResult
+	merge(reflect.ValueOf(&next).Elem(), reflect.ValueOf(loaded).Elem(), "", &result)
+	if len(result.Applied) == 0 {
+		return result, nil
+	}
+
+	s.current.Store(&next)
+	for _, fn := range s.subscribers {
+		fn(&next)
+	}
+	return result, nil
+}
+
+// merge copies the reloadable leaves of src into dst, recording every leaf
+// that differs under its config key.
+func merge(dst, src reflect.Value, prefix string, result *ReloadResult) {
+	for i := 0; i < dst.NumField(); i++ {
+		key := dst.Type().Field(i).Tag.Get("mapstructure")
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		field := dst.Field(i)
+		if field.Kind() == reflect.Struct {
+			merge(field, src.Field(i), key, result)
+			continue
+		}
+		if reflect.DeepEqual(field.Interface(), src.Field(i).Interface()) {
+			continue
+		}
+		if !reloadable[key] {
+			result.Ignored = append(result.Ignored, key)
+			continue
+		}
+		field.Set(src.Field(i))
+		result.Applied = append(result.Applied, key)
+	}
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvTest        = "test"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// Validate checks every field and returns all problems at once, each prefixed
+// with the environment variable that sets it.
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.check(oneOf(c.App.Env, EnvDevelopment, EnvTest, EnvStaging, EnvProduction), "ENV", "must be one of development, test, staging or production, got %q", c.App.Env)
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		v.add("PORT", "must be a number between 1 and 65535, got %q", c.Server.Port)
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		v.check(cidrErr == nil || net.ParseIP(proxy) != nil, "SERVER_TRUSTED_PROXIES", "%q is not an IP address or CIDR", proxy)
+	}
+	server := c.Server
+	v.check(server.ReadTimeoutSec >= 0, "SERVER_READ_TIMEOUT_SEC", "must not be negative, got %d", server.ReadTimeoutSec)
+	v.check(server.ReadHeaderTimeoutSec >= 0, "SERVER_READ_HEADER_TIMEOUT_SEC", "must not be negative, got %d", server.ReadHeaderTimeoutSec)
+	v.check(server.WriteTimeoutSec >= 0, "SERVER_WRITE_TIMEOUT_SEC", "must not be negative, got %d", server.WriteTimeoutSec)
+	v.check(server.IdleTimeoutSec >= 0, "SERVER_IDLE_TIMEOUT_SEC", "must not be negative, got %d", server.IdleTimeoutSec)
+	v.check(server.MaxHeaderBytes >= 0, "SERVER_MAX_HEADER_BYTES", "must not be negative, got %d", server.MaxHeaderBytes)
+	v.check(server.MaxBodyBytes >= 0, "SERVER_MAX_BODY_BYTES", "must not be negative, got %d", server.MaxBodyBytes)
+	for _, entry := range splitList(server.MaxBodyBytesRoutes) {
+		idx := strings.LastIndex(entry, "=")
+		limit, err := strconv.ParseInt(entry[idx+1:], 10, 64)
+		v.check(idx > 0 && err == nil && limit >= 0, "SERVER_MAX_BODY_BYTES_ROUTES", "entry %q must be route=bytes", entry)
+	}
+	v.check((server.TLSCertFile == "") == (server.TLSKeyFile == ""), "SERVER_TLS_CERT_FILE", "and SERVER_TLS_KEY_FILE must be set together")
+	v.check(!server.H2CEnabled || server.TLSCertFile == "", "SERVER_H2C_ENABLED", "cannot be combined with TLS, which negotiates HTTP/2 itself")
+	v.check(c.App.RequestTimeoutSec > 0, "REQUEST_TIMEOUT_SEC", "must be positive, got %d", c.App.RequestTimeoutSec)
+	v.check(c.App.ShutdownGraceSec > 0, "SHUTDOWN_GRACE_SEC", "must be positive, got %d", c.App.ShutdownGraceSec)
+	v.check(c.App.DrainDelaySec >= 0, "SHUTDOWN_DRAIN_DELAY_SEC", "must not be negative, got %d", c.App.DrainDelaySec)
+
+	v.httpURL("VIACEP_BASE_URL", c.ExternalAPIs.ViaCep.BaseURL)
+	v.check(c.ExternalAPIs.ViaCep.TimeoutSec > 0, "VIACEP_TIMEOUT_SEC", "must be positive, got %d", c.ExternalAPIs.ViaCep.TimeoutSec)
+	v.httpURL("WEATHER_BASE_URL", c.ExternalAPIs.Weather.BaseURL)
+	v.check(c.ExternalAPIs.Weather.APIKey != "" || c.App.Env == EnvDevelopment, "WEATHER_API_KEY", "is required outside development")
+	v.check(c.ExternalAPIs.Weather.TimeoutSec > 0, "WEATHER_TIMEOUT_SEC", "must be positive, got %d", c.ExternalAPIs.Weather.TimeoutSec)
+
+	budget := c.ExternalAPIs.Weather.Budget
+	v.check(budget.CallsPerSecond >= 0, "WEATHER_BUDGET_CALLS_PER_SECOND", "must not be negative, got %g", budget.CallsPerSecond)
+	v.check(budget.Burst >= 1 || budget.CallsPerSecond == 0, "WEATHER_BUDGET_BURST", "must be at least 1, got %d", budget.Burst)
+	v.check(budget.MaxWaitMs >= 0, "WEATHER_BUDGET_MAX_WAIT_MS", "must not be negative, got %d", budget.MaxWaitMs)
+	v.check(budget.PeriodCalls >= 0, "WEATHER_BUDGET_PERIOD_CALLS", "must not be negative, got %d", budget.PeriodCalls)
+	v.check(oneOf(budget.Period, "day", "month"), "WEATHER_BUDGET_PERIOD", "must be day or month, got %q", budget.Period)
+
+	outbound := c.ExternalAPIs.Outbound
+	v.check((outbound.ClientCertFile == "") == (outbound.ClientKeyFile == ""), "OUTBOUND_CLIENT_CERT_FILE", "and OUTBOUND_CLIENT_KEY_FILE must be set together")
+	if outbound.ProxyURL != "" {
+		v.httpURL("OUTBOUND_PROXY_URL", outbound.ProxyURL)
+	}
+	v.check(outbound.MaxIdleConns >= 0, "OUTBOUND_MAX_IDLE_CONNS", "must not be negative, got %d", outbound.MaxIdleConns)
+	v.check(outbound.MaxIdleConnsPerHost >= 0, "OUTBOUND_MAX_IDLE_CONNS_PER_HOST", "must not be negative, got %d", outbound.MaxIdleConnsPerHost)
+	v.check(outbound.IdleConnTimeoutSec >= 0, "OUTBOUND_IDLE_CONN_TIMEOUT_SEC", "must not be negative, got %d", outbound.IdleConnTimeoutSec)
+	v.check(outbound.TLSHandshakeTimeoutSec >= 0, "OUTBOUND_TLS_HANDSHAKE_TIMEOUT_SEC", "must not be negative, got %d", outbound.TLSHandshakeTimeoutSec)
+
+	v.check(oneOf(strings.ToLower(c.Log.Level), "debug", "info", "warn", "warning", "error"), "LOG_LEVEL", "must be debug, info, warn or error, got %q", c.Log.Level)
+	v.check(oneOf(strings.ToLower(c.Log.Format), "json", "text", "gcp"), "LOG_FORMAT", "must be json, text or gcp, got %q", c.Log.Format)
+
+	v.check(c.Telemetry.ServiceName != "", "OTEL_SERVICE_NAME", "is required")
+	v.check(oneOf(strings.ToLower(c.Telemetry.Exporter), "none", "stdout", "otlp"), "TRACING_EXPORTER", "must be none, stdout or otlp, got %q", c.Telemetry.Exporter)
+	if c.Telemetry.OTLPEndpoint != "" {
+		v.httpURL("OTEL_EXPORTER_OTLP_ENDPOINT", c.Telemetry.OTLPEndpoint)
+	}
+	v.check(c.Telemetry.SampleRatio >= 0 && c.Telemetry.SampleRatio <= 1, "TRACING_SAMPLE_RATIO", "must be between 0 and 1, got %g", c.Telemetry.SampleRatio)
+
+	v.check(c.Health.CacheTTLSec >= 0, "HEALTH_CACHE_TTL_SEC", "must not be negative, got %d", c.Health.CacheTTLSec)
+	v.check(c.Health.ProbeTimeoutSec > 0, "HEALTH_PROBE_TIMEOUT_SEC", "must be positive, got %d", c.Health.ProbeTimeoutSec)
+
+	apiKey := c.Auth.APIKey
+	if apiKey.Enabled {
+		v.check(apiKey.Header != "" || apiKey.QueryParam != "", "AUTH_API_KEY_HEADER", "or AUTH_API_KEY_QUERY_PARAM is required when API keys are enabled")
+		v.check(apiKey.Keys != "" || apiKey.File != "", "AUTH_API_KEYS", "or AUTH_API_KEYS_FILE is required when API keys are enabled")
+	}
+	v.check(apiKey.DefaultDailyQuota >= 0, "AUTH_API_KEY_DAILY_QUOTA", "must not be negative, got %d", apiKey.DefaultDailyQuota)
+	v.check(apiKey.DefaultMonthlyQuota >= 0, "AUTH_API_KEY_MONTHLY_QUOTA", "must not be negative, got %d", apiKey.DefaultMonthlyQuota)
+
+	jwt := c.Auth.JWT
+	if jwt.Enabled {
+		v.check(jwt.Issuer != "", "AUTH_JWT_ISSUER", "is required when JWT authentication is enabled")
+		v.check(jwt.Audience != "", "AUTH_JWT_AUDIENCE", "is required when JWT authentication is enabled")
+		v.check(jwt.JWKSURL != "" || jwt.KeyFile != "", "AUTH_JWT_JWKS_URL", "or AUTH_JWT_KEY_FILE is required when JWT authentication is enabled")
+	}
+	if jwt.JWKSURL != "" {
+		v.httpURL("AUTH_JWT_JWKS_URL", jwt.JWKSURL)
+	}
+	v.check(jwt.JWKSRefreshSec > 0, "AUTH_JWT_JWKS_REFRESH_SEC", "must be positive, got %d", jwt.JWKSRefreshSec)
+	v.check(jwt.LeewaySec >= 0, "AUTH_JWT_LEEWAY_SEC", "must not be negative, got %d", jwt.LeewaySec)
+
+	rl := c.RateLimit
+	if rl.Enabled {
+		v.check(rl.Requests > 0, "RATE_LIMIT_REQUESTS", "must be positive, got %d", rl.Requests)
+		v.check(rl.PeriodSec > 0, "RATE_LIMIT_PERIOD_SEC", "must be positive, got %d", rl.PeriodSec)
+		v.check(oneOf(rl.KeyBy, "principal", "ip", "route"), "RATE_LIMIT_KEY_BY", "must be principal, ip or route, got %q", rl.KeyBy)
+	}
+	for _, entry := range splitList(rl.Routes) {
+		idx := strings.LastIndex(entry, "=")
+		requests, err := strconv.Atoi(entry[idx+1:])
+		v.check(idx > 0 && err == nil && requests > 0, "RATE_LIMIT_ROUTES", "entry %q must be route=requests", entry)
+	}
+	v.check(rl.Burst >= 0, "RATE_LIMIT_BURST", "must not be negative, got %d", rl.Burst)
+
+	if gql := c.GraphQL; gql.Enabled {
+		v.check(gql.MaxDepth > 0, "GRAPHQL_MAX_DEPTH", "must be positive, got %d", gql.MaxDepth)
+		v.check(gql.MaxComplexity > 0, "GRAPHQL_MAX_COMPLEXITY", "must be positive, got %d", gql.MaxComplexity)
+		v.check(gql.MaxListSize > 0, "GRAPHQL_MAX_LIST_SIZE", "must be positive, got %d", gql.MaxListSize)
+	}
+
+	if stream := c.Stream; stream.Enabled {
+		v.check(stream.PollIntervalSec > 0, "STREAM_POLL_INTERVAL_SEC", "must be positive, got %d", stream.PollIntervalSec)
+		v.check(stream.HeartbeatSec > 0, "STREAM_HEARTBEAT_SEC", "must be positive, got %d", stream.HeartbeatSec)
+		v.check(stream.MaxCeps > 0, "STREAM_MAX_CEPS", "must be positive, got %d", stream.MaxCeps)
+	}
+
+	if cache := c.HTTPCache; cache.Enabled {
+		v.check(cache.WeatherMaxAgeSec >= 0, "HTTP_CACHE_WEATHER_MAX_AGE_SEC", "must not be negative, got %d", cache.WeatherMaxAgeSec)
+		v.check(cache.AddressMaxAgeSec >= 0, "HTTP_CACHE_ADDRESS_MAX_AGE_SEC", "must not be negative, got %d", cache.AddressMaxAgeSec)
+	}
+
+	if compression := c.Compression; compression.Enabled {
+		v.check(compression.MinSizeBytes >= 0, "COMPRESSION_MIN_SIZE_BYTES", "must not be negative, got %d", compression.MinSizeBytes)
+		v.check(len(compression.Encodings) > 0, "COMPRESSION_ENCODINGS", "must list at least one encoding")
+		for _, encoding := range compression.Encodings {
+			v.check(oneOf(encoding, "gzip", "br", "zstd"), "COMPRESSION_ENCODINGS", "must be gzip, br or zstd, got %q", encoding)
+		}
+		for _, entry := range splitList(compression.Routes) {
+			idx := strings.LastIndex(entry, "=")
+			size, err := strconv.Atoi(entry[idx+1:])
+			v.check(idx > 0 && (entry[idx+1:] == "off" || (err == nil && size >= 0)), "COMPRESSION_ROUTES", "entry %q must be route=bytes or route=off", entry)
+		}
+	}
+
+	versioning := c.Versioning
+	v.check(oneOf(versioning.Default, "v1", "v2"), "VERSIONING_DEFAULT", "must be v1 or v2, got %q", versioning.Default)
+	deprecatedAt, deprecatedErr := time.Parse(time.RFC3339, versioning.V1DeprecatedAt)
+	v.check(versioning.V1DeprecatedAt == "" || deprecatedErr == nil, "VERSIONING_V1_DEPRECATED_AT", "must be an RFC 3339 date, got %q", versioning.V1DeprecatedAt)
+	sunsetAt, sunsetErr := time.Parse(time.RFC3339, versioning.V1SunsetAt)
+	v.check(versioning.V1SunsetAt == "" || sunsetErr == nil, "VERSIONING_V1_SUNSET_AT", "must be an RFC 3339 date, got %q", versioning.V1SunsetAt)
+	if deprecatedErr == nil && sunsetErr == nil {
+		v.check(sunsetAt.After(deprecatedAt), "VERSIONING_V1_SUNSET_AT", "must be after VERSIONING_V1_DEPRECATED_AT, got %q", versioning.V1SunsetAt)
+	}
+
+	if c.GRPC.Enabled {
+		port, err := strconv.Atoi(c.GRPC.Port)
+		v.check(err == nil && port >= 1 && port <= 65535, "GRPC_PORT", "must be a number between 1 and 65535, got %q", c.GRPC.Port)
+		v.check(c.GRPC.Port != c.Server.Port, "GRPC_PORT", "must differ from PORT, got %q", c.GRPC.Port)
+		v.check(c.GRPC.MaxBatchSize > 0, "GRPC_MAX_BATCH_SIZE", "must be positive, got %d", c.GRPC.MaxBatchSize)
+	}
+
+	return errors.Join(v.errs...)
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) add(key, format string, args ...interface{}) {
+	v.errs = append(v.errs, fmt.Errorf("%s %s", key, fmt.Sprintf(format, args...)))
+}
+
+func (v *validator) check(ok bool, key, format string, args ...interface{}) {
+	if !ok {
+		v.add(key, format, args...)
+	}
+}
+
+func (v *validator) httpURL(key, value string) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.add(key, "must be an absolute http(s) URL, got %q", value)
+	}
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return true
+		}
+	}
+	return false
+}
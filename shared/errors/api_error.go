@@ -10,6 +10,10 @@ type APIError struct {
 	StatusCode int      `json:"-"`
 	Causes     []string `json:"causes,omitempty"`
 	Context    string   `json:"context,omitempty"`
+	// MessageText and CauseTexts keep Message and Causes with the message
+	// IDs and arguments they were built from, to be translated.
+	MessageText Text   `json:"-"`
+	CauseTexts  []Text `json:"-"`
 }
 
 func (e APIError) Error() string {
@@ -23,6 +27,8 @@ const (
 	BusinessError   ErrorType = "business"
 	SystemError     ErrorType = "system"
 	ExternalError   ErrorType = "external"
+	AuthError       ErrorType = "auth"
+	RateLimitError  ErrorType = "rate_limit"
 )
 
 const (
@@ -31,6 +37,18 @@ const (
 	CodeMissingParameter = "MISSING_PARAMETER"
 	CodeInvalidFormat    = "INVALID_FORMAT"
 
+	// Corpo da requisição acima do limite (413)
+	CodePayloadTooLarge = "PAYLOAD_TOO_LARGE"
+
+	// Formato de resposta não suportado (406)
+	CodeNotAcceptable = "NOT_ACCEPTABLE"
+
+	// Codificação do corpo da requisição não suportada (415)
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+
+	// Versão da API inexistente ou em conflito com o caminho (400)
+	CodeUnsupportedAPIVersion = "UNSUPPORTED_API_VERSION"
+
 	// Erros de negócio (400-404)
 	CodeResourceNotFound = "RESOURCE_NOT_FOUND"
 	CodeBusinessRule     = "BUSINESS_RULE_VIOLATION"
@@ -47,73 +65,186 @@ const (
 	// Erros de autenticação/autorização (401-403)
 	CodeUnauthorized = "UNAUTHORIZED"
 	CodeForbidden    = "FORBIDDEN"
+
+	// Erros de limite de requisições (429)
+	CodeTooManyRequests = "TOO_MANY_REQUESTS"
 )
 
-func NewAPIError(code, message string, statusCode int, causes []string) *APIError {
+func NewAPIError(code string, message Text, statusCode int, causes []Text) *APIError {
+	return &APIError{
+		Code:        code,
+		Message:     message.String(),
+		MessageText: message,
+		StatusCode:  statusCode,
+		Causes:      textStrings(causes),
+		CauseTexts:  causes,
+	}
+}
+
+func NewValidationError(message Text, causes []Text) *APIError {
+	return &APIError{
+		Code:        CodeInvalidInput,
+		Message:     message.String(),
+		MessageText: message,
+		StatusCode:  http.StatusBadRequest,
+		Causes:      textStrings(causes),
+		CauseTexts:  causes,
+		Context:     string(ValidationError),
+	}
+}
+
+func NewBusinessError(code string, message Text, causes []Text) *APIError {
+	return &APIError{
+		Code:        code,
+		Message:     message.String(),
+		MessageText: message,
+		StatusCode:  http.StatusBadRequest,
+		Causes:      textStrings(causes),
+		CauseTexts:  causes,
+		Context:     string(BusinessError),
+	}
+}
+
+func NewNotFoundError(message Text, causes []Text) *APIError {
+	return &APIError{
+		Code:        CodeResourceNotFound,
+		Message:     message.String(),
+		MessageText: message,
+		StatusCode:  http.StatusNotFound,
+		Causes:      textStrings(causes),
+		CauseTexts:  causes,
+		Context:     string(BusinessError),
+	}
+}
+
+func NewInternalError(message Text, causes []Text) *APIError {
+	return &APIError{
+		Code:        CodeInternalError,
+		Message:     message.String(),
+		MessageText: message,
+		StatusCode:  http.StatusInternalServerError,
+		Causes:      textStrings(causes),
+		CauseTexts:  causes,
+		Context:     string(SystemError),
+	}
+}
+
+func NewExternalServiceError(message Text, causes []Text) *APIError {
+	return &APIError{
+		Code:        CodeExternalService,
+		Message:     message.String(),
+		MessageText: message,
+		StatusCode:  http.StatusBadGateway,
+		Causes:      textStrings(causes),
+		CauseTexts:  causes,
+		Context:     string(ExternalError),
+	}
+}
+
+func NewServiceUnavailableError(message Text, causes []Text) *APIError {
+	return &APIError{
+		Code:        CodeServiceUnavailable,
+		Message:     message.String(),
+		MessageText: message,
+		StatusCode:  http.StatusServiceUnavailable,
+		Causes:      textStrings(causes),
+		CauseTexts:  causes,
+		Context:     string(ExternalError),
+	}
+}
+
+func NewTimeoutError(message Text, causes []Text) *APIError {
+	return &APIError{
+		Code:        CodeServiceTimeout,
+		Message:     message.String(),
+		MessageText: message,
+		StatusCode:  http.StatusGatewayTimeout,
+		Causes:      textStrings(causes),
+		CauseTexts:  causes,
+		Context:     string(ExternalError),
+	}
+}
+
+func NewUnauthorizedError(message Text, causes []Text) *APIError {
 	return &APIError{
-		Code:       code,
-		Message:    message,
-		StatusCode: statusCode,
-		Causes:     causes,
+		Code:        CodeUnauthorized,
+		Message:     message.String(),
+		MessageText: message,
+		StatusCode:  http.StatusUnauthorized,
+		Causes:      textStrings(causes),
+		CauseTexts:  causes,
+		Context:     string(AuthError),
 	}
 }
 
-func NewValidationError(message string, causes []string) *APIError {
+func NewForbiddenError(message Text, causes []Text) *APIError {
 	return &APIError{
-		Code:       CodeInvalidInput,
-		Message:    message,
-		StatusCode: http.StatusBadRequest,
-		Causes:     causes,
-		Context:    string(ValidationError),
+		Code:        CodeForbidden,
+		Message:     message.String(),
+		MessageText: message,
+		StatusCode:  http.StatusForbidden,
+		Causes:      textStrings(causes),
+		CauseTexts:  causes,
+		Context:     string(AuthError),
 	}
 }
 
-func NewBusinessError(code, message string, causes []string) *APIError {
+func NewTooManyRequestsError(message Text, causes []Text) *APIError {
 	return &APIError{
-		Code:       code,
-		Message:    message,
-		StatusCode: http.StatusBadRequest,
-		Causes:     causes,
-		Context:    string(BusinessError),
+		Code:        CodeTooManyRequests,
+		Message:     message.String(),
+		MessageText: message,
+		StatusCode:  http.StatusTooManyRequests,
+		Causes:      textStrings(causes),
+		CauseTexts:  causes,
+		Context:     string(RateLimitError),
 	}
 }
 
-func NewNotFoundError(message string, causes []string) *APIError {
+func NewPayloadTooLargeError(message Text, causes []Text) *APIError {
 	return &APIError{
-		Code:       CodeResourceNotFound,
-		Message:    message,
-		StatusCode: http.StatusNotFound,
-		Causes:     causes,
-		Context:    string(BusinessError),
+		Code:        CodePayloadTooLarge,
+		Message:     message.String(),
+		MessageText: message,
+		StatusCode:  http.StatusRequestEntityTooLarge,
+		Causes:      textStrings(causes),
+		CauseTexts:  causes,
+		Context:     string(ValidationError),
 	}
 }
 
-func NewInternalError(message string, causes []string) *APIError {
+func NewNotAcceptableError(message Text, causes []Text) *APIError {
 	return &APIError{
-		Code:       CodeInternalError,
-		Message:    message,
-		StatusCode: http.StatusInternalServerError,
-		Causes:     causes,
-		Context:    string(SystemError),
+		Code:        CodeNotAcceptable,
+		Message:     message.String(),
+		MessageText: message,
+		StatusCode:  http.StatusNotAcceptable,
+		Causes:      textStrings(causes),
+		CauseTexts:  causes,
+		Context:     string(ValidationError),
 	}
 }
 
-func NewExternalServiceError(message string, causes []string) *APIError {
+func NewUnsupportedMediaTypeError(message Text, causes []Text) *APIError {
 	return &APIError{
-		Code:       CodeExternalService,
-		Message:    message,
-		StatusCode: http.StatusBadGateway,
-		Causes:     causes,
-		Context:    string(ExternalError),
+		Code:        CodeUnsupportedMediaType,
+		Message:     message.String(),
+		MessageText: message,
+		StatusCode:  http.StatusUnsupportedMediaType,
+		Causes:      textStrings(causes),
+		CauseTexts:  causes,
+		Context:     string(ValidationError),
 	}
 }
 
-func NewTimeoutError(message string, causes []string) *APIError {
+func NewUnsupportedAPIVersionError(message Text, causes []Text) *APIError {
 	return &APIError{
-		Code:       CodeServiceTimeout,
-		Message:    message,
-		StatusCode: http.StatusGatewayTimeout,
-		Causes:     causes,
-		Context:    string(ExternalError),
+		Code:        CodeUnsupportedAPIVersion,
+		Message:     message.String(),
+		MessageText: message,
+		StatusCode:  http.StatusBadRequest,
+		Causes:      textStrings(causes),
+		CauseTexts:  causes,
+		Context:     string(ValidationError),
 	}
 }
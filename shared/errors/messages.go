@@ -0,0 +1,79 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Message is a client-facing text, written in English in the code. Its ID
+// keys the translations in the i18n catalogs, so the English wording can be
+// edited without losing them.
+type Message struct {
+	ID       string
+	Template string
+}
+
+// Text is a Message formatted with its arguments, which translations are
+// formatted with too. Text without an ID, such as a cause carrying an
+// upstream error, is never translated.
+type Text struct {
+	ID   string
+	Args []interface{}
+	text string
+}
+
+var messages = map[string]Message{}
+
+// NewMessage declares a message. Messages are declared in package variables
+// and their IDs must be unique.
+func NewMessage(id, template string) Message {
+	if _, ok := messages[id]; ok {
+		panic(fmt.Sprintf("message %q declared twice", id))
+	}
+	message := Message{ID: id, Template: template}
+	messages[id] = message
+	return message
+}
+
+// Messages lists every declared message, sorted by ID.
+func Messages() []Message {
+	declared := make([]Message, 0, len(messages))
+	for _, message := range messages {
+		declared = append(declared, message)
+	}
+	sort.Slice(declared, func(i, j int) bool { return declared[i].ID < declared[j].ID })
+	return declared
+}
+
+func (m Message) Format(args ...interface{}) Text {
+	text := m.Template
+	if len(args) > 0 {
+		text = fmt.Sprintf(m.Template, args...)
+	}
+	return Text{ID: m.ID, Args: args, text: text}
+}
+
+// Raw wraps text that has no translation.
+func Raw(text string) Text {
+	return Text{text: text}
+}
+
+// String returns the text in English.
+func (t Text) String() string {
+	return t.text
+}
+
+func textStrings(texts []Text) []string {
+	if texts == nil {
+		return nil
+	}
+	strs := make([]string, len(texts))
+	for i, text := range texts {
+		strs[i] = text.String()
+	}
+	return strs
+}
+
+var (
+	MsgInternalError = NewMessage("internal.error", "Internal server error occurred")
+)
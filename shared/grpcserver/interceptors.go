@@ -0,0 +1,99 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/requestid"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var requestIDKey = "x-request-id"
+
+// withRequestID mirrors RequestIDMiddleware: it reuses a valid x-request-id
+// metadata value or generates one, and returns it in the response header.
+func withRequestID(ctx context.Context, method string) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDKey); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	if !requestid.IsValid(id) {
+		id = requestid.New()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+
+	ctx = requestid.NewContext(ctx, id)
+	return logger.ContextWithFields(ctx, "request_id", id, "grpc_method", method)
+}
+
+func logCall(ctx context.Context, log logger.Logger, method string, start time.Time, err error) {
+	code := status.Code(err)
+	msg := fmt.Sprintf("gRPC %s %s (%dms)", method, code, time.Since(start).Milliseconds())
+	entry := log.WithContext(ctx)
+	switch code {
+	case codes.OK:
+		entry.Info(msg)
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable, codes.DeadlineExceeded:
+		entry.Error(msg)
+	default:
+		entry.Warn(msg)
+	}
+}
+
+func recovered(ctx context.Context, log logger.Logger, r interface{}) error {
+	log.WithContext(ctx).Error("Panic recovered: %v\n%s", r, debug.Stack())
+	return status.Error(codes.Internal, "Internal server error occurred")
+}
+
+func unaryInterceptor(log logger.Logger, timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		start := time.Now()
+		ctx = withRequestID(ctx, info.FullMethod)
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ctx, log, r)
+			}
+			logCall(ctx, log, info.FullMethod, start, err)
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func streamInterceptor(log logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		start := time.Now()
+		ctx := withRequestID(stream.Context(), info.FullMethod)
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ctx, log, r)
+			}
+			logCall(ctx, log, info.FullMethod, start, err)
+		}()
+
+		return handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+	}
+}
@@ -0,0 +1,99 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server is the internal gRPC API. It implements grpc.ServiceRegistrar so
+// generated Register functions can be used with it directly.
+type Server struct {
+	server *grpc.Server
+	health *health.Server
+	config config.GRPCConfig
+	logger logger.Logger
+}
+
+func NewServer(cfg *config.Config, log logger.Logger) *Server {
+	timeout := time.Duration(cfg.App.RequestTimeoutSec) * time.Second
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryInterceptor(log, timeout)),
+		grpc.ChainStreamInterceptor(streamInterceptor(log)),
+	)
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	if cfg.GRPC.ReflectionEnabled {
+		reflection.Register(server)
+	}
+
+	return &Server{
+		server: server,
+		health: healthServer,
+		config: cfg.GRPC,
+		logger: log,
+	}
+}
+
+func (s *Server) Enabled() bool {
+	return s.config.Enabled
+}
+
+// RegisterService registers a service and reports it as serving through the
+// gRPC health service.
+func (s *Server) RegisterService(desc *grpc.ServiceDesc, impl interface{}) {
+	s.server.RegisterService(desc, impl)
+	s.health.SetServingStatus(desc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+}
+
+func (s *Server) Start() error {
+	addr := fmt.Sprintf(":%s", s.config.Port)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to start gRPC server: %w", err)
+	}
+
+	s.logger.Info("Starting gRPC server on %s", addr)
+	return s.Serve(listener)
+}
+
+func (s *Server) Serve(listener net.Listener) error {
+	return s.server.Serve(listener)
+}
+
+// StartDraining reports every service as not serving so clients balancing on
+// the health service move away before the server stops.
+func (s *Server) StartDraining() {
+	s.health.Shutdown()
+}
+
+// Shutdown waits for in-flight calls and streams until ctx is done, then
+// closes the remaining ones.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.logger.Info("Shutting down gRPC server...")
+	s.health.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
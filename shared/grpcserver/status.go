@@ -0,0 +1,72 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain identifies this API in the google.rpc.ErrorInfo details.
+const ErrorDomain = "weather-api"
+
+// Code maps the HTTP status of an APIError to the closest gRPC code.
+func Code(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+// Status converts an error returned by a use case into a gRPC status. The
+// APIError code is kept as the ErrorInfo reason, with its causes joined in
+// the metadata.
+func Status(err error) *status.Status {
+	var apiErr *sharedErrors.APIError
+	switch {
+	case errors.As(err, &apiErr):
+		st := status.New(Code(apiErr.StatusCode), apiErr.Message)
+		info := &errdetails.ErrorInfo{Reason: apiErr.Code, Domain: ErrorDomain}
+		if len(apiErr.Causes) > 0 {
+			info.Metadata = map[string]string{"causes": strings.Join(apiErr.Causes, "; ")}
+		}
+		if detailed, detailErr := st.WithDetails(info); detailErr == nil {
+			return detailed
+		}
+		return st
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.New(codes.DeadlineExceeded, "Request timeout exceeded")
+	case errors.Is(err, context.Canceled):
+		return status.New(codes.Canceled, "Request canceled")
+	default:
+		return status.New(codes.Internal, "Internal server error occurred")
+	}
+}
+
+// Error is Status as an error, and nil for a nil err.
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	return Status(err).Err()
+}
@@ -0,0 +1,57 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected codes.Code
+	}{
+		{name: "Validation", err: sharedErrors.NewValidationError(sharedErrors.Raw("bad"), nil), expected: codes.InvalidArgument},
+		{name: "Not found", err: sharedErrors.NewNotFoundError(sharedErrors.Raw("zipcode"), nil), expected: codes.NotFound},
+		{name: "Unauthorized", err: sharedErrors.NewUnauthorizedError(sharedErrors.Raw("no key"), nil), expected: codes.Unauthenticated},
+		{name: "Rate limited", err: sharedErrors.NewTooManyRequestsError(sharedErrors.Raw("slow down"), nil), expected: codes.ResourceExhausted},
+		{name: "Upstream", err: sharedErrors.NewExternalServiceError(sharedErrors.Raw("down"), nil), expected: codes.Unavailable},
+		{name: "Budget", err: sharedErrors.NewServiceUnavailableError(sharedErrors.Raw("later"), nil), expected: codes.Unavailable},
+		{name: "Wrapped", err: fmt.Errorf("lookup: %w", sharedErrors.NewValidationError(sharedErrors.Raw("bad"), nil)), expected: codes.InvalidArgument},
+		{name: "Timeout", err: sharedErrors.NewTimeoutError(sharedErrors.Raw("slow"), nil), expected: codes.DeadlineExceeded},
+		{name: "Deadline", err: context.DeadlineExceeded, expected: codes.DeadlineExceeded},
+		{name: "Canceled", err: context.Canceled, expected: codes.Canceled},
+		{name: "Unknown", err: errors.New("boom"), expected: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Status(tt.err).Code())
+		})
+	}
+}
+
+func TestErrorIsNilForNil(t *testing.T) {
+	assert.NoError(t, Error(nil))
+}
+
+func TestStatusKeepsAPIErrorCode(t *testing.T) {
+	err := sharedErrors.NewAPIError("ZIPCODE_NOT_FOUND", sharedErrors.Raw("can not find zipcode"), 404, []sharedErrors.Text{sharedErrors.Raw("unknown CEP")})
+
+	st := Status(err)
+
+	assert.Equal(t, "can not find zipcode", st.Message())
+	require.Len(t, st.Details(), 1)
+	info := st.Details()[0].(*errdetails.ErrorInfo)
+	assert.Equal(t, "ZIPCODE_NOT_FOUND", info.GetReason())
+	assert.Equal(t, map[string]string{"causes": "unknown CEP"}, info.GetMetadata())
+}
@@ -0,0 +1,35 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func LivenessHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "alive"})
+	}
+}
+
+// LegacyHealthHandler serves /health with the body it had before /livez
+// existed, for clients that check it.
+func LegacyHealthHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "healthy"})
+	}
+}
+
+// ReadinessHandler answers 503 with the per-check details when a critical
+// dependency is down. Non-critical failures are reported as degraded.
+func ReadinessHandler(registry *Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := registry.Check(c.Request.Context())
+
+		status := http.StatusOK
+		if !report.Ready() {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	}
+}
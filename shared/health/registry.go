@@ -0,0 +1,182 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+
+	StatusReady    = "ready"
+	StatusDegraded = "degraded"
+	StatusNotReady = "not_ready"
+)
+
+const defaultTimeout = 5 * time.Second
+
+type Probe func(ctx context.Context) error
+
+type degradedError struct {
+	err error
+}
+
+func (e degradedError) Error() string { return e.err.Error() }
+
+func (e degradedError) Unwrap() error { return e.err }
+
+// Degraded marks a probe failure that must not take the instance out of
+// traffic, even when the check is critical: it is reported as degraded.
+func Degraded(err error) error {
+	return degradedError{err: err}
+}
+
+type Check struct {
+	Name     string
+	Critical bool
+	Timeout  time.Duration
+	Probe    Probe
+}
+
+type Result struct {
+	Name       string    `json:"name"`
+	Status     string    `json:"status"`
+	Critical   bool      `json:"critical"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+type Report struct {
+	Status   string   `json:"status"`
+	Draining bool     `json:"draining,omitempty"`
+	Checks   []Result `json:"checks"`
+}
+
+func (r Report) Ready() bool {
+	return r.Status != StatusNotReady
+}
+
+type registeredCheck struct {
+	Check
+
+	mu     sync.Mutex
+	last   Result
+	hasRun bool
+}
+
+// Registry runs the probes registered by each dependency. Results are cached
+// for cacheTTL so frequent readiness polls do not hammer the upstream APIs.
+type Registry struct {
+	mu       sync.RWMutex
+	checks   []*registeredCheck
+	cacheTTL atomic.Int64
+	draining atomic.Bool
+	now      func() time.Time
+}
+
+func NewRegistry(cacheTTL time.Duration) *Registry {
+	registry := &Registry{now: time.Now}
+	registry.SetCacheTTL(cacheTTL)
+	return registry
+}
+
+// SetCacheTTL changes how long probe results are reused; it applies to the
+// next readiness check.
+func (r *Registry) SetCacheTTL(ttl time.Duration) {
+	r.cacheTTL.Store(int64(ttl))
+}
+
+func (r *Registry) Register(check Check) {
+	if check.Timeout <= 0 {
+		check.Timeout = defaultTimeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, &registeredCheck{Check: check})
+}
+
+// StartDraining makes every following check report not ready, so load
+// balancers stop routing traffic while the app shuts down.
+func (r *Registry) StartDraining() {
+	r.draining.Store(true)
+}
+
+func (r *Registry) Check(ctx context.Context) Report {
+	if r.draining.Load() {
+		return Report{Status: StatusNotReady, Draining: true, Checks: []Result{}}
+	}
+
+	r.mu.RLock()
+	checks := make([]*registeredCheck, len(r.checks))
+	copy(checks, r.checks)
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check *registeredCheck) {
+			defer wg.Done()
+			results[i] = r.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusReady, Checks: results}
+	for _, result := range results {
+		if result.Status == StatusUp {
+			continue
+		}
+		if result.Critical {
+			report.Status = StatusNotReady
+			break
+		}
+		report.Status = StatusDegraded
+	}
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, check *registeredCheck) Result {
+	// Holding the per-check lock while probing collapses concurrent polls
+	// into a single upstream call.
+	check.mu.Lock()
+	defer check.mu.Unlock()
+
+	if check.hasRun && r.now().Sub(check.last.CheckedAt) < time.Duration(r.cacheTTL.Load()) {
+		return check.last
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	start := r.now()
+	err := check.Probe(probeCtx)
+	if err == nil && errors.Is(probeCtx.Err(), context.DeadlineExceeded) {
+		err = probeCtx.Err()
+	}
+
+	result := Result{
+		Name:       check.Name,
+		Status:     StatusUp,
+		Critical:   check.Critical,
+		DurationMs: r.now().Sub(start).Milliseconds(),
+		CheckedAt:  start,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+		if errors.As(err, &degradedError{}) {
+			result.Critical = false
+		}
+	}
+
+	check.last = result
+	check.hasRun = true
+	return result
+}
@@ -0,0 +1,154 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func countingProbe(calls *int32, err error) Probe {
+	return func(ctx context.Context) error {
+		atomic.AddInt32(calls, 1)
+		return err
+	}
+}
+
+func TestRegistryAllChecksUp(t *testing.T) {
+	registry := NewRegistry(time.Minute)
+	var calls int32
+	registry.Register(Check{Name: "viacep", Critical: true, Probe: countingProbe(&calls, nil)})
+	registry.Register(Check{Name: "weather", Critical: true, Probe: countingProbe(&calls, nil)})
+
+	report := registry.Check(context.Background())
+
+	assert.Equal(t, StatusReady, report.Status)
+	assert.True(t, report.Ready())
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, "viacep", report.Checks[0].Name)
+	assert.Equal(t, StatusUp, report.Checks[0].Status)
+	assert.Equal(t, int32(2), calls)
+}
+
+func TestRegistryCriticalFailureIsNotReady(t *testing.T) {
+	registry := NewRegistry(time.Minute)
+	registry.Register(Check{Name: "viacep", Critical: true, Probe: countingProbe(new(int32), errors.New("connection refused"))})
+	registry.Register(Check{Name: "weather", Critical: true, Probe: countingProbe(new(int32), nil)})
+
+	report := registry.Check(context.Background())
+
+	assert.Equal(t, StatusNotReady, report.Status)
+	assert.False(t, report.Ready())
+	assert.Equal(t, StatusDown, report.Checks[0].Status)
+	assert.Equal(t, "connection refused", report.Checks[0].Error)
+}
+
+func TestRegistryNonCriticalFailureIsDegraded(t *testing.T) {
+	registry := NewRegistry(time.Minute)
+	registry.Register(Check{Name: "viacep", Critical: true, Probe: countingProbe(new(int32), nil)})
+	registry.Register(Check{Name: "optional", Critical: false, Probe: countingProbe(new(int32), errors.New("down"))})
+
+	report := registry.Check(context.Background())
+
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.True(t, report.Ready())
+}
+
+func TestRegistryDegradedFailureOfCriticalCheck(t *testing.T) {
+	registry := NewRegistry(time.Minute)
+	registry.Register(Check{Name: "weather", Critical: true, Probe: countingProbe(new(int32), Degraded(errors.New("quota used")))})
+
+	report := registry.Check(context.Background())
+
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.True(t, report.Ready())
+	assert.Equal(t, StatusDown, report.Checks[0].Status)
+	assert.False(t, report.Checks[0].Critical)
+	assert.Equal(t, "quota used", report.Checks[0].Error)
+}
+
+func TestRegistryCachesResults(t *testing.T) {
+	registry := NewRegistry(30 * time.Second)
+	now := time.Now()
+	registry.now = func() time.Time { return now }
+
+	var calls int32
+	registry.Register(Check{Name: "viacep", Critical: true, Probe: countingProbe(&calls, nil)})
+
+	registry.Check(context.Background())
+	registry.Check(context.Background())
+	assert.Equal(t, int32(1), calls)
+
+	now = now.Add(31 * time.Second)
+	registry.Check(context.Background())
+	assert.Equal(t, int32(2), calls)
+}
+
+func TestRegistryProbeTimeout(t *testing.T) {
+	registry := NewRegistry(0)
+	registry.Register(Check{
+		Name:     "slow",
+		Critical: true,
+		Timeout:  10 * time.Millisecond,
+		Probe: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	report := registry.Check(context.Background())
+
+	assert.Equal(t, StatusNotReady, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Error)
+}
+
+func TestReadinessHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	registry := NewRegistry(0)
+	registry.Register(Check{Name: "weather", Critical: true, Probe: countingProbe(new(int32), errors.New("weather API key was rejected"))})
+
+	router := gin.New()
+	router.GET("/readyz", ReadinessHandler(registry))
+	router.GET("/livez", LivenessHandler())
+	router.GET("/health", LegacyHealthHandler())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var report Report
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, StatusNotReady, report.Status)
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, "weather API key was rejected", report.Checks[0].Error)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"alive"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"healthy"}`, w.Body.String())
+}
+
+func TestRegistryReportsNotReadyWhileDraining(t *testing.T) {
+	registry := NewRegistry(0)
+	registry.Register(Check{Name: "viacep", Critical: true, Probe: func(ctx context.Context) error { return nil }})
+
+	registry.StartDraining()
+	report := registry.Check(context.Background())
+
+	assert.False(t, report.Ready())
+	assert.True(t, report.Draining)
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLogMiddleware writes one line per request carrying an httpRequest
+// object in the shape Cloud Logging expects, replacing gin.Logger. The values
+// of secretParams, such as the API key query parameter, are redacted.
+func AccessLogMiddleware(log logger.Logger, secretParams ...string) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start)
+
+		req := c.Request
+		status := c.Writer.Status()
+
+		entry := log.WithContext(req.Context()).With(slog.Group("httpRequest",
+			slog.String("requestMethod", req.Method),
+			slog.String("requestUrl", redactedURL(req.URL, secretParams)),
+			slog.Int("status", status),
+			slog.String("responseSize", strconv.Itoa(responseSize(c))),
+			slog.String("userAgent", req.UserAgent()),
+			slog.String("remoteIp", c.ClientIP()),
+			slog.String("referer", req.Referer()),
+			slog.String("latency", fmt.Sprintf("%.9fs", latency.Seconds())),
+			slog.String("protocol", req.Proto),
+		))
+
+		msg := fmt.Sprintf("%s %s %d", req.Method, req.URL.Path, status)
+		switch {
+		case status >= http.StatusInternalServerError:
+			entry.Error(msg)
+		case status >= http.StatusBadRequest:
+			entry.Warn(msg)
+		default:
+			entry.Info(msg)
+		}
+	})
+}
+
+func redactedURL(u *url.URL, secretParams []string) string {
+	query := u.Query()
+	redacted := false
+	for _, name := range secretParams {
+		if name != "" && query.Has(name) {
+			query.Set(name, "REDACTED")
+			redacted = true
+		}
+	}
+	if !redacted {
+		return u.String()
+	}
+	copied := *u
+	copied.RawQuery = query.Encode()
+	return copied.String()
+}
+
+func responseSize(c *gin.Context) int {
+	if size := c.Writer.Size(); size > 0 {
+		return size
+	}
+	return 0
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxAgeKey       = "cache_max_age"
+	lastModifiedKey = "cache_last_modified"
+)
+
+// cacheWriter holds the response back so its ETag can be computed, and
+// dropped in favour of a 304, once the handler is done.
+type cacheWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *cacheWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *cacheWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *cacheWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *cacheWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *cacheWriter) Status() int {
+	return w.status
+}
+
+func (w *cacheWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *cacheWriter) Written() bool {
+	return w.written
+}
+
+// CacheMiddleware makes successful GET responses cacheable for the max-age
+// maxAge picks: it sets Cache-Control, a strong ETag over the body and, when
+// the handler called SetLastModified, Last-Modified, and answers matching
+// If-None-Match / If-Modified-Since requests with 304. The configuration is
+// read from store on every request, so reloads apply without a restart.
+func CacheMiddleware(store *config.Store, maxAge func(config.HTTPCacheConfig) time.Duration) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		cfg := store.Current().HTTPCache
+		if !cfg.Enabled || c.Request.Method != http.MethodGet || IsStreamRequest(c) {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		buffered := &cacheWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = buffered
+		// Restored even when a handler panics, so the recovery response
+		// reaches the client instead of the dropped buffer.
+		defer func() { c.Writer = original }()
+		c.Set(maxAgeKey, maxAge(cfg))
+		c.Next()
+
+		if !buffered.written {
+			original.WriteHeader(buffered.status)
+			return
+		}
+		maxAge := c.GetDuration(maxAgeKey)
+		if buffered.status != http.StatusOK || maxAge <= 0 {
+			original.WriteHeader(buffered.status)
+			_, _ = original.Write(buffered.body.Bytes())
+			return
+		}
+
+		header := original.Header()
+		etag := strongETag(buffered.body.Bytes())
+		header.Set("ETag", etag)
+		header.Set("Cache-Control", cacheControl(cfg, maxAge))
+		lastModified, hasLastModified := c.Get(lastModifiedKey)
+		if hasLastModified {
+			header.Set("Last-Modified", lastModified.(time.Time).UTC().Format(http.TimeFormat))
+		}
+
+		if notModified(c.Request, etag, lastModified) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			original.WriteHeader(http.StatusNotModified)
+			original.WriteHeaderNow()
+			return
+		}
+		original.WriteHeader(buffered.status)
+		_, _ = original.Write(buffered.body.Bytes())
+	})
+}
+
+// SetMaxAge overrides the max-age of CacheMiddleware for this response; zero
+// leaves the response without caching headers.
+func SetMaxAge(c *gin.Context, maxAge time.Duration) {
+	c.Set(maxAgeKey, maxAge)
+}
+
+// SetLastModified records when the data in the response was observed.
+func SetLastModified(c *gin.Context, at time.Time) {
+	if !at.IsZero() {
+		c.Set(lastModifiedKey, at)
+	}
+}
+
+func cacheControl(cfg config.HTTPCacheConfig, maxAge time.Duration) string {
+	visibility := "private"
+	if cfg.Public {
+		visibility = "public"
+	}
+	return fmt.Sprintf("%s, max-age=%d", visibility, int(maxAge.Seconds()))
+}
+
+func strongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:]) + `"`
+}
+
+// notModified evaluates the conditional headers in RFC 9110 order:
+// If-Modified-Since only counts when If-None-Match is absent.
+func notModified(r *http.Request, etag string, lastModified interface{}) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	modified, ok := lastModified.(time.Time)
+	if !ok {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !modified.Truncate(time.Second).After(since)
+}
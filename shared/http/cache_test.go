@@ -0,0 +1,184 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var observedAt = time.Date(2026, 10, 19, 12, 30, 0, 0, time.UTC)
+
+func setupCacheRouter(cfg config.HTTPCacheConfig, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/test", CacheMiddleware(cacheStore(cfg), weatherMaxAge), handler)
+	return router
+}
+
+func cacheStore(cfg config.HTTPCacheConfig) *config.Store {
+	return config.NewStore(&config.Config{HTTPCache: cfg}, config.Options{})
+}
+
+func weatherMaxAge(cfg config.HTTPCacheConfig) time.Duration {
+	return time.Duration(cfg.WeatherMaxAgeSec) * time.Second
+}
+
+func serveCached(router *gin.Engine, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func cachedWeather(c *gin.Context) {
+	SetLastModified(c, observedAt)
+	RespondWithSuccess(c, map[string]float64{"temp_C": 21.5}, "ok")
+}
+
+func TestCacheMiddlewareSetsValidators(t *testing.T) {
+	router := setupCacheRouter(config.HTTPCacheConfig{Enabled: true, WeatherMaxAgeSec: 60}, cachedWeather)
+
+	w := serveCached(router, nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "private, max-age=60", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "Mon, 19 Oct 2026 12:30:00 GMT", w.Header().Get("Last-Modified"))
+	assert.Regexp(t, `^"[A-Za-z0-9_-]{43}"$`, w.Header().Get("ETag"))
+	assert.JSONEq(t, `{"data":{"temp_C":21.5},"message":"ok"}`, w.Body.String())
+
+	again := serveCached(router, nil)
+	assert.Equal(t, w.Header().Get("ETag"), again.Header().Get("ETag"))
+}
+
+func TestCacheMiddlewareETagFollowsFormat(t *testing.T) {
+	router := setupCacheRouter(config.HTTPCacheConfig{Enabled: true, Public: true, WeatherMaxAgeSec: 60}, cachedWeather)
+
+	json := serveCached(router, nil)
+	csv := serveCached(router, map[string]string{"Accept": "text/csv"})
+
+	assert.Equal(t, "public, max-age=60", csv.Header().Get("Cache-Control"))
+	assert.NotEqual(t, json.Header().Get("ETag"), csv.Header().Get("ETag"))
+}
+
+func TestCacheMiddlewareNotModified(t *testing.T) {
+	router := setupCacheRouter(config.HTTPCacheConfig{Enabled: true, WeatherMaxAgeSec: 60}, cachedWeather)
+	etag := serveCached(router, nil).Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected int
+	}{
+		{name: "Matching ETag", headers: map[string]string{"If-None-Match": etag}, expected: http.StatusNotModified},
+		{name: "Weak comparison in a list", headers: map[string]string{"If-None-Match": `"other", W/` + etag}, expected: http.StatusNotModified},
+		{name: "Any", headers: map[string]string{"If-None-Match": "*"}, expected: http.StatusNotModified},
+		{name: "Other ETag", headers: map[string]string{"If-None-Match": `"other"`}, expected: http.StatusOK},
+		{name: "Not modified since", headers: map[string]string{"If-Modified-Since": "Mon, 19 Oct 2026 12:30:00 GMT"}, expected: http.StatusNotModified},
+		{name: "Modified since", headers: map[string]string{"If-Modified-Since": "Mon, 19 Oct 2026 12:29:59 GMT"}, expected: http.StatusOK},
+		{name: "If-None-Match wins", headers: map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": "Mon, 19 Oct 2026 13:00:00 GMT"}, expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveCached(router, tt.headers)
+
+			assert.Equal(t, tt.expected, w.Code)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			if tt.expected == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+				assert.Empty(t, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestCacheMiddlewareSkipsUncacheableResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.HTTPCacheConfig
+		handler gin.HandlerFunc
+	}{
+		{name: "Disabled", cfg: config.HTTPCacheConfig{}, handler: cachedWeather},
+		{name: "Error", cfg: config.HTTPCacheConfig{Enabled: true, WeatherMaxAgeSec: 60}, handler: func(c *gin.Context) {
+			RespondWithNotFound(c, sharedErrors.Raw("zipcode not found"), nil)
+		}},
+		{name: "Max-age cleared", cfg: config.HTTPCacheConfig{Enabled: true, WeatherMaxAgeSec: 60}, handler: func(c *gin.Context) {
+			SetMaxAge(c, 0)
+			cachedWeather(c)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupCacheRouter(tt.cfg, tt.handler)
+
+			w := serveCached(router, map[string]string{"If-None-Match": "*"})
+
+			assert.NotEqual(t, http.StatusNotModified, w.Code)
+			assert.Empty(t, w.Header().Get("ETag"))
+			assert.Empty(t, w.Header().Get("Cache-Control"))
+			assert.NotEmpty(t, w.Body.String())
+		})
+	}
+}
+
+func TestCacheMiddlewareRecoversPanics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.LogConfig{Level: "error", Format: "json"}
+	log := logger.NewWithWriter(io.Discard, cfg, logger.NewLevel(&config.Config{Log: cfg}))
+	router := gin.New()
+	router.Use(ErrorHandlerMiddleware(log))
+	router.GET("/test", CacheMiddleware(cacheStore(config.HTTPCacheConfig{Enabled: true, WeatherMaxAgeSec: 60}), weatherMaxAge), func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+
+	w := serveCached(router, nil)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "Internal server error")
+	assert.NotContains(t, w.Body.String(), "partial")
+	assert.Empty(t, w.Header().Get("ETag"))
+}
+
+func TestCacheMiddlewareFollowsReloads(t *testing.T) {
+	t.Setenv("ENV", "")
+	t.Setenv("HTTP_CACHE_WEATHER_MAX_AGE_SEC", "")
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	content := "app:\n  env: development\nhttp_cache:\n  weather_max_age_sec: 60\n"
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	opts := config.Options{File: file}
+	cfg, err := config.Load(opts)
+	require.NoError(t, err)
+	store := config.NewStore(cfg, opts)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/test", CacheMiddleware(store, weatherMaxAge), cachedWeather)
+
+	assert.Equal(t, "private, max-age=60", serveCached(router, nil).Header().Get("Cache-Control"))
+
+	require.NoError(t, os.WriteFile(file, []byte(strings.Replace(content, "60", "300", 1)), 0o600))
+	result, err := store.Reload()
+	require.NoError(t, err)
+	assert.Contains(t, result.Applied, "http_cache.weather_max_age_sec")
+
+	assert.Equal(t, "private, max-age=300", serveCached(router, nil).Header().Get("Cache-Control"))
+}
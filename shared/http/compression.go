@@ -0,0 +1,323 @@
+package http
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	msgEncodingUnsupported = sharedErrors.NewMessage("encoding.unsupported", "Content-Encoding %q is not supported, use gzip")
+	msgInvalidGzip         = sharedErrors.NewMessage("request.invalid_gzip", "Invalid gzip request body")
+)
+
+// compressionOff marks a route excluded with "route=off".
+const compressionOff = -1
+
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var encoderPools = map[string]*sync.Pool{
+	"gzip": {New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	}},
+	"br": {New: func() interface{} {
+		return brotli.NewWriterLevel(nil, brotli.DefaultCompression)
+	}},
+	"zstd": {New: func() interface{} {
+		w, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		return w
+	}},
+}
+
+// incompressibleTypes are already compressed; compressing them again only
+// costs CPU.
+var incompressibleTypes = []string{"image/", "video/", "audio/", "application/zip", "application/gzip", "application/zstd"}
+
+// compressWriter holds the body back until it reaches the minimum size, then
+// either compresses everything or sends it as is.
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+	buf      []byte
+	decided  bool
+	encoder  encoder
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, data...)
+		if len(w.buf) < w.minSize {
+			return len(data), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) Written() bool {
+	return w.decided || len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(len(w.buf) >= w.minSize)
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide sets the response headers for the chosen coding and sends what was
+// buffered so far.
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	header := w.Header()
+	if !compressible(header) || w.ResponseWriter.Written() {
+		compress = false
+	} else {
+		header.Add("Vary", "Accept-Encoding")
+	}
+
+	if compress && w.encoding != "" {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", w.encoding)
+		// The coded body is a different representation: the ETag becomes
+		// weak so If-None-Match still matches it.
+		if etag := header.Get("ETag"); strings.HasPrefix(etag, `"`) {
+			header.Set("ETag", "W/"+etag)
+		}
+		w.encoder = encoderPools[w.encoding].Get().(encoder)
+		w.encoder.Reset(w.ResponseWriter)
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.encoder != nil {
+		_, err := w.encoder.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+func (w *compressWriter) finish() {
+	if !w.decided {
+		if len(w.buf) == 0 {
+			return
+		}
+		_ = w.decide(false)
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+		encoderPools[w.encoding].Put(w.encoder)
+		w.encoder = nil
+	}
+}
+
+func compressible(header http.Header) bool {
+	contentType := header.Get("Content-Type")
+	if contentType == "" || header.Get("Content-Encoding") != "" {
+		return false
+	}
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// CompressionMiddleware compresses responses with the coding preferred in
+// Accept-Encoding among cfg.Encodings. Streams are left alone since the
+// coders would hold events back.
+func CompressionMiddleware(cfg config.CompressionConfig) gin.HandlerFunc {
+	routes, _ := routeOverrides(cfg.Routes, func(value string) (int64, error) {
+		if value == "off" {
+			return compressionOff, nil
+		}
+		return strconv.ParseInt(value, 10, 64)
+	})
+
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if !cfg.Enabled || IsStreamRequest(c) || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		minSize := int64(cfg.MinSizeBytes)
+		if override, ok := routes[c.FullPath()]; ok {
+			minSize = override
+		}
+		if minSize == compressionOff {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &compressWriter{
+			ResponseWriter: original,
+			encoding:       negotiateEncoding(c.GetHeader("Accept-Encoding"), cfg.Encodings),
+			minSize:        int(minSize),
+		}
+		c.Writer = writer
+		// Deferred so a panic still closes the encoder and returns it to
+		// its pool; what was held back is dropped so the recovery response
+		// can be written instead.
+		completed := false
+		defer func() {
+			if !completed {
+				writer.buf = nil
+			}
+			writer.finish()
+			c.Writer = original
+		}()
+		c.Next()
+		completed = true
+	})
+}
+
+// negotiateEncoding picks the supported coding with the highest quality in
+// Accept-Encoding; ties go to the earlier entry of supported.
+func negotiateEncoding(acceptEncoding string, supported []string) string {
+	ranges := parseAccept(acceptEncoding)
+	best, bestQ := "", 0.0
+	for _, encoding := range supported {
+		q, wildcard := -1.0, 0.0
+		for _, r := range ranges {
+			if r.mediaType == encoding {
+				q = r.q
+				break
+			}
+			if r.mediaType == "*" {
+				wildcard = r.q
+			}
+		}
+		if q < 0 {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// BodyLimits is the request body size limit, overridable per route.
+type BodyLimits struct {
+	Default int64
+	Routes  map[string]int64
+}
+
+// NewBodyLimits reads SERVER_MAX_BODY_BYTES and SERVER_MAX_BODY_BYTES_ROUTES.
+func NewBodyLimits(cfg config.ServerConfig) (BodyLimits, error) {
+	routes, err := routeOverrides(cfg.MaxBodyBytesRoutes, func(value string) (int64, error) {
+		return strconv.ParseInt(value, 10, 64)
+	})
+	if err != nil {
+		return BodyLimits{Default: cfg.MaxBodyBytes}, fmt.Errorf("invalid SERVER_MAX_BODY_BYTES_ROUTES: %w", err)
+	}
+	return BodyLimits{Default: cfg.MaxBodyBytes, Routes: routes}, nil
+}
+
+// For returns the limit of the route pattern; zero means unlimited.
+func (l BodyLimits) For(route string) int64 {
+	if limit, ok := l.Routes[route]; ok {
+		return limit
+	}
+	return l.Default
+}
+
+// DecompressionMiddleware inflates gzip request bodies, holding the inflated
+// body to the route limit as well. Other codings get 415.
+func DecompressionMiddleware(limits BodyLimits) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+		if encoding == "" || encoding == "identity" || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+		if encoding != "gzip" && encoding != "x-gzip" {
+			RespondWithUnsupportedMediaType(c, sharedErrors.Text{}, []sharedErrors.Text{msgEncodingUnsupported.Format(encoding)})
+			c.Abort()
+			return
+		}
+
+		reader, err := gzip.NewReader(c.Request.Body)
+		if err != nil {
+			RespondWithValidationError(c, msgInvalidGzip.Format(), []sharedErrors.Text{sharedErrors.Raw(err.Error())})
+			c.Abort()
+			return
+		}
+		var body io.ReadCloser = gzipBody{Reader: reader, raw: c.Request.Body}
+		if limit := limits.For(c.FullPath()); limit > 0 {
+			body = http.MaxBytesReader(c.Writer, body, limit)
+		}
+
+		c.Request.Body = body
+		c.Request.ContentLength = -1
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Del("Content-Length")
+		c.Next()
+	})
+}
+
+type gzipBody struct {
+	*gzip.Reader
+	raw io.Closer
+}
+
+func (b gzipBody) Close() error {
+	_ = b.Reader.Close()
+	return b.raw.Close()
+}
+
+// routeOverrides parses comma-separated "route=value" pairs.
+func routeOverrides(entries string, parse func(value string) (int64, error)) (map[string]int64, error) {
+	routes := map[string]int64{}
+	for _, entry := range strings.Split(entries, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		idx := strings.LastIndex(entry, "=")
+		if idx <= 0 {
+			return nil, fmt.Errorf("entry %q: expected route=value", entry)
+		}
+		value, err := parse(entry[idx+1:])
+		if err != nil {
+			return nil, fmt.Errorf("entry %q: %w", entry, err)
+		}
+		routes[entry[:idx]] = value
+	}
+	return routes, nil
+}
@@ -0,0 +1,242 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var largeBody = strings.Repeat("temperature ", 200)
+
+func setupCompressionRouter(cfg config.CompressionConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CompressionMiddleware(cfg))
+	router.GET("/large", func(c *gin.Context) {
+		c.Header("ETag", `"v1"`)
+		c.String(http.StatusOK, largeBody)
+	})
+	router.GET("/small", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	router.GET("/image", func(c *gin.Context) {
+		c.Data(http.StatusOK, "image/png", []byte(largeBody))
+	})
+	return router
+}
+
+func defaultCompression() config.CompressionConfig {
+	return config.CompressionConfig{Enabled: true, MinSizeBytes: 1024, Encodings: []string{"zstd", "br", "gzip"}}
+}
+
+func decode(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+	var reader io.Reader
+	switch encoding {
+	case "gzip":
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		reader = gz
+	case "br":
+		reader = brotli.NewReader(bytes.NewReader(body))
+	case "zstd":
+		zr, err := zstd.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		defer zr.Close()
+		reader = zr
+	default:
+		return string(body)
+	}
+	decoded, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(decoded)
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	supported := []string{"zstd", "br", "gzip"}
+
+	assert.Equal(t, "zstd", negotiateEncoding("gzip, deflate, br, zstd", supported))
+	assert.Equal(t, "gzip", negotiateEncoding("gzip;q=1, br;q=0.5", supported))
+	assert.Equal(t, "br", negotiateEncoding("br, *;q=0.1", supported))
+	assert.Equal(t, "zstd", negotiateEncoding("*", supported))
+	assert.Equal(t, "br", negotiateEncoding("*, zstd;q=0", supported))
+	assert.Empty(t, negotiateEncoding("deflate, identity", supported))
+	assert.Empty(t, negotiateEncoding("", supported))
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	router := setupCompressionRouter(defaultCompression())
+
+	for _, encoding := range []string{"gzip", "br", "zstd"} {
+		t.Run(encoding, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/large", nil)
+			req.Header.Set("Accept-Encoding", encoding)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, encoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			assert.Equal(t, `W/"v1"`, w.Header().Get("ETag"))
+			assert.Less(t, w.Body.Len(), len(largeBody))
+			assert.Equal(t, largeBody, decode(t, encoding, w.Body.Bytes()))
+		})
+	}
+}
+
+func TestCompressionMiddlewareLeavesResponsesAsIs(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            config.CompressionConfig
+		path           string
+		acceptEncoding string
+		vary           string
+	}{
+		{name: "Below minimum size", cfg: defaultCompression(), path: "/small", acceptEncoding: "gzip", vary: "Accept-Encoding"},
+		{name: "No acceptable coding", cfg: defaultCompression(), path: "/large", acceptEncoding: "deflate", vary: "Accept-Encoding"},
+		{name: "Already compressed type", cfg: defaultCompression(), path: "/image", acceptEncoding: "gzip"},
+		{name: "Disabled", cfg: config.CompressionConfig{}, path: "/large", acceptEncoding: "gzip"},
+		{name: "Route off", cfg: func() config.CompressionConfig {
+			cfg := defaultCompression()
+			cfg.Routes = "/large=off"
+			return cfg
+		}(), path: "/large", acceptEncoding: "gzip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupCompressionRouter(tt.cfg)
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, tt.vary, w.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestCompressionMiddlewareRouteMinimumSize(t *testing.T) {
+	cfg := defaultCompression()
+	cfg.Routes = "/small=0"
+	router := setupCompressionRouter(cfg)
+
+	req := httptest.NewRequest(http.MethodGet, "/small", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "ok", decode(t, "gzip", w.Body.Bytes()))
+}
+
+func TestDecompressionMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	limits := BodyLimits{Default: 1024, Routes: map[string]int64{"/small": 10}}
+	router.Use(BodyLimitMiddleware(limits))
+	router.Use(DecompressionMiddleware(limits))
+	echo := func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			RespondWithPayloadTooLarge(c, sharedErrors.Text{}, []sharedErrors.Text{sharedErrors.Raw(err.Error())})
+			return
+		}
+		c.String(http.StatusOK, string(body))
+	}
+	router.POST("/echo", echo)
+	router.POST("/small", echo)
+
+	gzipped := func(s string) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, _ = gz.Write([]byte(s))
+		_ = gz.Close()
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		encoding       string
+		body           []byte
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "Gzip body", path: "/echo", encoding: "gzip", body: gzipped(`{"query":"{ address }"}`), expectedStatus: http.StatusOK, expectedBody: `{"query":"{ address }"}`},
+		{name: "Plain body", path: "/echo", body: []byte("plain"), expectedStatus: http.StatusOK, expectedBody: "plain"},
+		{name: "Inflated body over route limit", path: "/small", encoding: "gzip", body: gzipped(strings.Repeat("a", 100)), expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "Invalid gzip", path: "/echo", encoding: "gzip", body: []byte("not gzip"), expectedStatus: http.StatusBadRequest},
+		{name: "Unsupported coding", path: "/echo", encoding: "br", body: []byte("x"), expectedStatus: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewReader(tt.body))
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCompressionMiddlewareRecoversPanics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.LogConfig{Level: "error", Format: "json"}
+	log := logger.NewWithWriter(io.Discard, cfg, logger.NewLevel(&config.Config{Log: cfg}))
+	router := gin.New()
+	router.Use(ErrorHandlerMiddleware(log))
+	router.Use(CompressionMiddleware(defaultCompression()))
+	router.GET("/held", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+	router.GET("/sent", func(c *gin.Context) {
+		c.String(http.StatusOK, largeBody)
+		panic("boom")
+	})
+
+	t.Run("Held back body is dropped", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/held", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "Internal server error")
+		assert.NotContains(t, w.Body.String(), "partial")
+	})
+
+	t.Run("Sent body is closed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/sent", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, largeBody, decode(t, "gzip", w.Body.Bytes()))
+	})
+}
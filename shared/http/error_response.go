@@ -4,74 +4,140 @@ import (
 	"net/http"
 
 	"github.com/gerps2/desafio-cloud-run/shared/errors"
+	"github.com/gerps2/desafio-cloud-run/shared/i18n"
+	"github.com/gerps2/desafio-cloud-run/shared/requestid"
 	"github.com/gin-gonic/gin"
 )
 
 type APIResponse struct {
-	Data    interface{} `json:"data"`
-	Message string      `json:"message"`
-	Causes  []string    `json:"causes,omitempty"`
+	Data      interface{} `json:"data"`
+	Message   string      `json:"message"`
+	Causes    []string    `json:"causes,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
 }
 
+// Messages the helpers fall back to when called without one.
+var (
+	msgRequestTimeout       = errors.NewMessage("request.timeout", "Request timeout exceeded")
+	msgTooManyRequests      = errors.NewMessage("rate_limit.too_many_requests", "Too many requests")
+	msgRequestTooLarge      = errors.NewMessage("request.too_large", "Request body too large")
+	msgNotAcceptable        = errors.NewMessage("format.not_acceptable", "Requested response format is not supported")
+	msgEncodingNotSupported = errors.NewMessage("encoding.not_supported", "Request body encoding is not supported")
+	msgVersionNotSupported  = errors.NewMessage("version.not_supported", "Requested API version is not supported")
+)
+
 func RespondWithSuccess(c *gin.Context, data interface{}, message string) {
 	response := APIResponse{
 		Data:    data,
 		Message: message,
 		Causes:  nil,
 	}
-	c.JSON(http.StatusOK, response)
+	respond(c, http.StatusOK, response)
 }
 
 func RespondWithAPIError(c *gin.Context, apiError *errors.APIError) {
+	apiError = i18n.LocalizeError(Locale(c), apiError)
 	response := APIResponse{
-		Data:    nil,
-		Message: apiError.Message,
-		Causes:  apiError.Causes,
+		Data:      nil,
+		Message:   apiError.Message,
+		Causes:    apiError.Causes,
+		RequestID: requestid.FromContext(c.Request.Context()),
 	}
-	c.JSON(apiError.StatusCode, response)
+	respond(c, apiError.StatusCode, response)
 }
 
 func RespondWithError(c *gin.Context, statusCode int, message string, causes []string) {
 	response := APIResponse{
-		Data:    nil,
-		Message: message,
-		Causes:  causes,
+		Data:      nil,
+		Message:   message,
+		Causes:    causes,
+		RequestID: requestid.FromContext(c.Request.Context()),
 	}
-	c.JSON(statusCode, response)
+	respond(c, statusCode, response)
 }
 
-func RespondWithValidationError(c *gin.Context, message string, causes []string) {
+func RespondWithValidationError(c *gin.Context, message errors.Text, causes []errors.Text) {
 	apiError := errors.NewValidationError(message, causes)
 	RespondWithAPIError(c, apiError)
 }
 
-func RespondWithBusinessError(c *gin.Context, code, message string, causes []string) {
+func RespondWithBusinessError(c *gin.Context, code string, message errors.Text, causes []errors.Text) {
 	apiError := errors.NewBusinessError(code, message, causes)
 	RespondWithAPIError(c, apiError)
 }
 
-func RespondWithNotFound(c *gin.Context, message string, causes []string) {
+func RespondWithNotFound(c *gin.Context, message errors.Text, causes []errors.Text) {
 	apiError := errors.NewNotFoundError(message, causes)
 	RespondWithAPIError(c, apiError)
 }
 
-func RespondWithInternalError(c *gin.Context, message string, causes []string) {
-	if message == "" {
-		message = "Internal server error occurred"
+func RespondWithInternalError(c *gin.Context, message errors.Text, causes []errors.Text) {
+	if message.String() == "" {
+		message = errors.MsgInternalError.Format()
 	}
 	apiError := errors.NewInternalError(message, causes)
 	RespondWithAPIError(c, apiError)
 }
 
-func RespondWithExternalServiceError(c *gin.Context, message string, causes []string) {
+func RespondWithExternalServiceError(c *gin.Context, message errors.Text, causes []errors.Text) {
 	apiError := errors.NewExternalServiceError(message, causes)
 	RespondWithAPIError(c, apiError)
 }
 
-func RespondWithTimeout(c *gin.Context, message string, causes []string) {
-	if message == "" {
-		message = "Request timeout exceeded"
+func RespondWithTimeout(c *gin.Context, message errors.Text, causes []errors.Text) {
+	if message.String() == "" {
+		message = msgRequestTimeout.Format()
 	}
 	apiError := errors.NewTimeoutError(message, causes)
 	RespondWithAPIError(c, apiError)
 }
+
+func RespondWithUnauthorized(c *gin.Context, message errors.Text, causes []errors.Text) {
+	apiError := errors.NewUnauthorizedError(message, causes)
+	RespondWithAPIError(c, apiError)
+}
+
+func RespondWithForbidden(c *gin.Context, message errors.Text, causes []errors.Text) {
+	apiError := errors.NewForbiddenError(message, causes)
+	RespondWithAPIError(c, apiError)
+}
+
+func RespondWithTooManyRequests(c *gin.Context, message errors.Text, causes []errors.Text) {
+	if message.String() == "" {
+		message = msgTooManyRequests.Format()
+	}
+	apiError := errors.NewTooManyRequestsError(message, causes)
+	RespondWithAPIError(c, apiError)
+}
+
+func RespondWithPayloadTooLarge(c *gin.Context, message errors.Text, causes []errors.Text) {
+	if message.String() == "" {
+		message = msgRequestTooLarge.Format()
+	}
+	apiError := errors.NewPayloadTooLargeError(message, causes)
+	RespondWithAPIError(c, apiError)
+}
+
+func RespondWithNotAcceptable(c *gin.Context, message errors.Text, causes []errors.Text) {
+	if message.String() == "" {
+		message = msgNotAcceptable.Format()
+	}
+	apiError := errors.NewNotAcceptableError(message, causes)
+	RespondWithAPIError(c, apiError)
+}
+
+func RespondWithUnsupportedMediaType(c *gin.Context, message errors.Text, causes []errors.Text) {
+	if message.String() == "" {
+		message = msgEncodingNotSupported.Format()
+	}
+	apiError := errors.NewUnsupportedMediaTypeError(message, causes)
+	RespondWithAPIError(c, apiError)
+}
+
+func RespondWithUnsupportedAPIVersion(c *gin.Context, message errors.Text, causes []errors.Text) {
+	if message.String() == "" {
+		message = msgVersionNotSupported.Format()
+	}
+	apiError := errors.NewUnsupportedAPIVersionError(message, causes)
+	RespondWithAPIError(c, apiError)
+}
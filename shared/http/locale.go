@@ -0,0 +1,26 @@
+package http
+
+import (
+	"github.com/gerps2/desafio-cloud-run/shared/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleMiddleware picks the catalog for the request from Accept-Language and
+// keeps it in the request context, where error responses and weather
+// conditions are translated from.
+func LocaleMiddleware() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		locale := i18n.Match(c.GetHeader("Accept-Language"))
+		c.Request = c.Request.WithContext(i18n.NewContext(c.Request.Context(), locale))
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Header("Content-Language", locale)
+
+		c.Next()
+	})
+}
+
+// Locale returns the locale LocaleMiddleware picked, or i18n.Default.
+func Locale(c *gin.Context) string {
+	return i18n.FromContext(c.Request.Context())
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupLocaleRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(LocaleMiddleware())
+	router.GET("/test", func(c *gin.Context) {
+		RespondWithPayloadTooLarge(c, sharedErrors.Text{}, []sharedErrors.Text{msgBodyLimit.Format(30), sharedErrors.Raw("upstream said no")})
+	})
+	return router
+}
+
+func TestLocaleMiddlewareTranslatesErrors(t *testing.T) {
+	tests := []struct {
+		name            string
+		acceptLanguage  string
+		contentLanguage string
+		expected        string
+	}{
+		{name: "Default", contentLanguage: "en",
+			expected: `{"data":null,"message":"Request body too large","causes":["Request body must not exceed 30 bytes","upstream said no"]}`},
+		{name: "Portuguese", acceptLanguage: "pt-BR,pt;q=0.9", contentLanguage: "pt-BR",
+			expected: `{"data":null,"message":"Corpo da requisição grande demais","causes":["O corpo da requisição não pode passar de 30 bytes","upstream said no"]}`},
+		{name: "Spanish", acceptLanguage: "es-MX", contentLanguage: "es",
+			expected: `{"data":null,"message":"Cuerpo de la solicitud demasiado grande","causes":["El cuerpo de la solicitud no debe superar 30 bytes","upstream said no"]}`},
+		{name: "Unsupported language", acceptLanguage: "fr", contentLanguage: "en",
+			expected: `{"data":null,"message":"Request body too large","causes":["Request body must not exceed 30 bytes","upstream said no"]}`},
+	}
+
+	router := setupLocaleRouter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+			assert.Equal(t, tt.contentLanguage, w.Header().Get("Content-Language"))
+			assert.Equal(t, []string{"Accept-Language", "Accept"}, w.Header().Values("Vary"))
+			assert.JSONEq(t, tt.expected, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that did not match any route, keeping
+// arbitrary paths out of the metric labels.
+const unmatchedRoute = "unmatched"
+
+func MetricsMiddleware(m *metrics.Metrics) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.ObserveHTTPRequest(route, c.Request.Method, c.Writer.Status(), time.Since(start))
+	})
+}
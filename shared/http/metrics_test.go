@@ -0,0 +1,63 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddlewareRecordsRouteAndStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := metrics.New()
+
+	router := gin.New()
+	router.Use(MetricsMiddleware(m))
+	router.GET("/api/v1/weather/:cep", func(c *gin.Context) {
+		if c.Param("cep") == "00000000" {
+			c.Status(http.StatusBadGateway)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/api/v1/weather/01001000", "/api/v1/weather/00000000", "/does-not-exist"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	expected := `
+# HELP weather_api_http_requests_total HTTP requests handled, by route, method and status code.
+# TYPE weather_api_http_requests_total counter
+weather_api_http_requests_total{method="GET",route="/api/v1/weather/:cep",status="200"} 1
+weather_api_http_requests_total{method="GET",route="/api/v1/weather/:cep",status="502"} 1
+weather_api_http_requests_total{method="GET",route="unmatched",status="404"} 1
+# HELP weather_api_http_request_errors_total HTTP requests answered with a 5xx status code.
+# TYPE weather_api_http_request_errors_total counter
+weather_api_http_request_errors_total{method="GET",route="/api/v1/weather/:cep",status="502"} 1
+`
+	err := testutil.GatherAndCompare(m.Registry(), strings.NewReader(expected),
+		"weather_api_http_requests_total", "weather_api_http_request_errors_total")
+	assert.NoError(t, err)
+
+	count, err := testutil.GatherAndCount(m.Registry(), "weather_api_http_request_duration_seconds")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
+
+func TestMetricsHandlerExposesRegistry(t *testing.T) {
+	m := metrics.New()
+	m.ObserveUpstreamCall(metrics.UpstreamViaCep, metrics.OutcomeSuccess, 0)
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `weather_api_upstream_requests_total{outcome="success",upstream="viacep"} 1`)
+	assert.Contains(t, w.Body.String(), "go_goroutines")
+}
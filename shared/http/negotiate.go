@@ -0,0 +1,365 @@
+package http
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	apiv1 "github.com/gerps2/desafio-cloud-run/gen/api/v1"
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	FormatJSON     = "json"
+	FormatXML      = "xml"
+	FormatCSV      = "csv"
+	FormatMsgPack  = "msgpack"
+	FormatProtobuf = "protobuf"
+
+	// FormatQueryParam overrides the Accept header, e.g. ?format=xml.
+	FormatQueryParam = "format"
+
+	formatKey = "response_format"
+)
+
+type responseFormat struct {
+	name        string
+	contentType string
+	mediaTypes  []string
+	encode      func(APIResponse) ([]byte, error)
+}
+
+var (
+	msgFormatUnsupported    = sharedErrors.NewMessage("format.unsupported", "unsupported format %q")
+	msgFormatNoneProducible = sharedErrors.NewMessage("format.none_producible", "none of %q can be produced")
+	msgFormatsSupported     = sharedErrors.NewMessage("format.supported", "supported: %s")
+)
+
+// formats are listed in order of preference for wildcard Accept ranges.
+var formats = []responseFormat{
+	{name: FormatJSON, contentType: "application/json; charset=utf-8", mediaTypes: []string{"application/json"}, encode: encodeJSON},
+	{name: FormatXML, contentType: "application/xml; charset=utf-8", mediaTypes: []string{"application/xml", "text/xml"}, encode: encodeXML},
+	{name: FormatCSV, contentType: "text/csv; charset=utf-8", mediaTypes: []string{"text/csv"}, encode: encodeCSV},
+	{name: FormatMsgPack, contentType: "application/msgpack", mediaTypes: []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}, encode: encodeMsgPack},
+	{name: FormatProtobuf, contentType: "application/x-protobuf", mediaTypes: []string{"application/x-protobuf", "application/protobuf", "application/vnd.google.protobuf"}, encode: encodeProtobuf},
+}
+
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// NegotiateFormat picks the response format from the format query parameter,
+// else from the Accept header. A missing Accept header means JSON.
+func NegotiateFormat(c *gin.Context) (string, error) {
+	if name := strings.ToLower(strings.TrimSpace(c.Query(FormatQueryParam))); name != "" {
+		for _, f := range formats {
+			if f.name == name {
+				return f.name, nil
+			}
+		}
+		return "", negotiationError{msgFormatUnsupported.Format(name)}
+	}
+
+	accept := c.GetHeader("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return FormatJSON, nil
+	}
+
+	ranges := parseAccept(accept)
+	for _, r := range ranges {
+		if r.q <= 0 {
+			continue
+		}
+		for _, f := range formats {
+			for _, mediaType := range f.mediaTypes {
+				if matchesMediaRange(r.mediaType, mediaType) && !excluded(ranges, mediaType) {
+					return f.name, nil
+				}
+			}
+		}
+	}
+	return "", negotiationError{msgFormatNoneProducible.Format(accept)}
+}
+
+// negotiationError keeps the reason a format cannot be served as a Text, so
+// NegotiateMiddleware can translate it.
+type negotiationError struct {
+	cause sharedErrors.Text
+}
+
+func (e negotiationError) Error() string {
+	return e.cause.String()
+}
+
+// NegotiateMiddleware rejects requests whose Accept header or format
+// parameter cannot be served with 406 before the handler runs.
+func NegotiateMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format, err := NegotiateFormat(c)
+		if err != nil {
+			c.Set(formatKey, FormatJSON)
+			cause := sharedErrors.Raw(err.Error())
+			var negotiationErr negotiationError
+			if errors.As(err, &negotiationErr) {
+				cause = negotiationErr.cause
+			}
+			RespondWithNotAcceptable(c, sharedErrors.Text{}, []sharedErrors.Text{
+				cause,
+				msgFormatsSupported.Format(strings.Join(SupportedMediaTypes(), ", ")),
+			})
+			c.Abort()
+			return
+		}
+		c.Set(formatKey, format)
+		c.Next()
+	}
+}
+
+// SupportedMediaTypes lists the primary media type of every format.
+func SupportedMediaTypes() []string {
+	mediaTypes := make([]string, 0, len(formats))
+	for _, f := range formats {
+		mediaTypes = append(mediaTypes, f.mediaTypes[0])
+	}
+	return mediaTypes
+}
+
+// respond writes the envelope in the negotiated format. Routes without
+// NegotiateMiddleware negotiate here and fall back to JSON, so an error
+// raised before negotiation is never replaced by a 406.
+func respond(c *gin.Context, statusCode int, response APIResponse) {
+	format := formatFor(c)
+	c.Writer.Header().Add("Vary", "Accept")
+
+	body, err := format.encode(response)
+	if err != nil {
+		_ = c.Error(err)
+		c.JSON(http.StatusInternalServerError, APIResponse{
+			Message:   "Internal server error occurred",
+			Causes:    []string{fmt.Sprintf("response could not be encoded as %s", format.name)},
+			RequestID: response.RequestID,
+		})
+		return
+	}
+	c.Data(statusCode, format.contentType, body)
+}
+
+func formatFor(c *gin.Context) responseFormat {
+	name := c.GetString(formatKey)
+	if name == "" {
+		name, _ = NegotiateFormat(c)
+	}
+	for _, f := range formats {
+		if f.name == name {
+			return f
+		}
+	}
+	return formats[0]
+}
+
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(fields[0]))
+		if mediaType == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(key, "q") {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+	return ranges
+}
+
+func matchesMediaRange(mediaRange, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(mediaRange, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
+
+// excluded reports whether the client refused mediaType explicitly with q=0.
+func excluded(ranges []mediaRange, mediaType string) bool {
+	for _, r := range ranges {
+		if r.q <= 0 && r.mediaType == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeJSON(response APIResponse) ([]byte, error) {
+	return json.Marshal(response)
+}
+
+// xmlResponse is the XML form of APIResponse; list payloads repeat <data>.
+type xmlResponse struct {
+	XMLName   xml.Name    `xml:"response"`
+	Data      interface{} `xml:"data,omitempty"`
+	Message   string      `xml:"message"`
+	Causes    *xmlCauses  `xml:"causes,omitempty"`
+	RequestID string      `xml:"request_id,omitempty"`
+}
+
+type xmlCauses struct {
+	Cause []string `xml:"cause"`
+}
+
+func encodeXML(response APIResponse) ([]byte, error) {
+	document := xmlResponse{Data: response.Data, Message: response.Message, RequestID: response.RequestID}
+	if len(response.Causes) > 0 {
+		document.Causes = &xmlCauses{Cause: response.Causes}
+	}
+	body, err := xml.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+func encodeMsgPack(response APIResponse) ([]byte, error) {
+	var body []byte
+	handle := &codec.MsgpackHandle{WriteExt: true}
+	err := codec.NewEncoderBytes(&body, handle).Encode(response)
+	return body, err
+}
+
+func encodeProtobuf(response APIResponse) ([]byte, error) {
+	message := &apiv1.Response{
+		Message:   response.Message,
+		Causes:    response.Causes,
+		RequestId: response.RequestID,
+	}
+	if response.Data != nil {
+		data, err := genericJSON(response.Data)
+		if err != nil {
+			return nil, err
+		}
+		if message.Data, err = structpb.NewValue(data); err != nil {
+			return nil, err
+		}
+	}
+	return proto.Marshal(message)
+}
+
+// encodeCSV writes one row per element of a list payload, or a single row
+// otherwise. Nested objects become dotted columns. Error responses, which
+// carry no data, are written as message, causes and request_id.
+func encodeCSV(response APIResponse) ([]byte, error) {
+	var rows []map[string]string
+	if response.Data == nil {
+		rows = append(rows, map[string]string{
+			"message":    response.Message,
+			"causes":     strings.Join(response.Causes, "; "),
+			"request_id": response.RequestID,
+		})
+	} else {
+		data, err := genericJSON(response.Data)
+		if err != nil {
+			return nil, err
+		}
+		items, ok := data.([]interface{})
+		if !ok {
+			items = []interface{}{data}
+		}
+		for _, item := range items {
+			row := map[string]string{}
+			flattenCSV(row, "", item)
+			rows = append(rows, row)
+		}
+	}
+
+	columns := map[string]bool{}
+	for _, row := range rows {
+		for column := range row {
+			columns[column] = true
+		}
+	}
+	header := make([]string, 0, len(columns))
+	for column := range columns {
+		header = append(header, column)
+	}
+	sort.Strings(header)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		record := make([]string, len(header))
+		for i, column := range header {
+			record[i] = row[column]
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func flattenCSV(row map[string]string, prefix string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenCSV(row, key, nested)
+		}
+	case []interface{}:
+		encoded, _ := json.Marshal(v)
+		row[csvColumn(prefix)] = string(encoded)
+	case nil:
+		row[csvColumn(prefix)] = ""
+	default:
+		row[csvColumn(prefix)] = fmt.Sprint(v)
+	}
+}
+
+func csvColumn(prefix string) string {
+	if prefix == "" {
+		return "value"
+	}
+	return prefix
+}
+
+// genericJSON converts data to its JSON shape, so every encoder names
+// fields the way the JSON responses do.
+func genericJSON(data interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
@@ -0,0 +1,183 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiv1 "github.com/gerps2/desafio-cloud-run/gen/api/v1"
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+)
+
+type negotiatedReading struct {
+	City  string  `json:"city" xml:"city"`
+	TempC float64 `json:"temp_C" xml:"temp_C"`
+}
+
+func setupNegotiateRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/test", NegotiateMiddleware(), handler)
+	router.GET("/plain", handler)
+	return router
+}
+
+func serveNegotiated(router *gin.Engine, target, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		accept   string
+		expected string
+	}{
+		{name: "No Accept", target: "/", expected: FormatJSON},
+		{name: "Any", target: "/", accept: "*/*", expected: FormatJSON},
+		{name: "XML", target: "/", accept: "text/xml", expected: FormatXML},
+		{name: "Quality order", target: "/", accept: "application/json;q=0.5, text/csv", expected: FormatCSV},
+		{name: "Type wildcard", target: "/", accept: "application/*", expected: FormatJSON},
+		{name: "Refused JSON", target: "/", accept: "application/json;q=0, */*", expected: FormatXML},
+		{name: "MessagePack", target: "/", accept: "application/x-msgpack", expected: FormatMsgPack},
+		{name: "Protobuf", target: "/", accept: "application/x-protobuf", expected: FormatProtobuf},
+		{name: "Query overrides Accept", target: "/?format=CSV", accept: "application/json", expected: FormatCSV},
+		{name: "Unsupported", target: "/", accept: "text/html"},
+		{name: "Unsupported query", target: "/?format=yaml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, tt.target, nil)
+			c.Request.Header.Set("Accept", tt.accept)
+
+			format, err := NegotiateFormat(c)
+
+			if tt.expected == "" {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, format)
+		})
+	}
+}
+
+func TestRespondWithSuccessEncodesNegotiatedFormat(t *testing.T) {
+	router := setupNegotiateRouter(func(c *gin.Context) {
+		RespondWithSuccess(c, []negotiatedReading{{City: "São Paulo", TempC: 21.5}, {City: "Recife", TempC: 30}}, "ok")
+	})
+
+	t.Run("XML", func(t *testing.T) {
+		w := serveNegotiated(router, "/test", "application/xml")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "Accept", w.Header().Get("Vary"))
+		assert.Contains(t, w.Body.String(), "<response><data><city>São Paulo</city><temp_C>21.5</temp_C></data>")
+		assert.Contains(t, w.Body.String(), "<message>ok</message></response>")
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		w := serveNegotiated(router, "/test?format=csv", "")
+
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "city,temp_C\nSão Paulo,21.5\nRecife,30\n", w.Body.String())
+	})
+
+	t.Run("MessagePack", func(t *testing.T) {
+		w := serveNegotiated(router, "/test", "application/msgpack")
+
+		handle := &codec.MsgpackHandle{}
+		handle.RawToString = true
+		var decoded map[string]interface{}
+		require.NoError(t, codec.NewDecoderBytes(w.Body.Bytes(), handle).Decode(&decoded))
+		assert.Equal(t, "ok", decoded["message"])
+		assert.Len(t, decoded["data"], 2)
+	})
+
+	t.Run("Protobuf", func(t *testing.T) {
+		w := serveNegotiated(router, "/test", "application/x-protobuf")
+
+		var decoded apiv1.Response
+		require.NoError(t, proto.Unmarshal(w.Body.Bytes(), &decoded))
+		assert.Equal(t, "ok", decoded.GetMessage())
+		first := decoded.GetData().GetListValue().GetValues()[0].GetStructValue().GetFields()
+		assert.Equal(t, "São Paulo", first["city"].GetStringValue())
+		assert.Equal(t, 21.5, first["temp_C"].GetNumberValue())
+	})
+}
+
+func TestRespondWithAPIErrorEncodesNegotiatedFormat(t *testing.T) {
+	router := setupNegotiateRouter(func(c *gin.Context) {
+		RespondWithNotFound(c, sharedErrors.Raw("zipcode not found"), []sharedErrors.Text{sharedErrors.Raw("cep 01001000")})
+	})
+
+	t.Run("XML", func(t *testing.T) {
+		w := serveNegotiated(router, "/test", "application/xml")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "<response><message>zipcode not found</message><causes><cause>cep 01001000</cause></causes></response>")
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		w := serveNegotiated(router, "/test", "text/csv")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "causes,message,request_id\ncep 01001000,zipcode not found,\n", w.Body.String())
+	})
+}
+
+func TestNegotiateMiddlewareRejectsUnsupportedFormat(t *testing.T) {
+	called := false
+	router := setupNegotiateRouter(func(c *gin.Context) {
+		called = true
+		RespondWithSuccess(c, nil, "ok")
+	})
+
+	w := serveNegotiated(router, "/test", "text/html")
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	var response APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Requested response format is not supported", response.Message)
+	assert.Contains(t, response.Causes, "supported: application/json, application/xml, text/csv, application/msgpack, application/x-protobuf")
+}
+
+func TestRespondFallsBackToJSONWithoutMiddleware(t *testing.T) {
+	router := setupNegotiateRouter(func(c *gin.Context) {
+		RespondWithUnauthorized(c, sharedErrors.Raw("missing credentials"), nil)
+	})
+
+	w := serveNegotiated(router, "/plain", "text/html")
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+}
+
+func TestRespondReportsUnencodableData(t *testing.T) {
+	router := setupNegotiateRouter(func(c *gin.Context) {
+		RespondWithSuccess(c, map[string]int{"a": 1}, "ok")
+	})
+
+	w := serveNegotiated(router, "/test", "application/xml")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "response could not be encoded as xml")
+}
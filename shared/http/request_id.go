@@ -0,0 +1,27 @@
+package http
+
+import (
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDMiddleware reuses the caller's X-Request-ID when it is valid or
+// generates a new one, making it available to handlers, logs, error bodies
+// and upstream calls through the request context.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.IsValid(id) {
+			id = requestid.New()
+		}
+
+		ctx := requestid.NewContext(c.Request.Context(), id)
+		ctx = logger.ContextWithFields(ctx, "request_id", id)
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(requestid.Header, id)
+
+		c.Next()
+	})
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+	"github.com/gerps2/desafio-cloud-run/shared/requestid"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRequestIDRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.GET("/test", handler)
+	return router
+}
+
+func TestRequestIDMiddlewareGeneratesID(t *testing.T) {
+	var seen string
+	router := setupRequestIDRouter(func(c *gin.Context) {
+		seen = requestid.FromContext(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Len(t, seen, 32)
+	assert.Equal(t, seen, w.Header().Get(requestid.Header))
+}
+
+func TestRequestIDMiddlewareReusesValidID(t *testing.T) {
+	var seen string
+	router := setupRequestIDRouter(func(c *gin.Context) {
+		seen = requestid.FromContext(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(requestid.Header, "client-id-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "client-id-123", seen)
+	assert.Equal(t, "client-id-123", w.Header().Get(requestid.Header))
+}
+
+func TestRequestIDMiddlewareReplacesInvalidID(t *testing.T) {
+	router := setupRequestIDRouter(func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(requestid.Header, "bad id\twith spaces")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.NotEqual(t, "bad id\twith spaces", w.Header().Get(requestid.Header))
+	assert.True(t, requestid.IsValid(w.Header().Get(requestid.Header)))
+}
+
+func TestRequestIDIncludedInErrorBody(t *testing.T) {
+	router := setupRequestIDRouter(func(c *gin.Context) {
+		RespondWithNotFound(c, sharedErrors.Raw("can not find zipcode"), nil)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(requestid.Header, "abc-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "abc-123", response.RequestID)
+}
@@ -7,9 +7,22 @@ import (
 	"time"
 
 	"github.com/gerps2/desafio-cloud-run/shared/config"
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
 	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+	"github.com/gerps2/desafio-cloud-run/shared/telemetry"
 
 	"github.com/gin-gonic/gin"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+var (
+	msgTimeoutExceeded = sharedErrors.NewMessage("request.timeout_exceeded", "Request exceeded the configured timeout")
+	msgBodyLimit       = sharedErrors.NewMessage("request.body_limit", "Request body must not exceed %d bytes")
+	msgPanic           = sharedErrors.NewMessage("internal.panic", "Internal server error")
+	msgUnexpectedError = sharedErrors.NewMessage("internal.unexpected", "An unexpected error occurred in the application")
+	msgRequestFailed   = sharedErrors.NewMessage("internal.request_failed", "An error occurred while processing the request")
 )
 
 type Server struct {
@@ -19,88 +32,192 @@ type Server struct {
 	logger logger.Logger
 }
 
+// TimeoutMiddleware bounds every request except long-lived streams: WebSocket
+// upgrades and requests accepting text/event-stream.
 func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
+		if IsStreamRequest(c) {
+			c.Next()
+			return
+		}
+
 		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 		defer cancel()
 
 		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 
-		if ctx.Err() == context.DeadlineExceeded {
-			RespondWithTimeout(c, "", []string{"Request exceeded the configured timeout"})
+		if ctx.Err() == context.DeadlineExceeded && !c.Writer.Written() {
+			RespondWithTimeout(c, sharedErrors.Text{}, []sharedErrors.Text{msgTimeoutExceeded.Format()})
+			c.Abort()
+		}
+	})
+}
+
+// Stream routes keep their connection open while the client listens.
+const (
+	StreamRoute = "/api/v1/weather/:cep/stream"
+	SocketRoute = "/api/v1/weather/ws"
+)
+
+// IsStreamRequest reports whether the request matched a stream route, which
+// skips the request timeout, cache and compression. It goes by the route,
+// not by client headers, so callers cannot lift the timeout elsewhere.
+func IsStreamRequest(c *gin.Context) bool {
+	switch c.FullPath() {
+	case StreamRoute, SocketRoute:
+		return true
+	}
+	return false
+}
+
+// BodyLimitMiddleware answers 413 when the declared body size exceeds the
+// route limit; bodies without a declared size fail on read past the limit.
+func BodyLimitMiddleware(limits BodyLimits) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		maxBytes := limits.For(c.FullPath())
+		if maxBytes <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			RespondWithPayloadTooLarge(c, sharedErrors.Text{}, []sharedErrors.Text{msgBodyLimit.Format(maxBytes)})
 			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	})
+}
+
+func LoggerContextMiddleware() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
 		}
+
+		ctx := logger.ContextWithFields(c.Request.Context(), "method", c.Request.Method, "route", route)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
 	})
 }
 
-func ErrorHandlerMiddleware(logger logger.Logger) gin.HandlerFunc {
+func ErrorHandlerMiddleware(log logger.Logger) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				logger.Error("Panic recovered: %v", err)
-				
+				log.WithContext(c.Request.Context()).Error("Panic recovered: %v", err)
+
 				if !c.Writer.Written() {
-					causes := []string{"An unexpected error occurred in the application"}
-					RespondWithInternalError(c, "Internal server error", causes)
+					causes := []sharedErrors.Text{msgUnexpectedError.Format()}
+					RespondWithInternalError(c, msgPanic.Format(), causes)
 				}
-				
+
 				c.Abort()
 			}
 		}()
-		
+
 		c.Next()
-		
+
 		if len(c.Errors) > 0 {
 			if !c.Writer.Written() {
 				lastError := c.Errors.Last()
-				logger.Error("Request error: %v", lastError.Error())
-				
-				causes := []string{lastError.Error()}
-				RespondWithInternalError(c, "An error occurred while processing the request", causes)
+				log.WithContext(c.Request.Context()).Error("Request error: %v", lastError.Error())
+
+				causes := []sharedErrors.Text{sharedErrors.Raw(lastError.Error())}
+				RespondWithInternalError(c, msgRequestFailed.Format(), causes)
 			}
 		}
 	})
 }
 
-func NewServer(cfg *config.Config, log logger.Logger) *Server {
+func NewServer(cfg *config.Config, log logger.Logger, tel *telemetry.Telemetry, m *metrics.Metrics) *Server {
 	if cfg.App.Env == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	router := gin.New()
-	router.Use(gin.Logger())
-	
+	// Without trusted proxies the client IP is the peer address; forwarded
+	// headers are only honoured when they come from a listed proxy.
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Warn("Ignoring invalid SERVER_TRUSTED_PROXIES: %v", err)
+		_ = router.SetTrustedProxies(nil)
+	}
+	router.Use(RequestIDMiddleware())
+	router.Use(LocaleMiddleware())
+	router.Use(TraceContextMiddleware())
+	router.Use(TracingMiddleware(tel))
+	router.Use(LoggerContextMiddleware())
+	router.Use(AccessLogMiddleware(log, cfg.Auth.APIKey.QueryParam))
+	router.Use(MetricsMiddleware(m))
+
+	router.Use(CompressionMiddleware(cfg.Compression))
+
+	bodyLimits, err := NewBodyLimits(cfg.Server)
+	if err != nil {
+		log.Warn("Ignoring %v", err)
+	}
 	router.Use(ErrorHandlerMiddleware(log))
-	
+	router.Use(BodyLimitMiddleware(bodyLimits))
+	router.Use(DecompressionMiddleware(bodyLimits))
+
 	timeout := time.Duration(cfg.App.RequestTimeoutSec) * time.Second
 	router.Use(TimeoutMiddleware(timeout))
 
+	if write := cfg.Server.WriteTimeoutSec; write > 0 && cfg.App.RequestTimeoutSec >= write {
+		log.Warn("REQUEST_TIMEOUT_SEC (%ds) is not below SERVER_WRITE_TIMEOUT_SEC (%ds): slow requests will be dropped instead of answered with 504",
+			cfg.App.RequestTimeoutSec, write)
+	}
+
 	return &Server{
 		config: cfg,
 		logger: log,
 		router: router,
+		server: newHTTPServer(cfg.Server, router),
 	}
 }
 
+func newHTTPServer(cfg config.ServerConfig, router *gin.Engine) *http.Server {
+	handler := http.Handler(router)
+	if cfg.H2CEnabled {
+		handler = h2c.NewHandler(handler, &http2.Server{IdleTimeout: seconds(cfg.IdleTimeoutSec)})
+	}
+
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%s", cfg.Port),
+		Handler:           handler,
+		ReadTimeout:       seconds(cfg.ReadTimeoutSec),
+		ReadHeaderTimeout: seconds(cfg.ReadHeaderTimeoutSec),
+		WriteTimeout:      seconds(cfg.WriteTimeoutSec),
+		IdleTimeout:       seconds(cfg.IdleTimeoutSec),
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
 func (s *Server) GetRouter() *gin.Engine {
 	return s.router
 }
 
 func (s *Server) Start() error {
-	addr := fmt.Sprintf(":%s", s.config.Server.Port)
-	
-	s.server = &http.Server{
-		Addr:         addr,
-		Handler:      s.router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	cfg := s.config.Server
+
+	var err error
+	if cfg.TLSCertFile != "" {
+		s.logger.Info("Starting HTTPS server on %s", s.server.Addr)
+		err = s.server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+	} else {
+		s.logger.Info("Starting server on %s (h2c: %t)", s.server.Addr, cfg.H2CEnabled)
+		err = s.server.ListenAndServe()
 	}
 
-	s.logger.Info("Starting server on %s", addr)
-	
-	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("failed to start server: %w", err)
 	}
 
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+	"github.com/gerps2/desafio-cloud-run/shared/telemetry"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+)
+
+func newTestServer(t *testing.T, serverCfg config.ServerConfig, requestTimeoutSec int) (*Server, *bytes.Buffer) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	serverCfg.Port = "8080"
+	cfg := &config.Config{
+		Server: serverCfg,
+		App:    config.AppConfig{Env: config.EnvTest, RequestTimeoutSec: requestTimeoutSec},
+		Log:    config.LogConfig{Level: "info", Format: "json"},
+	}
+	var logs bytes.Buffer
+	log := logger.NewWithWriter(&logs, cfg.Log, logger.NewLevel(cfg))
+
+	return NewServer(cfg, log, telemetry.NewNoop(), metrics.New()), &logs
+}
+
+func TestNewServerAppliesServerConfig(t *testing.T) {
+	server, logs := newTestServer(t, config.ServerConfig{
+		ReadTimeoutSec:       5,
+		ReadHeaderTimeoutSec: 2,
+		WriteTimeoutSec:      40,
+		IdleTimeoutSec:       90,
+		MaxHeaderBytes:       4096,
+	}, 30)
+
+	assert.Equal(t, ":8080", server.server.Addr)
+	assert.Equal(t, 5*time.Second, server.server.ReadTimeout)
+	assert.Equal(t, 2*time.Second, server.server.ReadHeaderTimeout)
+	assert.Equal(t, 40*time.Second, server.server.WriteTimeout)
+	assert.Equal(t, 90*time.Second, server.server.IdleTimeout)
+	assert.Equal(t, 4096, server.server.MaxHeaderBytes)
+	assert.NotContains(t, logs.String(), "SERVER_WRITE_TIMEOUT_SEC")
+}
+
+func TestNewServerWarnsWhenRequestTimeoutExceedsWriteTimeout(t *testing.T) {
+	_, logs := newTestServer(t, config.ServerConfig{WriteTimeoutSec: 15}, 300)
+
+	assert.Contains(t, logs.String(), "REQUEST_TIMEOUT_SEC (300s) is not below SERVER_WRITE_TIMEOUT_SEC (15s)")
+}
+
+func TestH2CServesCleartextHTTP2(t *testing.T) {
+	server, _ := newTestServer(t, config.ServerConfig{H2CEnabled: true}, 30)
+	server.GetRouter().GET("/proto", func(c *gin.Context) {
+		c.String(http.StatusOK, c.Request.Proto)
+	})
+	ts := httptest.NewServer(server.server.Handler)
+	defer ts.Close()
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+	resp, err := client.Get(ts.URL + "/proto")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "HTTP/2.0", string(body))
+}
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(BodyLimitMiddleware(BodyLimits{Default: 10, Routes: map[string]int64{"/upload": 20}}))
+	router.POST("/echo", func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			RespondWithPayloadTooLarge(c, sharedErrors.Text{}, []sharedErrors.Text{sharedErrors.Raw(err.Error())})
+			return
+		}
+		c.String(http.StatusOK, string(body))
+	})
+	router.POST("/upload", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		contentLength  int64
+		expectedStatus int
+	}{
+		{name: "Within limit", path: "/echo", body: "0123456789", contentLength: 10, expectedStatus: http.StatusOK},
+		{name: "Declared length over limit", path: "/echo", body: "0123456789a", contentLength: 11, expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "Undeclared length over limit", path: "/echo", body: "0123456789a", contentLength: -1, expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "Within route limit", path: "/upload", body: "0123456789a", contentLength: 11, expectedStatus: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.ContentLength = tt.contentLength
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestTimeoutMiddlewareSkipsStreams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(TimeoutMiddleware(time.Millisecond))
+	handler := func(c *gin.Context) {
+		_, hasDeadline := c.Request.Context().Deadline()
+		c.String(http.StatusOK, "deadline=%t", hasDeadline)
+	}
+	router.GET(StreamRoute, handler)
+	router.GET(SocketRoute, handler)
+	router.GET("/api/v1/weather/:cep", handler)
+
+	tests := []struct {
+		name     string
+		path     string
+		header   http.Header
+		expected string
+	}{
+		{name: "Plain request", path: "/api/v1/weather/01001000", header: http.Header{}, expected: "deadline=true"},
+		{name: "Event stream", path: "/api/v1/weather/01001000/stream", header: http.Header{}, expected: "deadline=false"},
+		{name: "WebSocket", path: "/api/v1/weather/ws", header: http.Header{"Connection": {"Upgrade"}, "Upgrade": {"websocket"}}, expected: "deadline=false"},
+		{name: "Event stream Accept on another route", path: "/api/v1/weather/01001000",
+			header: http.Header{"Accept": {"text/event-stream"}}, expected: "deadline=true"},
+		{name: "WebSocket upgrade on another route", path: "/api/v1/weather/01001000",
+			header: http.Header{"Connection": {"Upgrade"}, "Upgrade": {"websocket"}}, expected: "deadline=true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header = tt.header
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HeaderTraceParent       = "traceparent"
+	HeaderCloudTraceContext = "X-Cloud-Trace-Context"
+)
+
+var (
+	traceParentPattern = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+	cloudTracePattern  = regexp.MustCompile(`^([0-9a-fA-F]{32})(?:/(\d+))?(?:;o=([01]))?$`)
+)
+
+// TraceContextMiddleware stores the incoming trace context in the request
+// context so every log line written for the request is grouped under the
+// same trace in Cloud Logging.
+func TraceContextMiddleware() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if trace, ok := ParseTraceHeaders(c.Request.Header); ok {
+			c.Request = c.Request.WithContext(logger.ContextWithTrace(c.Request.Context(), trace))
+		}
+		c.Next()
+	})
+}
+
+// ParseTraceHeaders reads the W3C traceparent header, falling back to the
+// legacy X-Cloud-Trace-Context header set by Google front ends.
+func ParseTraceHeaders(header http.Header) (logger.TraceContext, bool) {
+	if trace, ok := parseTraceParent(header.Get(HeaderTraceParent)); ok {
+		return trace, true
+	}
+	return parseCloudTraceContext(header.Get(HeaderCloudTraceContext))
+}
+
+func parseTraceParent(value string) (logger.TraceContext, bool) {
+	matches := traceParentPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if matches == nil || matches[1] == strings.Repeat("0", 32) {
+		return logger.TraceContext{}, false
+	}
+
+	flags, err := strconv.ParseUint(matches[3], 16, 8)
+	if err != nil {
+		return logger.TraceContext{}, false
+	}
+
+	return logger.TraceContext{
+		TraceID: matches[1],
+		SpanID:  matches[2],
+		Sampled: flags&0x01 == 0x01,
+	}, true
+}
+
+func parseCloudTraceContext(value string) (logger.TraceContext, bool) {
+	matches := cloudTracePattern.FindStringSubmatch(strings.TrimSpace(value))
+	if matches == nil {
+		return logger.TraceContext{}, false
+	}
+
+	trace := logger.TraceContext{
+		TraceID: strings.ToLower(matches[1]),
+		Sampled: matches[3] == "1",
+	}
+
+	// X-Cloud-Trace-Context carries the span ID as a decimal number while
+	// Cloud Logging expects the 16-character hexadecimal form.
+	if matches[2] != "" {
+		if spanID, err := strconv.ParseUint(matches[2], 10, 64); err == nil {
+			trace.SpanID = strconv.FormatUint(spanID, 16)
+			trace.SpanID = strings.Repeat("0", 16-len(trace.SpanID)) + trace.SpanID
+		}
+	}
+
+	return trace, true
+}
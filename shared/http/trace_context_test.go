@@ -0,0 +1,120 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected logger.TraceContext
+		ok       bool
+	}{
+		{
+			name:    "W3C traceparent",
+			headers: map[string]string{HeaderTraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			expected: logger.TraceContext{
+				TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:  "00f067aa0ba902b7",
+				Sampled: true,
+			},
+			ok: true,
+		},
+		{
+			name:    "X-Cloud-Trace-Context with decimal span",
+			headers: map[string]string{HeaderCloudTraceContext: "105445aa7843bc8bf206b12000100000/1;o=1"},
+			expected: logger.TraceContext{
+				TraceID: "105445aa7843bc8bf206b12000100000",
+				SpanID:  "0000000000000001",
+				Sampled: true,
+			},
+			ok: true,
+		},
+		{
+			name: "traceparent takes precedence",
+			headers: map[string]string{
+				HeaderTraceParent:       "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+				HeaderCloudTraceContext: "105445aa7843bc8bf206b12000100000/1;o=1",
+			},
+			expected: logger.TraceContext{
+				TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:  "00f067aa0ba902b7",
+				Sampled: false,
+			},
+			ok: true,
+		},
+		{
+			name:    "Invalid traceparent",
+			headers: map[string]string{HeaderTraceParent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+			ok:      false,
+		},
+		{
+			name:    "No headers",
+			headers: map[string]string{},
+			ok:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for key, value := range tt.headers {
+				header.Set(key, value)
+			}
+
+			trace, ok := ParseTraceHeaders(header)
+
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, trace)
+		})
+	}
+}
+
+func TestAccessLogMiddlewareCloudFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	cfg := &config.Config{Log: config.LogConfig{Level: "info", Format: logger.FormatCloud, ProjectID: "my-project"}}
+	log := logger.NewWithWriter(&buf, cfg.Log, logger.NewLevel(cfg))
+
+	router := gin.New()
+	router.Use(TraceContextMiddleware(), LoggerContextMiddleware(), AccessLogMiddleware(log, "api_key"))
+	router.GET("/api/v1/weather/:cep", func(c *gin.Context) {
+		c.String(http.StatusNotFound, "missing")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/weather/99999-999?api_key=secret&format=json", nil)
+	req.Header.Set(HeaderCloudTraceContext, "105445aa7843bc8bf206b12000100000/1;o=1")
+	req.Header.Set("User-Agent", "test-agent")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+
+	assert.Equal(t, "WARNING", entry["severity"])
+	assert.Equal(t, "GET /api/v1/weather/99999-999 404", entry["message"])
+	assert.Equal(t, "projects/my-project/traces/105445aa7843bc8bf206b12000100000", entry[logger.CloudTraceKey])
+	assert.Equal(t, "/api/v1/weather/:cep", entry["route"])
+
+	httpRequest, ok := entry["httpRequest"].(map[string]interface{})
+	require.True(t, ok, "Expected httpRequest object")
+	assert.Equal(t, "GET", httpRequest["requestMethod"])
+	assert.Equal(t, "/api/v1/weather/99999-999?api_key=REDACTED&format=json", httpRequest["requestUrl"])
+	assert.Equal(t, float64(http.StatusNotFound), httpRequest["status"])
+	assert.Equal(t, "7", httpRequest["responseSize"])
+	assert.Equal(t, "test-agent", httpRequest["userAgent"])
+	assert.Regexp(t, `^\d+\.\d{9}s$`, httpRequest["latency"])
+}
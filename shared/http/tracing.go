@@ -0,0 +1,62 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/telemetry"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/gerps2/desafio-cloud-run/shared/http"
+
+// TracingMiddleware starts a server span per request, continuing the trace
+// received in the W3C traceparent header. The span context replaces the one
+// parsed by TraceContextMiddleware so log lines point at this span.
+func TracingMiddleware(tel *telemetry.Telemetry) gin.HandlerFunc {
+	tracer := tel.Tracer(tracerName)
+
+	return gin.HandlerFunc(func(c *gin.Context) {
+		ctx := tel.Propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		spanName := fmt.Sprintf("%s %s", c.Request.Method, route)
+		if route == "" {
+			spanName = c.Request.Method
+		}
+
+		ctx, span := tracer.Start(ctx, spanName,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.UserAgentOriginal(c.Request.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		if sc := span.SpanContext(); sc.IsValid() {
+			ctx = logger.ContextWithTrace(ctx, logger.TraceContext{
+				TraceID: sc.TraceID().String(),
+				SpanID:  sc.SpanID().String(),
+				Sampled: sc.IsSampled(),
+			})
+		}
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	})
+}
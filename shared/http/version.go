@@ -0,0 +1,131 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	APIVersion1 = "v1"
+	APIVersion2 = "v2"
+
+	// VersionHeader selects the version on unversioned routes and echoes the
+	// version served on every versioned response, e.g. API-Version: v2.
+	VersionHeader = "API-Version"
+
+	versionKey = "api_version"
+)
+
+var (
+	msgVersionUnsupported = sharedErrors.NewMessage("version.unsupported", "API-Version %q is not supported, use %s or %s")
+	msgVersionConflict    = sharedErrors.NewMessage("version.conflict", "API-Version header %q conflicts with the %s path")
+)
+
+// APIVersions lists the versions served, oldest first.
+func APIVersions() []string {
+	return []string{APIVersion1, APIVersion2}
+}
+
+// VersionMiddleware resolves the API version of the request. Routes under a
+// versioned path pass it as pathVersion and only accept an API-Version header
+// that agrees with it; unversioned routes pass "" and take the header, else
+// cfg.Default. Responses of a deprecated version carry Deprecation (RFC 9745)
+// and Sunset (RFC 8594) headers and, under /api/v1, a Link to the v2 route.
+func VersionMiddleware(cfg config.VersioningConfig, pathVersion string) gin.HandlerFunc {
+	return versionMiddleware(cfg, pathVersion, true)
+}
+
+// V1OnlyVersionMiddleware is VersionMiddleware for /api/v1 routes without a
+// v2 counterpart, such as the streams: they are deprecated along with v1 but
+// get no successor Link.
+func V1OnlyVersionMiddleware(cfg config.VersioningConfig) gin.HandlerFunc {
+	return versionMiddleware(cfg, APIVersion1, false)
+}
+
+func versionMiddleware(cfg config.VersioningConfig, pathVersion string, linkSuccessor bool) gin.HandlerFunc {
+	deprecatedAt, _ := time.Parse(time.RFC3339, cfg.V1DeprecatedAt)
+	sunsetAt, _ := time.Parse(time.RFC3339, cfg.V1SunsetAt)
+	defaultVersion := normalizeVersion(cfg.Default)
+	if defaultVersion == "" {
+		defaultVersion = APIVersion1
+	}
+
+	return gin.HandlerFunc(func(c *gin.Context) {
+		requested := strings.TrimSpace(c.GetHeader(VersionHeader))
+		if pathVersion == "" {
+			c.Writer.Header().Add("Vary", VersionHeader)
+		}
+
+		version := pathVersion
+		switch {
+		case requested == "":
+			if version == "" {
+				version = defaultVersion
+			}
+		case !supportedVersion(normalizeVersion(requested)):
+			RespondWithUnsupportedAPIVersion(c, sharedErrors.Text{}, []sharedErrors.Text{
+				msgVersionUnsupported.Format(requested, APIVersion1, APIVersion2),
+			})
+			c.Abort()
+			return
+		case pathVersion != "" && normalizeVersion(requested) != pathVersion:
+			RespondWithUnsupportedAPIVersion(c, sharedErrors.Text{}, []sharedErrors.Text{
+				msgVersionConflict.Format(requested, pathVersion),
+			})
+			c.Abort()
+			return
+		default:
+			version = normalizeVersion(requested)
+		}
+
+		c.Set(versionKey, version)
+		c.Header(VersionHeader, version)
+		if version == APIVersion1 {
+			if !deprecatedAt.IsZero() {
+				c.Header("Deprecation", fmt.Sprintf("@%d", deprecatedAt.Unix()))
+			}
+			if !sunsetAt.IsZero() {
+				c.Header("Sunset", sunsetAt.UTC().Format(http.TimeFormat))
+			}
+			if successor, ok := strings.CutPrefix(c.Request.URL.Path, "/api/"+APIVersion1+"/"); ok && pathVersion != "" && linkSuccessor {
+				c.Header("Link", fmt.Sprintf(`</api/%s/%s>; rel="successor-version"`, APIVersion2, successor))
+			}
+		}
+
+		c.Next()
+	})
+}
+
+// APIVersion returns the version VersionMiddleware resolved, or v1 on routes
+// without it.
+func APIVersion(c *gin.Context) string {
+	if version := c.GetString(versionKey); version != "" {
+		return version
+	}
+	return APIVersion1
+}
+
+// normalizeVersion accepts "2", "v2" and "V2" alike.
+func normalizeVersion(version string) string {
+	version = strings.ToLower(strings.TrimSpace(version))
+	if version != "" && !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return version
+}
+
+func supportedVersion(version string) bool {
+	for _, supported := range APIVersions() {
+		if version == supported {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var versioningConfig = config.VersioningConfig{
+	Default:        "v1",
+	V1DeprecatedAt: "2026-10-19T00:00:00Z",
+	V1SunsetAt:     "2027-10-19T00:00:00Z",
+}
+
+func setupVersionRouter(cfg config.VersioningConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(LocaleMiddleware())
+	handler := func(c *gin.Context) {
+		RespondWithSuccess(c, APIVersion(c), "ok")
+	}
+	router.GET("/api/v1/weather/:cep", VersionMiddleware(cfg, APIVersion1), handler)
+	router.GET("/api/v2/weather/:cep", VersionMiddleware(cfg, APIVersion2), handler)
+	router.GET("/api/weather/:cep", VersionMiddleware(cfg, ""), handler)
+	router.GET("/api/v1/weather/:cep/stream", V1OnlyVersionMiddleware(cfg), handler)
+	return router
+}
+
+func TestVersionMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		version    string
+		deprecated bool
+		link       string
+		vary       []string
+	}{
+		{name: "v1 path", path: "/api/v1/weather/01001000", version: "v1", deprecated: true,
+			link: `</api/v2/weather/01001000>; rel="successor-version"`},
+		{name: "v1 path with matching header", path: "/api/v1/weather/01001000", header: "1", version: "v1", deprecated: true,
+			link: `</api/v2/weather/01001000>; rel="successor-version"`},
+		{name: "v2 path", path: "/api/v2/weather/01001000", version: "v2"},
+		{name: "v1 only path has no successor", path: "/api/v1/weather/01001000/stream", version: "v1", deprecated: true},
+		{name: "Unversioned default", path: "/api/weather/01001000", version: "v1", deprecated: true, vary: []string{VersionHeader}},
+		{name: "Unversioned v2 header", path: "/api/weather/01001000", header: "V2", version: "v2", vary: []string{VersionHeader}},
+	}
+
+	router := setupVersionRouter(versioningConfig)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(VersionHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), `"data":"`+tt.version+`"`)
+			assert.Equal(t, tt.version, w.Header().Get(VersionHeader))
+			assert.Equal(t, tt.link, w.Header().Get("Link"))
+			if tt.deprecated {
+				assert.Equal(t, "@1792368000", w.Header().Get("Deprecation"))
+				assert.Equal(t, "Tue, 19 Oct 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+			} else {
+				assert.Empty(t, w.Header().Get("Deprecation"))
+				assert.Empty(t, w.Header().Get("Sunset"))
+			}
+			for _, vary := range tt.vary {
+				assert.Contains(t, w.Header().Values("Vary"), vary)
+			}
+		})
+	}
+}
+
+func TestVersionMiddlewareRejectsVersions(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		header         string
+		acceptLanguage string
+		expected       string
+	}{
+		{name: "Unknown version", path: "/api/weather/01001000", header: "v3",
+			expected: `{"data":null,"message":"Requested API version is not supported","causes":["API-Version \"v3\" is not supported, use v1 or v2"]}`},
+		{name: "Conflicting path", path: "/api/v2/weather/01001000", header: "v1",
+			expected: `{"data":null,"message":"Requested API version is not supported","causes":["API-Version header \"v1\" conflicts with the v2 path"]}`},
+		{name: "Conflicting path in Portuguese", path: "/api/v1/weather/01001000", header: "v2", acceptLanguage: "pt-BR",
+			expected: `{"data":null,"message":"A versão da API solicitada não é suportada","causes":["O header API-Version \"v2\" conflita com o caminho v1"]}`},
+	}
+
+	router := setupVersionRouter(versioningConfig)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set(VersionHeader, tt.header)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Empty(t, w.Header().Get(VersionHeader))
+			assert.JSONEq(t, tt.expected, w.Body.String())
+		})
+	}
+}
+
+func TestVersionMiddlewareWithoutDates(t *testing.T) {
+	router := setupVersionRouter(config.VersioningConfig{Default: "v2"})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/weather/01001000", nil))
+	assert.Equal(t, "v2", w.Header().Get(VersionHeader))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/weather/01001000", nil))
+	assert.Equal(t, "v1", w.Header().Get(VersionHeader))
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Sunset"))
+}
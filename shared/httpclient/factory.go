@@ -0,0 +1,108 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+)
+
+const dialTimeout = 10 * time.Second
+
+// Factory hands out http.Clients that share a single pooled transport, so
+// connections to the upstream APIs are reused across requests.
+type Factory struct {
+	transport *http.Transport
+}
+
+func NewFactory(cfg *config.Config) (*Factory, func(), error) {
+	transport, err := NewTransport(cfg.ExternalAPIs.Outbound)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	factory := &Factory{transport: transport}
+	return factory, transport.CloseIdleConnections, nil
+}
+
+// Client returns a client bound to the shared transport with its own overall
+// request timeout. A zero timeout means no limit besides the request context.
+func (f *Factory) Client(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: f.transport,
+		Timeout:   timeout,
+	}
+}
+
+func NewTransport(cfg config.OutboundConfig) (*http.Transport, error) {
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid outbound proxy URL: %w", err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	transport := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:     tlsConfig,
+		ForceAttemptHTTP2:   cfg.HTTP2Enabled,
+		MaxIdleConns:        cfg.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:     time.Duration(cfg.IdleConnTimeoutSec) * time.Second,
+		TLSHandshakeTimeout: time.Duration(cfg.TLSHandshakeTimeoutSec) * time.Second,
+	}
+
+	// A non-nil, empty TLSNextProto map is how net/http disables HTTP/2.
+	if !cfg.HTTP2Enabled {
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return transport, nil
+}
+
+func newTLSConfig(cfg config.OutboundConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read outbound CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in outbound CA bundle %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCertFile != "" || cfg.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load outbound client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,98 @@
+package httpclient
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0o600))
+	return path
+}
+
+func newTLSServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFactoryVerifiesServerCertificate(t *testing.T) {
+	server := newTLSServer(t)
+
+	factory, cleanup, err := NewFactory(&config.Config{})
+	require.NoError(t, err)
+	defer cleanup()
+
+	_, err = factory.Client(time.Second).Get(server.URL)
+
+	assert.Error(t, err, "self-signed certificate must not be trusted by default")
+}
+
+func TestFactoryTrustsCustomCABundle(t *testing.T) {
+	server := newTLSServer(t)
+
+	cfg := &config.Config{}
+	cfg.ExternalAPIs.Outbound.CAFile = writeServerCA(t, server)
+
+	factory, cleanup, err := NewFactory(cfg)
+	require.NoError(t, err)
+	defer cleanup()
+
+	resp, err := factory.Client(time.Second).Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestFactorySharesTransportAcrossClients(t *testing.T) {
+	factory, cleanup, err := NewFactory(&config.Config{})
+	require.NoError(t, err)
+	defer cleanup()
+
+	viaCep := factory.Client(2 * time.Second)
+	weather := factory.Client(5 * time.Second)
+
+	assert.Same(t, viaCep.Transport, weather.Transport)
+	assert.Equal(t, 2*time.Second, viaCep.Timeout)
+	assert.Equal(t, 5*time.Second, weather.Timeout)
+}
+
+func TestNewTransportOptions(t *testing.T) {
+	transport, err := NewTransport(config.OutboundConfig{
+		ProxyURL:     "http://proxy.internal:3128",
+		HTTP2Enabled: false,
+	})
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://viacep.com.br/ws/", nil)
+	proxyURL, err := transport.Proxy(req)
+	require.NoError(t, err)
+	assert.Equal(t, "proxy.internal:3128", proxyURL.Host)
+	assert.NotNil(t, transport.TLSNextProto)
+	assert.False(t, transport.TLSClientConfig.InsecureSkipVerify)
+}
+
+func TestNewTransportInvalidFiles(t *testing.T) {
+	_, err := NewTransport(config.OutboundConfig{CAFile: "/does/not/exist.pem"})
+	assert.ErrorContains(t, err, "CA bundle")
+
+	_, err = NewTransport(config.OutboundConfig{ClientCertFile: "/does/not/exist.crt", ClientKeyFile: "/does/not/exist.key"})
+	assert.ErrorContains(t, err, "client certificate")
+}
@@ -0,0 +1,47 @@
+package i18n_test
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+	"github.com/gerps2/desafio-cloud-run/shared/i18n"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	// Imported for the messages they declare.
+	_ "github.com/gerps2/desafio-cloud-run/features/graphql"
+	_ "github.com/gerps2/desafio-cloud-run/features/weather"
+	_ "github.com/gerps2/desafio-cloud-run/shared/ratelimit"
+)
+
+var verbs = regexp.MustCompile(`%(?:\[\d+\])?[dsqv]`)
+
+func TestEveryMessageIsTranslated(t *testing.T) {
+	messages := sharedErrors.Messages()
+	require.NotEmpty(t, messages)
+
+	for _, message := range messages {
+		for _, locale := range []string{i18n.Portuguese, i18n.Spanish} {
+			translation, ok := i18n.Lookup(locale, message.ID)
+			if !assert.True(t, ok, "%s has no %s translation", message.ID, locale) {
+				continue
+			}
+			assert.Equal(t, verbKinds(message.Template), verbKinds(translation),
+				"%s: the %s translation must take the same arguments", message.ID, locale)
+		}
+	}
+}
+
+// verbKinds lists the fmt verbs of a text regardless of their order, since
+// translations may reorder arguments with explicit indexes.
+func verbKinds(text string) []string {
+	kinds := []string{}
+	for _, verb := range verbs.FindAllString(text, -1) {
+		kinds = append(kinds, verb[len(verb)-1:])
+	}
+	sort.Strings(kinds)
+	return kinds
+}
@@ -0,0 +1,218 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+)
+
+// Locales with a catalog. Messages are written in English in the code, so
+// English is both the default and the fallback for text a catalog lacks.
+const (
+	English    = "en"
+	Portuguese = "pt-BR"
+	Spanish    = "es"
+
+	Default = English
+)
+
+//go:embed locales/*.json
+var files embed.FS
+
+type catalogFile struct {
+	// Messages maps a message ID to its translation, formatted with the
+	// arguments of the message.
+	Messages map[string]string `json:"messages"`
+	// Conditions maps a WeatherAPI condition code to its description.
+	Conditions map[string]string `json:"conditions"`
+}
+
+type catalog struct {
+	messages   map[string]string
+	conditions map[int]string
+}
+
+var catalogs = mustLoad()
+
+// Locales lists the locales with a catalog, default first.
+func Locales() []string {
+	return []string{English, Portuguese, Spanish}
+}
+
+func mustLoad() map[string]*catalog {
+	loaded := make(map[string]*catalog, len(Locales()))
+	for _, locale := range Locales() {
+		c, err := load(locale)
+		if err != nil {
+			panic(err)
+		}
+		loaded[locale] = c
+	}
+	return loaded
+}
+
+func load(locale string) (*catalog, error) {
+	data, err := files.ReadFile(path.Join("locales", locale+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var file catalogFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("catalog %s: %w", locale, err)
+	}
+
+	c := &catalog{
+		messages:   file.Messages,
+		conditions: map[int]string{},
+	}
+	if c.messages == nil {
+		c.messages = map[string]string{}
+	}
+	for key, text := range file.Conditions {
+		code, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("catalog %s: condition %q: %w", locale, key, err)
+		}
+		c.conditions[code] = text
+	}
+	return c, nil
+}
+
+// Match picks the locale for an Accept-Language header. Tags also match on
+// their primary language, so pt-PT gets pt-BR and es-AR gets es; a header
+// naming no known language gets Default.
+func Match(acceptLanguage string) string {
+	type tag struct {
+		name string
+		q    float64
+	}
+	var tags []tag
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.TrimSpace(fields[0])
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			tags = append(tags, tag{name: name, q: q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	for _, t := range tags {
+		if locale, ok := lookupTag(t.name); ok {
+			return locale
+		}
+	}
+	return Default
+}
+
+func lookupTag(name string) (string, bool) {
+	if name == "*" {
+		return Default, true
+	}
+	for _, locale := range Locales() {
+		if strings.EqualFold(locale, name) {
+			return locale, true
+		}
+	}
+	primary, _, _ := strings.Cut(name, "-")
+	for _, locale := range Locales() {
+		localePrimary, _, _ := strings.Cut(locale, "-")
+		if strings.EqualFold(localePrimary, primary) {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext returns the locale of the request, or Default.
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return Default
+	}
+	if locale, ok := ctx.Value(contextKey{}).(string); ok {
+		return locale
+	}
+	return Default
+}
+
+// Lookup returns the translation of a message ID, unformatted.
+func Lookup(locale, id string) (string, bool) {
+	c, ok := catalogs[locale]
+	if !ok {
+		return "", false
+	}
+	translation, ok := c.messages[id]
+	return translation, ok
+}
+
+// Translate formats text in locale. Text without an ID or a translation,
+// such as causes carrying upstream errors, is returned in English.
+func Translate(locale string, text sharedErrors.Text) string {
+	if text.ID == "" {
+		return text.String()
+	}
+	translation, ok := Lookup(locale, text.ID)
+	if !ok {
+		return text.String()
+	}
+	if len(text.Args) > 0 {
+		return fmt.Sprintf(translation, text.Args...)
+	}
+	return translation
+}
+
+// LocalizeError returns a copy of apiErr with its message and causes
+// translated; the code is left alone for clients to branch on.
+func LocalizeError(locale string, apiErr *sharedErrors.APIError) *sharedErrors.APIError {
+	if apiErr == nil {
+		return nil
+	}
+	localized := *apiErr
+	if apiErr.MessageText.ID != "" {
+		localized.Message = Translate(locale, apiErr.MessageText)
+	}
+	if len(apiErr.CauseTexts) > 0 && len(apiErr.CauseTexts) == len(apiErr.Causes) {
+		localized.Causes = make([]string, len(apiErr.CauseTexts))
+		for i, cause := range apiErr.CauseTexts {
+			localized.Causes[i] = Translate(locale, cause)
+		}
+	}
+	return &localized
+}
+
+// Condition describes a WeatherAPI condition code, falling back to the
+// English catalog and then to the provider's own text for unknown codes.
+func Condition(locale string, code int, providerText string) string {
+	if c, ok := catalogs[locale]; ok {
+		if text, ok := c.conditions[code]; ok {
+			return text
+		}
+	}
+	if text, ok := catalogs[Default].conditions[code]; ok {
+		return text
+	}
+	return providerText
+}
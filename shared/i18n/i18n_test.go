@@ -0,0 +1,115 @@
+package i18n
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		expected       string
+	}{
+		{acceptLanguage: "", expected: English},
+		{acceptLanguage: "pt-BR", expected: Portuguese},
+		{acceptLanguage: "pt-br,pt;q=0.9,en;q=0.8", expected: Portuguese},
+		{acceptLanguage: "pt-PT", expected: Portuguese},
+		{acceptLanguage: "es-AR,es;q=0.9", expected: Spanish},
+		{acceptLanguage: "fr-FR, es;q=0.5", expected: Spanish},
+		{acceptLanguage: "en;q=0.4, es;q=0.6", expected: Spanish},
+		{acceptLanguage: "es;q=0, pt", expected: Portuguese},
+		{acceptLanguage: "de, *;q=0.1", expected: English},
+		{acceptLanguage: "de", expected: English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Match(tt.acceptLanguage))
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, Default, FromContext(context.Background()))
+	assert.Equal(t, Spanish, FromContext(NewContext(context.Background(), Spanish)))
+}
+
+func TestTranslate(t *testing.T) {
+	invalidZipcode := sharedErrors.Message{ID: "zipcode.invalid", Template: "invalid zipcode"}.Format()
+	assert.Equal(t, "CEP inválido", Translate(Portuguese, invalidZipcode))
+	assert.Equal(t, "código postal inválido", Translate(Spanish, invalidZipcode))
+	assert.Equal(t, "invalid zipcode", Translate(English, invalidZipcode))
+	assert.Equal(t, "invalid zipcode", Translate("fr", invalidZipcode))
+
+	retryIn := sharedErrors.Message{ID: "rate_limit.retry_in", Template: "Retry in %d seconds"}
+	assert.Equal(t, "Tente novamente em 42 segundos", Translate(Portuguese, retryIn.Format(42)))
+	encoding := sharedErrors.Message{ID: "encoding.unsupported", Template: "Content-Encoding %q is not supported, use gzip"}
+	assert.Equal(t, `Content-Encoding "br" no está soportado, use gzip`, Translate(Spanish, encoding.Format("br")))
+
+	// The English wording does not take part in the lookup.
+	reworded := sharedErrors.Message{ID: "zipcode.invalid", Template: "zipcode is invalid"}.Format()
+	assert.Equal(t, "CEP inválido", Translate(Portuguese, reworded))
+	assert.Equal(t, "zipcode is invalid", Translate(English, reworded))
+
+	// Raw text and unknown IDs are left as is.
+	assert.Equal(t, "dial tcp: connection refused", Translate(Portuguese, sharedErrors.Raw("dial tcp: connection refused")))
+	assert.Equal(t, "brand new", Translate(Portuguese, sharedErrors.Message{ID: "brand.new", Template: "brand new"}.Format()))
+}
+
+func TestLocalizeError(t *testing.T) {
+	original := sharedErrors.NewAPIError("ZIPCODE_NOT_FOUND",
+		sharedErrors.Message{ID: "zipcode.not_found", Template: "can not find zipcode"}.Format(), http.StatusNotFound,
+		[]sharedErrors.Text{
+			sharedErrors.Message{ID: "zipcode.not_found_cause", Template: "The provided zipcode was not found"}.Format(),
+			sharedErrors.Raw("upstream said no"),
+		})
+
+	localized := LocalizeError(Portuguese, original)
+
+	assert.Equal(t, "CEP não encontrado", localized.Message)
+	assert.Equal(t, []string{"O CEP informado não foi encontrado", "upstream said no"}, localized.Causes)
+	assert.Equal(t, "ZIPCODE_NOT_FOUND", localized.Code)
+	assert.Equal(t, http.StatusNotFound, localized.StatusCode)
+	assert.Equal(t, "can not find zipcode", original.Message)
+	assert.Equal(t, []string{"The provided zipcode was not found", "upstream said no"}, original.Causes)
+
+	// Errors built without texts keep their message and causes.
+	literal := &sharedErrors.APIError{Code: "LEGACY", Message: "kept", Causes: []string{"as is"}}
+	assert.Equal(t, literal, LocalizeError(Portuguese, literal))
+
+	assert.Nil(t, LocalizeError(Portuguese, nil))
+}
+
+func TestCondition(t *testing.T) {
+	assert.Equal(t, "Parcialmente nublado", Condition(Portuguese, 1003, "Partly cloudy"))
+	assert.Equal(t, "Lluvia ligera", Condition(Spanish, 1183, "Light rain"))
+	assert.Equal(t, "Clear", Condition(English, 1000, "Sunny"))
+	assert.Equal(t, "Clear", Condition("fr", 1000, "Sunny"))
+	assert.Equal(t, "Volcanic ash", Condition(Portuguese, 9999, "Volcanic ash"))
+}
+
+func TestCatalogsCoverTheSameKeys(t *testing.T) {
+	english := catalogs[English]
+	require.NotEmpty(t, english.conditions)
+	assert.Empty(t, english.messages, "English messages live in the code")
+
+	for _, locale := range []string{Portuguese, Spanish} {
+		c := catalogs[locale]
+		assert.Len(t, c.conditions, len(english.conditions), locale)
+		for code := range english.conditions {
+			assert.Contains(t, c.conditions, code, locale)
+		}
+	}
+
+	portuguese, spanish := catalogs[Portuguese], catalogs[Spanish]
+	for id := range portuguese.messages {
+		assert.Contains(t, spanish.messages, id)
+	}
+	assert.Len(t, spanish.messages, len(portuguese.messages))
+}
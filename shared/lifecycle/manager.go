@@ -0,0 +1,209 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type server struct {
+	name  string
+	start func() error
+	stop  func(ctx context.Context) error
+}
+
+type worker struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+type closer struct {
+	name  string
+	close func() error
+}
+
+// Manager runs the servers and background workers of the app and shuts them
+// down in order on SIGINT/SIGTERM or when any of them fails:
+//
+//  1. drain hooks run (readiness starts failing) and the drain delay elapses
+//  2. servers stop accepting connections and in-flight requests finish
+//  3. background workers are cancelled and awaited
+//  4. closers run in registration order
+//
+// Steps 2 and 3 share the grace period.
+type Manager struct {
+	grace      time.Duration
+	drainDelay time.Duration
+	signals    []os.Signal
+	logger     logger.Logger
+
+	servers  []server
+	workers  []worker
+	drains   []func()
+	closers  []closer
+	inFlight inFlight
+}
+
+func NewManager(cfg *config.Config, log logger.Logger) *Manager {
+	return &Manager{
+		grace:      time.Duration(cfg.App.ShutdownGraceSec) * time.Second,
+		drainDelay: time.Duration(cfg.App.DrainDelaySec) * time.Second,
+		signals:    []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+		logger:     log,
+	}
+}
+
+// AddServer registers a server whose start blocks until stop is called.
+func (m *Manager) AddServer(name string, start func() error, stop func(ctx context.Context) error) {
+	m.servers = append(m.servers, server{name: name, start: start, stop: stop})
+}
+
+// Go registers background work that must return once ctx is cancelled.
+func (m *Manager) Go(name string, run func(ctx context.Context) error) {
+	m.workers = append(m.workers, worker{name: name, run: run})
+}
+
+// OnDrain registers a hook run as soon as shutdown starts.
+func (m *Manager) OnDrain(fn func()) {
+	m.drains = append(m.drains, fn)
+}
+
+// OnClose registers a closer run after servers and workers have stopped.
+func (m *Manager) OnClose(name string, fn func() error) {
+	m.closers = append(m.closers, closer{name: name, close: fn})
+}
+
+// Track registers a unit of in-flight work; shutdown waits for done to be
+// called within the grace period.
+func (m *Manager) Track() (done func()) {
+	m.inFlight.add()
+	var once sync.Once
+	return func() { once.Do(m.inFlight.done) }
+}
+
+// Middleware tracks each request, including those on hijacked h2c
+// connections that http.Server.Shutdown does not wait for.
+func (m *Manager) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		done := m.Track()
+		defer done()
+		c.Next()
+	}
+}
+
+// Run blocks until a shutdown signal arrives or a server or worker fails,
+// then shuts everything down. The returned error reports the failure that
+// triggered the shutdown and any step that did not complete.
+func (m *Manager) Run(ctx context.Context) error {
+	signalCtx, stopSignals := signal.NotifyContext(ctx, m.signals...)
+	defer stopSignals()
+
+	workerCtx, cancelWorkers := context.WithCancel(context.Background())
+	defer cancelWorkers()
+
+	// Buffered so exits after shutdown has begun, which nobody reads, never
+	// block.
+	failures := make(chan error, len(m.servers)+len(m.workers))
+	var serversDone, workersDone sync.WaitGroup
+
+	for _, s := range m.servers {
+		serversDone.Add(1)
+		go func(s server) {
+			defer serversDone.Done()
+			failures <- failure(s.name, s.start())
+		}(s)
+	}
+	for _, w := range m.workers {
+		workersDone.Add(1)
+		go func(w worker) {
+			defer workersDone.Done()
+			failures <- failure(w.name, w.run(workerCtx))
+		}(w)
+	}
+
+	var cause error
+	select {
+	case <-signalCtx.Done():
+		m.logger.Info("Shutdown signal received, draining")
+	case cause = <-failures:
+		m.logger.Error("Shutting down after failure: %v", cause)
+	}
+	// A second signal now terminates the process immediately.
+	stopSignals()
+
+	return errors.Join(cause, m.shutdown(cause == nil, cancelWorkers, &serversDone, &workersDone))
+}
+
+func (m *Manager) shutdown(drain bool, cancelWorkers context.CancelFunc, serversDone, workersDone *sync.WaitGroup) error {
+	for _, fn := range m.drains {
+		fn()
+	}
+	if drain && m.drainDelay > 0 {
+		m.logger.Info("Waiting %s for load balancers to stop routing traffic", m.drainDelay)
+		time.Sleep(m.drainDelay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.grace)
+	defer cancel()
+
+	var errs []error
+	for i := len(m.servers) - 1; i >= 0; i-- {
+		s := m.servers[i]
+		if err := s.stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stopping %s: %w", s.name, err))
+		}
+	}
+	if err := wait(ctx, serversDone); err != nil {
+		errs = append(errs, fmt.Errorf("waiting for servers: %w", err))
+	}
+	if err := m.inFlight.wait(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	cancelWorkers()
+	if err := wait(ctx, workersDone); err != nil {
+		errs = append(errs, fmt.Errorf("waiting for background workers: %w", err))
+	}
+
+	for _, c := range m.closers {
+		if err := c.close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing %s: %w", c.name, err))
+		}
+	}
+
+	if len(errs) == 0 {
+		m.logger.Info("Shutdown complete")
+	}
+	return errors.Join(errs...)
+}
+
+func failure(name string, err error) error {
+	if err == nil {
+		return fmt.Errorf("%s stopped unexpectedly", name)
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
+
+func wait(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
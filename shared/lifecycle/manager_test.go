@@ -0,0 +1,126 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type events struct {
+	mu   sync.Mutex
+	list []string
+}
+
+func (e *events) add(event string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.list = append(e.list, event)
+}
+
+func (e *events) get() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.list...)
+}
+
+func newTestManager(graceMs int) *Manager {
+	cfg := &config.Config{Log: config.LogConfig{Level: "error"}}
+	m := NewManager(cfg, logger.NewWithWriter(io.Discard, cfg.Log, logger.NewLevel(cfg)))
+	m.grace = time.Duration(graceMs) * time.Millisecond
+	return m
+}
+
+// addBlockingServer registers a server that runs until stopped, like
+// http.Server.ListenAndServe.
+func addBlockingServer(m *Manager, ev *events) {
+	stopped := make(chan struct{})
+	m.AddServer("server", func() error {
+		ev.add("server started")
+		<-stopped
+		return nil
+	}, func(ctx context.Context) error {
+		ev.add("server stopped")
+		close(stopped)
+		return nil
+	})
+}
+
+func TestRunShutsDownInOrder(t *testing.T) {
+	m := newTestManager(1000)
+	ev := &events{}
+	addBlockingServer(m, ev)
+	m.Go("worker", func(ctx context.Context) error {
+		<-ctx.Done()
+		ev.add("worker stopped")
+		return nil
+	})
+	m.OnDrain(func() { ev.add("draining") })
+	m.OnClose("clients", func() error { ev.add("clients closed"); return nil })
+	m.OnClose("cache", func() error { ev.add("cache closed"); return nil })
+
+	done := m.Track()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		ev.add("request finished")
+		done()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	err := m.Run(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"server started",
+		"draining",
+		"server stopped",
+		"request finished",
+		"worker stopped",
+		"clients closed",
+		"cache closed",
+	}, ev.get())
+}
+
+func TestRunReturnsStartupFailure(t *testing.T) {
+	m := newTestManager(1000)
+	ev := &events{}
+	m.AddServer("http server", func() error {
+		return errors.New("listen tcp :8080: address already in use")
+	}, func(ctx context.Context) error { return nil })
+	m.OnClose("clients", func() error { ev.add("clients closed"); return nil })
+
+	err := m.Run(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "http server: listen tcp :8080: address already in use")
+	assert.Equal(t, []string{"clients closed"}, ev.get())
+}
+
+func TestRunReportsWorkPastGracePeriod(t *testing.T) {
+	m := newTestManager(50)
+	ev := &events{}
+	addBlockingServer(m, ev)
+	m.Track()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := m.Run(ctx)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 requests still in flight")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
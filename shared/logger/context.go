@@ -0,0 +1,28 @@
+package logger
+
+import "context"
+
+type fieldsKey struct{}
+
+// ContextWithFields returns a copy of ctx carrying the given key/value pairs.
+// Loggers obtained through WithContext attach them to every line.
+func ContextWithFields(ctx context.Context, fields ...interface{}) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+
+	existing := FieldsFromContext(ctx)
+	merged := make([]interface{}, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+func FieldsFromContext(ctx context.Context) []interface{} {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).([]interface{})
+	return fields
+}
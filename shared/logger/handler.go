@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+// Field names understood by Cloud Logging when parsing structured JSON
+// written to stdout. See https://cloud.google.com/logging/docs/structured-logging.
+const (
+	CloudTraceKey          = "logging.googleapis.com/trace"
+	CloudSpanIDKey         = "logging.googleapis.com/spanId"
+	CloudTraceSampledKey   = "logging.googleapis.com/trace_sampled"
+	CloudSourceLocationKey = "logging.googleapis.com/sourceLocation"
+)
+
+// traceHandler adds the trace context carried by the record's context to
+// every line, using Cloud Logging keys when cloud is set.
+type traceHandler struct {
+	slog.Handler
+	cloud     bool
+	projectID string
+}
+
+func (h *traceHandler) Handle(ctx context.Context, record slog.Record) error {
+	if trace, ok := TraceFromContext(ctx); ok {
+		if h.cloud {
+			record.AddAttrs(
+				slog.String(CloudTraceKey, h.cloudTrace(trace.TraceID)),
+				slog.String(CloudSpanIDKey, trace.SpanID),
+				slog.Bool(CloudTraceSampledKey, trace.Sampled),
+			)
+		} else {
+			record.AddAttrs(
+				slog.String("trace_id", trace.TraceID),
+				slog.String("span_id", trace.SpanID),
+			)
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &traceHandler{Handler: h.Handler.WithAttrs(attrs), cloud: h.cloud, projectID: h.projectID}
+}
+
+func (h *traceHandler) WithGroup(name string) slog.Handler {
+	return &traceHandler{Handler: h.Handler.WithGroup(name), cloud: h.cloud, projectID: h.projectID}
+}
+
+func (h *traceHandler) cloudTrace(traceID string) string {
+	if h.projectID == "" {
+		return traceID
+	}
+	return fmt.Sprintf("projects/%s/traces/%s", h.projectID, traceID)
+}
+
+// cloudReplaceAttr renames the built-in slog keys to the ones Cloud Logging
+// promotes to LogEntry fields.
+func cloudReplaceAttr(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return attr
+	}
+
+	switch attr.Key {
+	case slog.LevelKey:
+		level, _ := attr.Value.Any().(slog.Level)
+		return slog.String("severity", cloudSeverity(level))
+	case slog.MessageKey:
+		attr.Key = "message"
+	case slog.SourceKey:
+		source, ok := attr.Value.Any().(*slog.Source)
+		if !ok || source == nil {
+			return attr
+		}
+		return slog.Group(CloudSourceLocationKey,
+			slog.String("file", source.File),
+			slog.String("line", strconv.Itoa(source.Line)),
+			slog.String("function", source.Function),
+		)
+	}
+	return attr
+}
+
+func cloudSeverity(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "ERROR"
+	case level >= slog.LevelWarn:
+		return "WARNING"
+	case level >= slog.LevelInfo:
+		return "INFO"
+	default:
+		return "DEBUG"
+	}
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+)
+
+// Level is the minimum level shared by every logger derived from New. It can
+// be changed at runtime without rebuilding the loggers.
+type Level struct {
+	v slog.LevelVar
+}
+
+func NewLevel(cfg *config.Config) *Level {
+	level := &Level{}
+	if err := level.Set(cfg.Log.Level); err != nil {
+		level.v.Set(slog.LevelInfo)
+	}
+	return level
+}
+
+func (l *Level) Set(level string) error {
+	parsed, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.v.Set(parsed)
+	return nil
+}
+
+func (l *Level) String() string {
+	return strings.ToLower(l.v.Level().String())
+}
+
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q", level)
+	}
+}
@@ -1,8 +1,22 @@
 package logger
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+)
+
+const (
+	FormatJSON  = "json"
+	FormatText  = "text"
+	FormatCloud = "gcp"
 )
 
 //go:generate mockery --name=Logger
@@ -11,36 +25,99 @@ type Logger interface {
 	Error(msg string, args ...interface{})
 	Debug(msg string, args ...interface{})
 	Warn(msg string, args ...interface{})
+	With(fields ...interface{}) Logger
+	WithContext(ctx context.Context) Logger
 }
 
 type logger struct {
-	infoLogger  *log.Logger
-	errorLogger *log.Logger
-	debugLogger *log.Logger
-	warnLogger  *log.Logger
+	handler slog.Handler
+	ctx     context.Context
 }
 
-func New() Logger {
+func New(cfg *config.Config, level *Level) Logger {
+	return NewWithWriter(os.Stdout, cfg.Log, level)
+}
+
+func NewWithWriter(w io.Writer, cfg config.LogConfig, level *Level) Logger {
+	opts := &slog.HandlerOptions{
+		AddSource: true,
+		Level:     &level.v,
+	}
+
+	format := strings.ToLower(cfg.Format)
+
+	var handler slog.Handler
+	switch format {
+	case FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	case FormatCloud:
+		opts.ReplaceAttr = cloudReplaceAttr
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		handler = slog.NewJSONHandler(w, opts)
+	}
+
 	return &logger{
-		infoLogger:  log.New(os.Stdout, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile),
-		errorLogger: log.New(os.Stderr, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile),
-		debugLogger: log.New(os.Stdout, "DEBUG: ", log.Ldate|log.Ltime|log.Lshortfile),
-		warnLogger:  log.New(os.Stdout, "WARN: ", log.Ldate|log.Ltime|log.Lshortfile),
+		handler: &traceHandler{
+			Handler:   handler,
+			cloud:     format == FormatCloud,
+			projectID: cfg.ProjectID,
+		},
+		ctx: context.Background(),
 	}
 }
 
 func (l *logger) Info(msg string, args ...interface{}) {
-	l.infoLogger.Printf(msg, args...)
+	l.log(slog.LevelInfo, msg, args)
 }
 
 func (l *logger) Error(msg string, args ...interface{}) {
-	l.errorLogger.Printf(msg, args...)
+	l.log(slog.LevelError, msg, args)
 }
 
 func (l *logger) Debug(msg string, args ...interface{}) {
-	l.debugLogger.Printf(msg, args...)
+	l.log(slog.LevelDebug, msg, args)
 }
 
 func (l *logger) Warn(msg string, args ...interface{}) {
-	l.warnLogger.Printf(msg, args...)
+	l.log(slog.LevelWarn, msg, args)
+}
+
+func (l *logger) With(fields ...interface{}) Logger {
+	if len(fields) == 0 {
+		return l
+	}
+	return &logger{
+		handler: slog.New(l.handler).With(fields...).Handler(),
+		ctx:     l.ctx,
+	}
+}
+
+func (l *logger) WithContext(ctx context.Context) Logger {
+	child := &logger{
+		handler: l.handler,
+		ctx:     ctx,
+	}
+	if fields := FieldsFromContext(ctx); len(fields) > 0 {
+		child.handler = slog.New(l.handler).With(fields...).Handler()
+	}
+	return child
+}
+
+func (l *logger) log(level slog.Level, msg string, args []interface{}) {
+	if !l.handler.Enabled(l.ctx, level) {
+		return
+	}
+
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+
+	// Skip runtime.Callers, log and the exported level method so the
+	// source attribute points at the caller.
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+
+	record := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	_ = l.handler.Handle(l.ctx, record)
 }
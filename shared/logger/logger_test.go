@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLevel(t *testing.T, level string) *Level {
+	t.Helper()
+	cfg := &config.Config{Log: config.LogConfig{Level: level}}
+	return NewLevel(cfg)
+}
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func TestLoggerJSONOutputFormatsMessage(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriter(&buf, config.LogConfig{Format: FormatJSON}, newTestLevel(t, "info"))
+
+	log.Info("Address found for CEP %s", "12345-678")
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "INFO", lines[0]["level"])
+	assert.Equal(t, "Address found for CEP 12345-678", lines[0]["msg"])
+
+	source, ok := lines[0]["source"].(map[string]interface{})
+	require.True(t, ok, "Expected source attribute")
+	assert.Contains(t, source["file"], "logger_test.go")
+}
+
+func TestLoggerLevelFiltering(t *testing.T) {
+	var buf bytes.Buffer
+	level := newTestLevel(t, "warn")
+	log := NewWithWriter(&buf, config.LogConfig{Format: FormatJSON}, level)
+
+	log.Debug("debug message")
+	log.Info("info message")
+	log.Warn("warn message")
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "warn message", lines[0]["msg"])
+
+	require.NoError(t, level.Set("debug"))
+	log.Debug("debug after change")
+
+	lines = decodeLines(t, &buf)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "debug after change", lines[1]["msg"])
+	assert.Equal(t, "debug", level.String())
+}
+
+func TestLoggerFieldsFromWithAndContext(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriter(&buf, config.LogConfig{Format: FormatJSON}, newTestLevel(t, "info"))
+
+	ctx := ContextWithFields(context.Background(), "route", "/api/v1/weather/:cep")
+	ctx = ContextWithFields(ctx, "cep", "12345-678")
+
+	log.With("component", "test").WithContext(ctx).Error("failed")
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "ERROR", lines[0]["level"])
+	assert.Equal(t, "test", lines[0]["component"])
+	assert.Equal(t, "/api/v1/weather/:cep", lines[0]["route"])
+	assert.Equal(t, "12345-678", lines[0]["cep"])
+}
+
+func TestLoggerTextFormat(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriter(&buf, config.LogConfig{Format: FormatText}, newTestLevel(t, "info"))
+
+	log.With("cep", "12345-678").Info("done")
+
+	output := buf.String()
+	assert.Contains(t, output, "level=INFO")
+	assert.Contains(t, output, "msg=done")
+	assert.Contains(t, output, "cep=12345-678")
+}
+
+func TestParseLevelRejectsUnknownLevel(t *testing.T) {
+	_, err := ParseLevel("verbose")
+	assert.Error(t, err)
+
+	level := newTestLevel(t, "verbose")
+	assert.Equal(t, "info", level.String())
+}
+
+func TestLoggerCloudFormat(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriter(&buf, config.LogConfig{Format: FormatCloud, ProjectID: "my-project"}, newTestLevel(t, "info"))
+
+	ctx := ContextWithTrace(context.Background(), TraceContext{
+		TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:  "00f067aa0ba902b7",
+		Sampled: true,
+	})
+
+	log.WithContext(ctx).Warn("upstream slow")
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "WARNING", lines[0]["severity"])
+	assert.Equal(t, "upstream slow", lines[0]["message"])
+	assert.Equal(t, "projects/my-project/traces/4bf92f3577b34da6a3ce929d0e0e4736", lines[0][CloudTraceKey])
+	assert.Equal(t, "00f067aa0ba902b7", lines[0][CloudSpanIDKey])
+	assert.Equal(t, true, lines[0][CloudTraceSampledKey])
+	assert.NotContains(t, lines[0], "level")
+	assert.NotContains(t, lines[0], "msg")
+
+	source, ok := lines[0][CloudSourceLocationKey].(map[string]interface{})
+	require.True(t, ok, "Expected sourceLocation attribute")
+	assert.Contains(t, source["file"], "logger_test.go")
+}
@@ -2,7 +2,12 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	logger "github.com/gerps2/desafio-cloud-run/shared/logger"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // MockLogger is an autogenerated mock type for the Logger type
 type MockLogger struct {
@@ -193,6 +198,111 @@ func (_c *MockLogger_Warn_Call) RunAndReturn(run func(string, ...interface{})) *
 	return _c
 }
 
+// With provides a mock function with given fields: fields
+func (_m *MockLogger) With(fields ...interface{}) logger.Logger {
+	var _ca []interface{}
+	_ca = append(_ca, fields...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for With")
+	}
+
+	var r0 logger.Logger
+	if rf, ok := ret.Get(0).(func(...interface{}) logger.Logger); ok {
+		r0 = rf(fields...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(logger.Logger)
+		}
+	}
+
+	return r0
+}
+
+// MockLogger_With_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'With'
+type MockLogger_With_Call struct {
+	*mock.Call
+}
+
+// With is a helper method to define mock.On call
+//   - fields ...interface{}
+func (_e *MockLogger_Expecter) With(fields ...interface{}) *MockLogger_With_Call {
+	return &MockLogger_With_Call{Call: _e.mock.On("With",
+		append([]interface{}{}, fields...)...)}
+}
+
+func (_c *MockLogger_With_Call) Run(run func(fields ...interface{})) *MockLogger_With_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]interface{}, len(args)-0)
+		for i, a := range args[0:] {
+			if a != nil {
+				variadicArgs[i] = a.(interface{})
+			}
+		}
+		run(variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *MockLogger_With_Call) Return(_a0 logger.Logger) *MockLogger_With_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockLogger_With_Call) RunAndReturn(run func(...interface{}) logger.Logger) *MockLogger_With_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WithContext provides a mock function with given fields: ctx
+func (_m *MockLogger) WithContext(ctx context.Context) logger.Logger {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithContext")
+	}
+
+	var r0 logger.Logger
+	if rf, ok := ret.Get(0).(func(context.Context) logger.Logger); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(logger.Logger)
+		}
+	}
+
+	return r0
+}
+
+// MockLogger_WithContext_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithContext'
+type MockLogger_WithContext_Call struct {
+	*mock.Call
+}
+
+// WithContext is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockLogger_Expecter) WithContext(ctx interface{}) *MockLogger_WithContext_Call {
+	return &MockLogger_WithContext_Call{Call: _e.mock.On("WithContext", ctx)}
+}
+
+func (_c *MockLogger_WithContext_Call) Run(run func(ctx context.Context)) *MockLogger_WithContext_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockLogger_WithContext_Call) Return(_a0 logger.Logger) *MockLogger_WithContext_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockLogger_WithContext_Call) RunAndReturn(run func(context.Context) logger.Logger) *MockLogger_WithContext_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockLogger creates a new instance of MockLogger. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockLogger(t interface {
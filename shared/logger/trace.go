@@ -0,0 +1,26 @@
+package logger
+
+import "context"
+
+type TraceContext struct {
+	TraceID string
+	SpanID  string
+	Sampled bool
+}
+
+type traceKey struct{}
+
+func ContextWithTrace(ctx context.Context, trace TraceContext) context.Context {
+	if trace.TraceID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+func TraceFromContext(ctx context.Context) (TraceContext, bool) {
+	if ctx == nil {
+		return TraceContext{}, false
+	}
+	trace, ok := ctx.Value(traceKey{}).(TraceContext)
+	return trace, ok
+}
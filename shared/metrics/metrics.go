@@ -0,0 +1,219 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "weather_api"
+
+const (
+	UpstreamViaCep  = "viacep"
+	UpstreamWeather = "weather"
+)
+
+// Upstream call outcomes.
+const (
+	OutcomeSuccess  = "success"
+	OutcomeNotFound = "not_found"
+	OutcomeError    = "error"
+	OutcomeTimeout  = "timeout"
+)
+
+// Reasons an upstream call was rejected by its client-side budget.
+const (
+	BudgetReasonRate  = "rate"
+	BudgetReasonQuota = "quota"
+)
+
+// Configuration reload triggers and results.
+const (
+	ReloadTriggerSignal = "signal"
+	ReloadTriggerFile   = "file"
+
+	ReloadSuccess  = "success"
+	ReloadRejected = "rejected"
+)
+
+// Weather lookup results, used to derive business rates such as the share of
+// requests with an invalid CEP.
+const (
+	LookupSuccess        = "success"
+	LookupInvalidCep     = "invalid_cep"
+	LookupCepNotFound    = "cep_not_found"
+	LookupAddressFailure = "address_failure"
+	LookupWeatherFailure = "weather_failure"
+	LookupBudgetExceeded = "budget_exceeded"
+)
+
+// Metrics owns a dedicated registry instead of the Prometheus global one so
+// each instance (and each test) starts from a clean state.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests     *prometheus.CounterVec
+	httpErrors       *prometheus.CounterVec
+	httpDuration     *prometheus.HistogramVec
+	upstreamCalls    *prometheus.CounterVec
+	upstreamDuration *prometheus.HistogramVec
+	weatherLookups   *prometheus.CounterVec
+	rateLimited      *prometheus.CounterVec
+	budgetRemaining  *prometheus.GaugeVec
+	budgetRejections *prometheus.CounterVec
+	configReloads    *prometheus.CounterVec
+	streamSubs       prometheus.Gauge
+	streamCities     prometheus.Gauge
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests handled, by route, method and status code.",
+		}, []string{"route", "method", "status"}),
+		httpErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_request_errors_total",
+			Help:      "HTTP requests answered with a 5xx status code.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency, by route, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		upstreamCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "upstream_requests_total",
+			Help:      "Calls to upstream APIs, by upstream and outcome.",
+		}, []string{"upstream", "outcome"}),
+		upstreamDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upstream_request_duration_seconds",
+			Help:      "Upstream API call latency, by upstream and outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"upstream", "outcome"}),
+		weatherLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "weather_lookups_total",
+			Help:      "Weather by CEP lookups, by result.",
+		}, []string{"result"}),
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rate_limited_requests_total",
+			Help:      "Requests rejected by the rate limiter, by route.",
+		}, []string{"route"}),
+		budgetRemaining: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "upstream_budget_remaining",
+			Help:      "Calls left in the current budget period of an upstream API.",
+		}, []string{"upstream"}),
+		budgetRejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "upstream_budget_rejections_total",
+			Help:      "Upstream calls rejected by the client-side budget, by upstream and reason.",
+		}, []string{"upstream", "reason"}),
+		configReloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "config_reloads_total",
+			Help:      "Configuration reloads, by trigger and result.",
+		}, []string{"trigger", "result"}),
+		streamSubs: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "stream_subscriptions",
+			Help:      "Live weather subscriptions open over SSE and WebSocket.",
+		}),
+		streamCities: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "stream_polled_cities",
+			Help:      "Cities polled for live weather subscribers.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpErrors,
+		m.httpDuration,
+		m.upstreamCalls,
+		m.upstreamDuration,
+		m.weatherLookups,
+		m.rateLimited,
+		m.budgetRemaining,
+		m.budgetRejections,
+		m.configReloads,
+		m.streamSubs,
+		m.streamCities,
+	)
+
+	return m
+}
+
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+func (m *Metrics) ObserveHTTPRequest(route, method string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(route, method, code).Inc()
+	m.httpDuration.WithLabelValues(route, method, code).Observe(duration.Seconds())
+	if status >= http.StatusInternalServerError {
+		m.httpErrors.WithLabelValues(route, method, code).Inc()
+	}
+}
+
+func (m *Metrics) ObserveUpstreamCall(upstream, outcome string, duration time.Duration) {
+	m.upstreamCalls.WithLabelValues(upstream, outcome).Inc()
+	m.upstreamDuration.WithLabelValues(upstream, outcome).Observe(duration.Seconds())
+}
+
+func (m *Metrics) IncWeatherLookup(result string) {
+	m.weatherLookups.WithLabelValues(result).Inc()
+}
+
+func (m *Metrics) IncRateLimited(route string) {
+	m.rateLimited.WithLabelValues(route).Inc()
+}
+
+func (m *Metrics) SetUpstreamBudgetRemaining(upstream string, remaining int64) {
+	m.budgetRemaining.WithLabelValues(upstream).Set(float64(remaining))
+}
+
+func (m *Metrics) IncUpstreamBudgetRejection(upstream, reason string) {
+	m.budgetRejections.WithLabelValues(upstream, reason).Inc()
+}
+
+func (m *Metrics) IncConfigReload(trigger, result string) {
+	m.configReloads.WithLabelValues(trigger, result).Inc()
+}
+
+func (m *Metrics) SetStreamSubscriptions(subscriptions, cities int) {
+	m.streamSubs.Set(float64(subscriptions))
+	m.streamCities.Set(float64(cities))
+}
+
+// Outcome classifies the result of an upstream call for the outcome label.
+func Outcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, context.DeadlineExceeded):
+		return OutcomeTimeout
+	default:
+		return OutcomeError
+	}
+}
@@ -0,0 +1,316 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+)
+
+const Version = "3.0.3"
+
+// Security scheme names declared by New.
+const (
+	SchemeBearer       = "bearerAuth"
+	SchemeAPIKeyHeader = "apiKeyHeader"
+	SchemeAPIKeyQuery  = "apiKeyQuery"
+)
+
+const errorResponseSchema = "ErrorResponse"
+
+// Document is the subset of OpenAPI 3.0 used to describe this API. Routes are
+// added next to their registration so the spec follows the code.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lower-case HTTP methods to their operation.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name        string      `json:"name"`
+	In          string      `json:"in"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required"`
+	Schema      *Schema     `json:"schema"`
+	Example     interface{} `json:"example,omitempty"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+// New returns a document with the error envelope and the JWT and API key
+// security schemes already declared.
+func New(info Info, apiKeyHeader, apiKeyQuery string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{
+				errorResponseSchema: {
+					Type: "object",
+					Properties: map[string]*Schema{
+						"data":       {Nullable: true, Description: "Always null on errors"},
+						"message":    {Type: "string"},
+						"causes":     {Type: "array", Items: &Schema{Type: "string"}},
+						"request_id": {Type: "string", Description: "Echoes the X-Request-ID header"},
+					},
+					Required: []string{"data", "message"},
+				},
+			},
+			SecuritySchemes: map[string]SecurityScheme{
+				SchemeBearer: {Type: "http", Scheme: "bearer", BearerFormat: "JWT",
+					Description: "RS256/ES256 token validated against the configured JWKS"},
+				SchemeAPIKeyHeader: {Type: "apiKey", In: "header", Name: apiKeyHeader},
+				SchemeAPIKeyQuery:  {Type: "apiKey", In: "query", Name: apiKeyQuery},
+			},
+		},
+	}
+}
+
+var ginParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// Path converts a gin route such as /weather/:cep to /weather/{cep}.
+func Path(route string) string {
+	return ginParam.ReplaceAllString(route, "{$1}")
+}
+
+// Add describes the operation served by method on the gin route.
+func (d *Document) Add(method, route string, op Operation) {
+	path := Path(route)
+	if d.Paths[path] == nil {
+		d.Paths[path] = PathItem{}
+	}
+	d.Paths[path][strings.ToLower(method)] = &op
+}
+
+// Operation returns the operation for method on the gin route, if described.
+func (d *Document) Operation(method, route string) (*Operation, bool) {
+	op, ok := d.Paths[Path(route)][strings.ToLower(method)]
+	return op, ok
+}
+
+// Schema registers v's schema under name and returns a reference to it.
+func (d *Document) Schema(name string, v interface{}) *Schema {
+	d.Components.Schemas[name] = SchemaOf(v)
+	return Ref(name)
+}
+
+// Secured lists the accepted credentials for an operation requiring scopes.
+func Secured(scopes ...string) []map[string][]string {
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return []map[string][]string{
+		{SchemeBearer: scopes},
+		{SchemeAPIKeyHeader: scopes},
+		{SchemeAPIKeyQuery: scopes},
+	}
+}
+
+// JSON is a response body of the given schema.
+func JSON(description string, schema *Schema) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{"application/json": {Schema: schema}},
+	}
+}
+
+// Success wraps data in the APIResponse envelope returned by
+// RespondWithSuccess.
+func Success(description string, data *Schema) Response {
+	return JSON(description, &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"data":    data,
+			"message": {Type: "string"},
+		},
+		Required: []string{"data", "message"},
+	})
+}
+
+// NotModified is the empty 304 answered when If-None-Match or
+// If-Modified-Since shows the client already has the response.
+func NotModified() Response {
+	return Response{
+		Description: "Not modified since the ETag or Last-Modified the client sent",
+		Headers: map[string]Header{
+			"ETag":          {Schema: &Schema{Type: "string"}},
+			"Cache-Control": {Schema: &Schema{Type: "string"}},
+		},
+	}
+}
+
+// ErrorCode documents an APIError code that a response may stand for. The
+// code is not part of the body; clients tell errors apart by status and
+// message.
+type ErrorCode struct {
+	Code    string
+	Message string
+}
+
+// Error is an error envelope response listing the codes behind it.
+func Error(description string, codes ...ErrorCode) Response {
+	if len(codes) > 0 {
+		lines := make([]string, len(codes))
+		for i, code := range codes {
+			lines[i] = fmt.Sprintf("- `%s`: %s", code.Code, code.Message)
+		}
+		description += "\n\n" + strings.Join(lines, "\n")
+	}
+	return JSON(description, Ref(errorResponseSchema))
+}
+
+// Responses merges response sets, later ones winning on the same status.
+func Responses(sets ...map[string]Response) map[string]Response {
+	merged := map[string]Response{}
+	for _, set := range sets {
+		for status, response := range set {
+			merged[status] = response
+		}
+	}
+	return merged
+}
+
+// Negotiated documents an operation that honors the Accept header: every
+// response is offered in each media type, and the format override and the
+// 406 response are added.
+func Negotiated(op Operation, mediaTypes []string) Operation {
+	formats := make([]string, 0, len(mediaTypes))
+	for _, mediaType := range mediaTypes {
+		// application/x-protobuf -> protobuf, text/csv -> csv
+		formats = append(formats, mediaType[strings.LastIndexAny(mediaType, "/-")+1:])
+	}
+	op.Parameters = append(op.Parameters, Parameter{
+		Name:        "format",
+		In:          "query",
+		Description: "Response format, overrides the Accept header",
+		Schema:      &Schema{Type: "string", Enum: formats},
+	})
+
+	responses := Responses(op.Responses, map[string]Response{
+		Status(http.StatusNotAcceptable): Error("Response format not supported",
+			ErrorCode{sharedErrors.CodeNotAcceptable, "neither Accept nor format names a supported media type"}),
+	})
+	for status, response := range responses {
+		if schema, ok := response.Content["application/json"]; ok {
+			content := make(map[string]MediaType, len(mediaTypes))
+			for _, mediaType := range mediaTypes {
+				content[mediaType] = schema
+			}
+			response.Content = content
+			responses[status] = response
+		}
+	}
+	op.Responses = responses
+	return op
+}
+
+// Localized documents the Accept-Language header of an operation whose
+// messages are translated; locales[0] is the default.
+func Localized(op Operation, locales []string) Operation {
+	op.Parameters = append(op.Parameters, Parameter{
+		Name:        "Accept-Language",
+		In:          "header",
+		Description: fmt.Sprintf("Language of error messages and weather conditions, one of %s; defaults to %s", strings.Join(locales, ", "), locales[0]),
+		Schema:      &Schema{Type: "string"},
+		Example:     locales[len(locales)-1],
+	})
+	return op
+}
+
+// Versioned documents the version header of an operation: the request may
+// name one of versions, the 200 response echoes the version served and the
+// 400 response rejects unknown or conflicting versions. A deprecated
+// operation is flagged and its 200 response documents the Deprecation, Sunset
+// and successor Link headers.
+func Versioned(op Operation, header string, versions []string, deprecated bool) Operation {
+	op.Parameters = append(op.Parameters, Parameter{
+		Name:        header,
+		In:          "header",
+		Description: fmt.Sprintf("API version, one of %s; under a versioned path it must name the path's version", strings.Join(versions, ", ")),
+		Schema:      &Schema{Type: "string", Enum: versions},
+	})
+
+	responses := Responses(op.Responses, map[string]Response{
+		Status(http.StatusBadRequest): Error("API version not supported",
+			ErrorCode{sharedErrors.CodeUnsupportedAPIVersion, header + " names an unknown version or conflicts with the path"}),
+	})
+	if success, ok := responses[Status(http.StatusOK)]; ok {
+		headers := map[string]Header{header: {Description: "Version that served the response", Schema: &Schema{Type: "string"}}}
+		if deprecated {
+			headers["Deprecation"] = Header{Description: "When the version was deprecated, as @<unix seconds>", Schema: &Schema{Type: "string"}}
+			headers["Sunset"] = Header{Description: "HTTP date after which the version may stop responding", Schema: &Schema{Type: "string"}}
+			headers["Link"] = Header{Description: "The successor-version route", Schema: &Schema{Type: "string"}}
+		}
+		for name, h := range success.Headers {
+			headers[name] = h
+		}
+		success.Headers = headers
+		responses[Status(http.StatusOK)] = success
+	}
+	op.Responses = responses
+	op.Deprecated = deprecated
+	return op
+}
+
+// Status formats an HTTP status code as a responses key.
+func Status(code int) string {
+	return fmt.Sprint(code)
+}
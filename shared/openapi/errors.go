@@ -0,0 +1,42 @@
+package openapi
+
+import (
+	"net/http"
+
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+)
+
+// CommonErrors are the responses any route can produce through the global
+// middleware. Secured routes add the authentication failures.
+func CommonErrors(secured bool) map[string]Response {
+	responses := map[string]Response{
+		Status(http.StatusRequestEntityTooLarge): Error("Request body too large",
+			ErrorCode{sharedErrors.CodePayloadTooLarge, "body, or gzip-inflated body, exceeds SERVER_MAX_BODY_BYTES"}),
+		Status(http.StatusUnsupportedMediaType): Error("Request body encoding not supported",
+			ErrorCode{sharedErrors.CodeUnsupportedMediaType, "Content-Encoding other than gzip"}),
+		Status(http.StatusTooManyRequests): withRetryHeaders(Error("Rate limit exceeded",
+			ErrorCode{sharedErrors.CodeTooManyRequests, "per-client rate limit exhausted"})),
+		Status(http.StatusInternalServerError): Error("Unexpected failure",
+			ErrorCode{sharedErrors.CodeInternalError, "unhandled error or recovered panic"}),
+		Status(http.StatusGatewayTimeout): Error("Request timed out",
+			ErrorCode{sharedErrors.CodeServiceTimeout, "request exceeded REQUEST_TIMEOUT_SEC"}),
+	}
+	if secured {
+		responses[Status(http.StatusUnauthorized)] = Error("Missing or invalid credentials",
+			ErrorCode{sharedErrors.CodeUnauthorized, "no credentials, invalid API key or invalid token"})
+		responses[Status(http.StatusForbidden)] = Error("Credentials lack access",
+			ErrorCode{sharedErrors.CodeForbidden, "missing scope or API key quota exceeded"})
+	}
+	return responses
+}
+
+func withRetryHeaders(response Response) Response {
+	response.Headers = map[string]Header{
+		"Retry-After":         {Description: "Seconds until a request is allowed", Schema: &Schema{Type: "integer"}},
+		"RateLimit-Limit":     {Schema: &Schema{Type: "integer"}},
+		"RateLimit-Remaining": {Schema: &Schema{Type: "integer"}},
+		"RateLimit-Reset":     {Schema: &Schema{Type: "integer"}},
+		"RateLimit-Policy":    {Schema: &Schema{Type: "string"}},
+	}
+	return response
+}
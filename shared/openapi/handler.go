@@ -0,0 +1,43 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed swagger-ui.html
+var swaggerUI string
+
+var swaggerUITemplate = template.Must(template.New("swagger-ui").Parse(swaggerUI))
+
+// Handler serves the document as JSON. It is encoded on first use, once every
+// route has been described.
+func Handler(doc *Document) gin.HandlerFunc {
+	var (
+		once    sync.Once
+		encoded []byte
+		err     error
+	)
+	return func(c *gin.Context) {
+		once.Do(func() { encoded, err = json.Marshal(doc) })
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		c.Data(http.StatusOK, "application/json; charset=utf-8", encoded)
+	}
+}
+
+// UIHandler serves Swagger UI pointed at specURL.
+func UIHandler(title, specURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		_ = swaggerUITemplate.Execute(c.Writer, map[string]string{"Title": title, "SpecURL": specURL})
+	}
+}
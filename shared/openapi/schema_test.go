@@ -0,0 +1,90 @@
+package openapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type embedded struct {
+	ID string `json:"id"`
+}
+
+type sample struct {
+	embedded
+	Name     string            `json:"name"`
+	Count    int64             `json:"count,omitempty"`
+	Ratio    float64           `json:"ratio"`
+	Tags     []string          `json:"tags,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	At       time.Time         `json:"at"`
+	Next     *sample           `json:"-"`
+	internal string
+}
+
+func TestSchemaOf(t *testing.T) {
+	schema := SchemaOf(sample{})
+
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, []string{"id", "name", "ratio", "at"}, schema.Required)
+	assert.Equal(t, &Schema{Type: "integer", Format: "int64"}, schema.Properties["count"])
+	assert.Equal(t, &Schema{Type: "number", Format: "double"}, schema.Properties["ratio"])
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Type: "string"}}, schema.Properties["tags"])
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}, schema.Properties["labels"])
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, schema.Properties["at"])
+	assert.NotContains(t, schema.Properties, "Next")
+	assert.NotContains(t, schema.Properties, "internal")
+}
+
+func TestPath(t *testing.T) {
+	assert.Equal(t, "/api/v1/weather/{cep}", Path("/api/v1/weather/:cep"))
+	assert.Equal(t, "/files/{path}", Path("/files/*path"))
+	assert.Equal(t, "/health", Path("/health"))
+}
+
+func TestLocalized(t *testing.T) {
+	op := Localized(Operation{Parameters: []Parameter{{Name: "cep", In: "path"}}}, []string{"en", "pt-BR", "es"})
+
+	require.Len(t, op.Parameters, 2)
+	assert.Equal(t, "Accept-Language", op.Parameters[1].Name)
+	assert.Equal(t, "header", op.Parameters[1].In)
+	assert.Contains(t, op.Parameters[1].Description, "en, pt-BR, es")
+}
+
+func TestVersioned(t *testing.T) {
+	op := Versioned(Operation{
+		Responses: map[string]Response{"200": Success("ok", &Schema{Type: "string"})},
+	}, "API-Version", []string{"v1", "v2"}, true)
+
+	assert.True(t, op.Deprecated)
+	assert.Equal(t, "API-Version", op.Parameters[0].Name)
+	assert.Equal(t, []string{"v1", "v2"}, op.Parameters[0].Schema.Enum)
+	assert.Contains(t, op.Responses, "400")
+	for _, header := range []string{"API-Version", "Deprecation", "Sunset", "Link"} {
+		assert.Contains(t, op.Responses["200"].Headers, header)
+	}
+
+	current := Versioned(Operation{
+		Responses: map[string]Response{"200": Success("ok", &Schema{Type: "string"})},
+	}, "API-Version", []string{"v1", "v2"}, false)
+
+	assert.False(t, current.Deprecated)
+	assert.Len(t, current.Responses["200"].Headers, 1)
+}
+
+func TestNegotiated(t *testing.T) {
+	op := Negotiated(Operation{
+		Responses: map[string]Response{
+			"200": Success("ok", &Schema{Type: "string"}),
+		},
+	}, []string{"application/json", "text/csv", "application/x-protobuf"})
+
+	assert.Equal(t, "format", op.Parameters[0].Name)
+	assert.Equal(t, []string{"json", "csv", "protobuf"}, op.Parameters[0].Schema.Enum)
+	assert.Contains(t, op.Responses, "406")
+	assert.Len(t, op.Responses["200"].Content, 3)
+	assert.Equal(t, op.Responses["200"].Content["application/json"], op.Responses["200"].Content["text/csv"])
+	assert.Contains(t, op.Responses["406"].Content, "application/x-protobuf")
+}
@@ -0,0 +1,25 @@
+package providers
+
+import (
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/auth"
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/httpclient"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+)
+
+const jwksTimeout = 10 * time.Second
+
+func ProvideAPIKeyAuthenticator(cfg *config.Config, log logger.Logger) (*auth.APIKeyAuthenticator, error) {
+	return auth.NewAPIKeyAuthenticator(cfg, auth.NewMemoryUsageStore(), log)
+}
+
+func ProvideJWTAuthenticator(cfg *config.Config, factory *httpclient.Factory, log logger.Logger) (*auth.JWTAuthenticator, error) {
+	return auth.NewJWTAuthenticator(cfg, factory.Client(jwksTimeout), log)
+}
+
+// ProvideAuthGuard accepts a bearer token first, then an API key.
+func ProvideAuthGuard(cfg *config.Config, log logger.Logger, jwtAuth *auth.JWTAuthenticator, apiKeyAuth *auth.APIKeyAuthenticator) *auth.Guard {
+	return auth.NewGuard(cfg, log, jwtAuth, apiKeyAuth)
+}
@@ -0,0 +1,27 @@
+package providers
+
+import (
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/health"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+	"github.com/gerps2/desafio-cloud-run/shared/ratelimit"
+	"github.com/gerps2/desafio-cloud-run/shared/reload"
+)
+
+// ProvideReloader subscribes the runtime-tunable components to configuration
+// reloads.
+func ProvideReloader(store *config.Store, level *logger.Level, registry *health.Registry, limiter *ratelimit.Limiter, m *metrics.Metrics, log logger.Logger) *reload.Reloader {
+	store.OnChange(func(cfg *config.Config) {
+		if err := level.Set(cfg.Log.Level); err != nil {
+			log.Error("Failed to apply log level: %v", err)
+		}
+		registry.SetCacheTTL(time.Duration(cfg.Health.CacheTTLSec) * time.Second)
+		if err := limiter.Apply(cfg); err != nil {
+			log.Error("Failed to apply rate limit configuration: %v", err)
+		}
+	})
+	return reload.NewReloader(store, m, log)
+}
@@ -1,23 +1,34 @@
 package providers
 
 import (
+	"time"
+
 	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/httpclient"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
 	viacep "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/viapcep"
 	"github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather"
+	"github.com/gerps2/desafio-cloud-run/shared/telemetry"
 )
 
-func ProvideViaCepClient(cfg *config.Config) *viacep.ViaCepClient {
-	return viacep.NewClient(cfg.ExternalAPIs.ViaCep.BaseURL)
+func ProvideViaCepClient(cfg *config.Config, factory *httpclient.Factory, tel *telemetry.Telemetry, m *metrics.Metrics) *viacep.ViaCepClient {
+	client := factory.Client(time.Duration(cfg.ExternalAPIs.ViaCep.TimeoutSec) * time.Second)
+	return viacep.NewClient(cfg.ExternalAPIs.ViaCep.BaseURL, client, tel, m)
 }
 
 func ProvideViaCepRepository(client *viacep.ViaCepClient) viacep.ViaCepRepositoryInterface {
 	return viacep.NewViaCepRepository(client)
 }
 
-func ProvideWeatherClient(cfg *config.Config) *weather.WeatherClient {
-	return weather.NewClient(cfg.ExternalAPIs.Weather.BaseURL, cfg.ExternalAPIs.Weather.APIKey)
+func ProvideWeatherClient(cfg *config.Config, factory *httpclient.Factory, tel *telemetry.Telemetry, m *metrics.Metrics) *weather.WeatherClient {
+	client := factory.Client(time.Duration(cfg.ExternalAPIs.Weather.TimeoutSec) * time.Second)
+	return weather.NewClient(cfg.ExternalAPIs.Weather.BaseURL, cfg.ExternalAPIs.Weather.APIKey, client, tel, m)
+}
+
+func ProvideWeatherBudget(cfg *config.Config, m *metrics.Metrics) (*weather.Budget, error) {
+	return weather.NewBudget(cfg.ExternalAPIs.Weather.Budget, m)
 }
 
-func ProvideWeatherRepository(client *weather.WeatherClient) weather.WeatherRepositoryInterface {
-	return weather.NewWeatherRepository(client)
+func ProvideWeatherRepository(client *weather.WeatherClient, budget *weather.Budget) weather.WeatherRepositoryInterface {
+	return weather.NewBudgetedWeatherRepository(weather.NewWeatherRepository(client), budget)
 }
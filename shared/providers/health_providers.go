@@ -0,0 +1,45 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/health"
+	viacep "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/viapcep"
+	"github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather"
+)
+
+func ProvideHealthRegistry(cfg *config.Config, viaCepClient *viacep.ViaCepClient, weatherClient *weather.WeatherClient, weatherBudget *weather.Budget) *health.Registry {
+	registry := health.NewRegistry(time.Duration(cfg.Health.CacheTTLSec) * time.Second)
+	timeout := time.Duration(cfg.Health.ProbeTimeoutSec) * time.Second
+
+	registry.Register(health.Check{
+		Name:     "viacep",
+		Critical: true,
+		Timeout:  timeout,
+		Probe:    viaCepClient.Ping,
+	})
+	registry.Register(health.Check{
+		Name:     "weather",
+		Critical: true,
+		Timeout:  timeout,
+		Probe:    weatherProbe(weatherClient, weatherBudget),
+	})
+
+	return registry
+}
+
+// weatherProbe degrades readiness instead of failing it once the quota runs
+// out, since every instance shares the plan and would go not ready together.
+func weatherProbe(client *weather.WeatherClient, budget *weather.Budget) health.Probe {
+	probe := weather.Probe(client, budget)
+	return func(ctx context.Context) error {
+		err := probe(ctx)
+		if errors.Is(err, weather.ErrBudgetExceeded) {
+			return health.Degraded(err)
+		}
+		return err
+	}
+}
@@ -0,0 +1,14 @@
+package providers
+
+import (
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/openapi"
+)
+
+func ProvideOpenAPIDocument(cfg *config.Config) *openapi.Document {
+	return openapi.New(openapi.Info{
+		Title:       "Weather API",
+		Version:     "1.0.0",
+		Description: "Current temperature by Brazilian zipcode (CEP). Responses use the APIResponse envelope; error responses list the internal error codes they stand for.",
+	}, cfg.Auth.APIKey.Header, cfg.Auth.APIKey.QueryParam)
+}
@@ -0,0 +1,12 @@
+package providers
+
+import (
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+	"github.com/gerps2/desafio-cloud-run/shared/ratelimit"
+)
+
+func ProvideRateLimiter(cfg *config.Config, m *metrics.Metrics, log logger.Logger) (*ratelimit.Limiter, error) {
+	return ratelimit.NewLimiter(cfg, ratelimit.NewMemoryStore(), m, log)
+}
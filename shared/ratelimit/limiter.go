@@ -0,0 +1,216 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/auth"
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	sharedErrors "github.com/gerps2/desafio-cloud-run/shared/errors"
+	httpShared "github.com/gerps2/desafio-cloud-run/shared/http"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	KeyByPrincipal = "principal"
+	KeyByIP        = "ip"
+	KeyByRoute     = "route"
+)
+
+const unmatchedRoute = "unmatched"
+
+// Standard rate limit headers from the IETF httpapi RateLimit header fields
+// draft.
+const (
+	HeaderLimit     = "RateLimit-Limit"
+	HeaderRemaining = "RateLimit-Remaining"
+	HeaderReset     = "RateLimit-Reset"
+	HeaderPolicy    = "RateLimit-Policy"
+	HeaderRetry     = "Retry-After"
+)
+
+var (
+	msgRateLimitExceeded = sharedErrors.NewMessage("rate_limit.exceeded", "Rate limit exceeded")
+	msgRetryIn           = sharedErrors.NewMessage("rate_limit.retry_in", "Retry in %d seconds")
+)
+
+type Limiter struct {
+	policy  atomic.Pointer[policy]
+	store   Store
+	metrics *metrics.Metrics
+	logger  logger.Logger
+	now     func() time.Time
+}
+
+// policy is the parsed rate limit configuration, swapped as a whole when the
+// configuration is reloaded.
+type policy struct {
+	enabled     bool
+	keyBy       string
+	limit       Limit
+	routes      map[string]Limit
+	exemptPaths []string
+}
+
+func NewLimiter(cfg *config.Config, store Store, m *metrics.Metrics, log logger.Logger) (*Limiter, error) {
+	limiter := &Limiter{
+		store:   store,
+		metrics: m,
+		logger:  log,
+		now:     time.Now,
+	}
+	if err := limiter.Apply(cfg); err != nil {
+		return nil, err
+	}
+	return limiter, nil
+}
+
+// Apply replaces the limits with those of cfg. Buckets in the store are kept,
+// so clients keep their remaining tokens across changes.
+func (l *Limiter) Apply(cfg *config.Config) error {
+	p, err := newPolicy(cfg.RateLimit)
+	if err != nil {
+		return err
+	}
+	l.policy.Store(p)
+	return nil
+}
+
+func newPolicy(rl config.RateLimitConfig) (*policy, error) {
+	p := &policy{
+		enabled:     rl.Enabled,
+		keyBy:       rl.KeyBy,
+		exemptPaths: rl.ExemptPaths,
+		routes:      map[string]Limit{},
+	}
+	if !rl.Enabled {
+		return p, nil
+	}
+
+	switch rl.KeyBy {
+	case KeyByPrincipal, KeyByIP, KeyByRoute:
+	default:
+		return nil, fmt.Errorf("invalid RATE_LIMIT_KEY_BY %q: expected principal, ip or route", rl.KeyBy)
+	}
+	if rl.Requests <= 0 || rl.PeriodSec <= 0 {
+		return nil, fmt.Errorf("RATE_LIMIT_REQUESTS and RATE_LIMIT_PERIOD_SEC must be positive")
+	}
+
+	period := time.Duration(rl.PeriodSec) * time.Second
+	p.limit = newLimit(rl.Requests, period, rl.Burst)
+
+	for _, entry := range strings.Split(rl.Routes, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		idx := strings.LastIndex(entry, "=")
+		requests, err := strconv.Atoi(entry[idx+1:])
+		if idx <= 0 || err != nil || requests <= 0 {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_ROUTES entry %q: expected route=requests", entry)
+		}
+		p.routes[entry[:idx]] = newLimit(requests, period, 0)
+	}
+
+	return p, nil
+}
+
+func newLimit(requests int, period time.Duration, burst int) Limit {
+	if burst <= 0 {
+		burst = requests
+	}
+	return Limit{Requests: requests, Period: period, Burst: burst}
+}
+
+// Middleware must run before authentication, so requests the guard rejects
+// with 401 are limited too. It keys requests by client IP or route; with
+// KeyByPrincipal every request is also limited by client IP here, and
+// PrincipalMiddleware keys authenticated requests by their principal. Store
+// errors fail open.
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := l.policy.Load()
+		keyBy := p.keyBy
+		if keyBy == KeyByPrincipal {
+			keyBy = KeyByIP
+		}
+		l.limit(c, p, keyBy)
+	}
+}
+
+// PrincipalMiddleware must run after authentication. With KeyByPrincipal it
+// limits authenticated principals on their own buckets, shared across IPs;
+// requests without a principal were already limited by Middleware.
+func (l *Limiter) PrincipalMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := l.policy.Load()
+		if _, ok := auth.PrincipalFromContext(c.Request.Context()); !ok || p.keyBy != KeyByPrincipal {
+			c.Next()
+			return
+		}
+		l.limit(c, p, KeyByPrincipal)
+	}
+}
+
+func (l *Limiter) limit(c *gin.Context, p *policy, keyBy string) {
+	if !p.enabled || auth.IsPublicPath(c.Request.URL.Path, p.exemptPaths) {
+		c.Next()
+		return
+	}
+
+	route := c.FullPath()
+	if route == "" {
+		route = unmatchedRoute
+	}
+	limit, key := p.limit, bucketKey(c, keyBy, route)
+	if override, ok := p.routes[route]; ok {
+		limit, key = override, key+"|"+route
+	}
+
+	result, err := l.store.Take(c.Request.Context(), key, limit, l.now())
+	if err != nil {
+		l.logger.WithContext(c.Request.Context()).Error("Rate limit store failed, allowing request: %v", err)
+		c.Next()
+		return
+	}
+
+	c.Header(HeaderLimit, strconv.Itoa(limit.Burst))
+	c.Header(HeaderRemaining, strconv.Itoa(result.Remaining))
+	c.Header(HeaderReset, strconv.Itoa(ceilSeconds(result.Reset)))
+	c.Header(HeaderPolicy, fmt.Sprintf("%d;w=%d;burst=%d", limit.Requests, int(limit.Period.Seconds()), limit.Burst))
+
+	if !result.Allowed {
+		retryAfter := ceilSeconds(result.RetryAfter)
+		c.Header(HeaderRetry, strconv.Itoa(retryAfter))
+		l.metrics.IncRateLimited(route)
+		httpShared.RespondWithTooManyRequests(c, msgRateLimitExceeded.Format(),
+			[]sharedErrors.Text{msgRetryIn.Format(retryAfter)})
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
+func bucketKey(c *gin.Context, keyBy, route string) string {
+	switch keyBy {
+	case KeyByRoute:
+		return "route:" + route
+	case KeyByPrincipal:
+		if principal, ok := auth.PrincipalFromContext(c.Request.Context()); ok {
+			return "principal:" + principal.Method + ":" + principal.Subject
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,248 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/auth"
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	httpShared "github.com/gerps2/desafio-cloud-run/shared/http"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type limiterFixture struct {
+	router  *gin.Engine
+	limiter *Limiter
+	metrics *metrics.Metrics
+	now     time.Time
+}
+
+func setupLimiterFixture(t *testing.T, rl config.RateLimitConfig, store Store) *limiterFixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	rl.Enabled = true
+	rl.PeriodSec = 60
+	rl.ExemptPaths = []string{"/health"}
+	cfg := &config.Config{Log: config.LogConfig{Level: "error"}, RateLimit: rl}
+	log := logger.NewWithWriter(io.Discard, cfg.Log, logger.NewLevel(cfg))
+
+	fixture := &limiterFixture{metrics: metrics.New(), now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	limiter, err := NewLimiter(cfg, store, fixture.metrics, log)
+	require.NoError(t, err)
+	limiter.now = func() time.Time { return fixture.now }
+	fixture.limiter = limiter
+
+	fixture.router = gin.New()
+	require.NoError(t, fixture.router.SetTrustedProxies([]string{"10.0.0.0/8"}))
+	fixture.router.Use(limiter.Middleware())
+	// Stands in for the auth guard.
+	fixture.router.Use(func(c *gin.Context) {
+		if c.GetHeader("X-Test-Reject") != "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		if subject := c.GetHeader("X-Test-Subject"); subject != "" {
+			ctx := auth.ContextWithPrincipal(c.Request.Context(), auth.Principal{Subject: subject, Method: auth.MethodAPIKey})
+			c.Request = c.Request.WithContext(ctx)
+		}
+	})
+	fixture.router.Use(limiter.PrincipalMiddleware())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	fixture.router.GET("/health", ok)
+	fixture.router.GET("/api/v1/weather/:cep", ok)
+	fixture.router.GET("/api/v1/other", ok)
+
+	return fixture
+}
+
+func (f *limiterFixture) get(path, remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = remoteAddr
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestLimiterTokenBucket(t *testing.T) {
+	fixture := setupLimiterFixture(t, config.RateLimitConfig{Requests: 2, KeyBy: KeyByIP}, NewMemoryStore())
+
+	w := fixture.get("/api/v1/weather/01001000", "203.0.113.1:1234", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get(HeaderLimit))
+	assert.Equal(t, "1", w.Header().Get(HeaderRemaining))
+	assert.Equal(t, "30", w.Header().Get(HeaderReset))
+	assert.Equal(t, "2;w=60;burst=2", w.Header().Get(HeaderPolicy))
+
+	require.Equal(t, http.StatusOK, fixture.get("/api/v1/weather/01001000", "203.0.113.1:1234", nil).Code)
+
+	w = fixture.get("/api/v1/weather/01001000", "203.0.113.1:1234", nil)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get(HeaderRetry))
+	assert.Equal(t, "0", w.Header().Get(HeaderRemaining))
+
+	var response httpShared.APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Rate limit exceeded", response.Message)
+	expectedMetric := `
+# HELP weather_api_rate_limited_requests_total Requests rejected by the rate limiter, by route.
+# TYPE weather_api_rate_limited_requests_total counter
+weather_api_rate_limited_requests_total{route="/api/v1/weather/:cep"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(fixture.metrics.Registry(), strings.NewReader(expectedMetric), "weather_api_rate_limited_requests_total"))
+
+	// Another client has its own bucket, and exempt paths are never limited.
+	assert.Equal(t, http.StatusOK, fixture.get("/api/v1/weather/01001000", "203.0.113.2:1234", nil).Code)
+	assert.Equal(t, http.StatusOK, fixture.get("/health", "203.0.113.1:1234", nil).Code)
+
+	// One token is refilled every 30 seconds.
+	fixture.now = fixture.now.Add(30 * time.Second)
+	assert.Equal(t, http.StatusOK, fixture.get("/api/v1/weather/01001000", "203.0.113.1:1234", nil).Code)
+	assert.Equal(t, http.StatusTooManyRequests, fixture.get("/api/v1/weather/01001000", "203.0.113.1:1234", nil).Code)
+}
+
+func TestLimiterKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		rl       config.RateLimitConfig
+		first    func(f *limiterFixture) *httptest.ResponseRecorder
+		second   func(f *limiterFixture) *httptest.ResponseRecorder
+		separate bool
+	}{
+		{
+			name: "Principal shares its bucket across IPs",
+			rl:   config.RateLimitConfig{Requests: 1, KeyBy: KeyByPrincipal},
+			first: func(f *limiterFixture) *httptest.ResponseRecorder {
+				return f.get("/api/v1/other", "203.0.113.1:1", map[string]string{"X-Test-Subject": "partner"})
+			},
+			second: func(f *limiterFixture) *httptest.ResponseRecorder {
+				return f.get("/api/v1/other", "203.0.113.2:1", map[string]string{"X-Test-Subject": "partner"})
+			},
+			separate: false,
+		},
+		{
+			name: "Anonymous requests fall back to the client IP",
+			rl:   config.RateLimitConfig{Requests: 1, KeyBy: KeyByPrincipal},
+			first: func(f *limiterFixture) *httptest.ResponseRecorder {
+				return f.get("/api/v1/other", "203.0.113.1:1", nil)
+			},
+			second: func(f *limiterFixture) *httptest.ResponseRecorder {
+				return f.get("/api/v1/other", "203.0.113.2:1", nil)
+			},
+			separate: true,
+		},
+		{
+			name: "Rejected credentials are limited by client IP",
+			rl:   config.RateLimitConfig{Requests: 1, KeyBy: KeyByPrincipal},
+			first: func(f *limiterFixture) *httptest.ResponseRecorder {
+				return f.get("/api/v1/other", "203.0.113.1:1", map[string]string{"X-Test-Reject": "1"})
+			},
+			second: func(f *limiterFixture) *httptest.ResponseRecorder {
+				return f.get("/api/v1/other", "203.0.113.1:1", map[string]string{"X-Test-Reject": "1"})
+			},
+			separate: false,
+		},
+		{
+			name: "Forwarded IP from a trusted proxy",
+			rl:   config.RateLimitConfig{Requests: 1, KeyBy: KeyByIP},
+			first: func(f *limiterFixture) *httptest.ResponseRecorder {
+				return f.get("/api/v1/other", "10.0.0.1:1", map[string]string{"X-Forwarded-For": "198.51.100.1"})
+			},
+			second: func(f *limiterFixture) *httptest.ResponseRecorder {
+				return f.get("/api/v1/other", "10.0.0.2:1", map[string]string{"X-Forwarded-For": "198.51.100.2"})
+			},
+			separate: true,
+		},
+		{
+			name: "Forwarded IP from an untrusted peer is ignored",
+			rl:   config.RateLimitConfig{Requests: 1, KeyBy: KeyByIP},
+			first: func(f *limiterFixture) *httptest.ResponseRecorder {
+				return f.get("/api/v1/other", "203.0.113.1:1", map[string]string{"X-Forwarded-For": "198.51.100.1"})
+			},
+			second: func(f *limiterFixture) *httptest.ResponseRecorder {
+				return f.get("/api/v1/other", "203.0.113.1:1", map[string]string{"X-Forwarded-For": "198.51.100.2"})
+			},
+			separate: false,
+		},
+		{
+			name: "Route limits all clients together",
+			rl:   config.RateLimitConfig{Requests: 1, KeyBy: KeyByRoute},
+			first: func(f *limiterFixture) *httptest.ResponseRecorder {
+				return f.get("/api/v1/other", "203.0.113.1:1", nil)
+			},
+			second: func(f *limiterFixture) *httptest.ResponseRecorder {
+				return f.get("/api/v1/other", "203.0.113.2:1", nil)
+			},
+			separate: false,
+		},
+		{
+			name: "Route override has its own bucket",
+			rl:   config.RateLimitConfig{Requests: 1, KeyBy: KeyByIP, Routes: "/api/v1/weather/:cep=5"},
+			first: func(f *limiterFixture) *httptest.ResponseRecorder {
+				return f.get("/api/v1/other", "203.0.113.1:1", nil)
+			},
+			second: func(f *limiterFixture) *httptest.ResponseRecorder {
+				return f.get("/api/v1/weather/01001000", "203.0.113.1:1", nil)
+			},
+			separate: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := setupLimiterFixture(t, tt.rl, NewMemoryStore())
+
+			require.NotEqual(t, http.StatusTooManyRequests, tt.first(fixture).Code)
+			expected := http.StatusTooManyRequests
+			if tt.separate {
+				expected = http.StatusOK
+			}
+			assert.Equal(t, expected, tt.second(fixture).Code)
+		})
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, Limit, time.Time) (Result, error) {
+	return Result{}, errors.New("store unavailable")
+}
+
+func TestLimiterFailsOpen(t *testing.T) {
+	fixture := setupLimiterFixture(t, config.RateLimitConfig{Requests: 1, KeyBy: KeyByIP}, failingStore{})
+
+	for i := 0; i < 3; i++ {
+		w := fixture.get("/api/v1/other", "203.0.113.1:1", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(HeaderLimit))
+	}
+}
+
+func TestNewLimiterRejectsInvalidConfig(t *testing.T) {
+	log := logger.NewWithWriter(io.Discard, config.LogConfig{Level: "error"}, logger.NewLevel(&config.Config{}))
+
+	for _, rl := range []config.RateLimitConfig{
+		{Enabled: true, Requests: 1, PeriodSec: 60, KeyBy: "user"},
+		{Enabled: true, Requests: 0, PeriodSec: 60, KeyBy: KeyByIP},
+		{Enabled: true, Requests: 1, PeriodSec: 60, KeyBy: KeyByIP, Routes: "/api/v1/weather/:cep"},
+	} {
+		_, err := NewLimiter(&config.Config{RateLimit: rl}, NewMemoryStore(), metrics.New(), log)
+		assert.Error(t, err)
+	}
+}
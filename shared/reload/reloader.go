@@ -0,0 +1,88 @@
+package reload
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// Reloader applies configuration changes to a running process, on SIGHUP or
+// when the config file or its overlay changes.
+type Reloader struct {
+	store   *config.Store
+	metrics *metrics.Metrics
+	logger  logger.Logger
+}
+
+func NewReloader(store *config.Store, m *metrics.Metrics, log logger.Logger) *Reloader {
+	return &Reloader{
+		store:   store,
+		metrics: m,
+		logger:  log,
+	}
+}
+
+// Reload re-reads every configuration source. A rejected reload keeps the
+// current configuration.
+func (r *Reloader) Reload(trigger string) error {
+	result, err := r.store.Reload()
+	if err != nil {
+		r.metrics.IncConfigReload(trigger, metrics.ReloadRejected)
+		r.logger.Error("Configuration reload (%s) rejected, keeping the current configuration: %v", trigger, err)
+		return err
+	}
+
+	r.metrics.IncConfigReload(trigger, metrics.ReloadSuccess)
+	if len(result.Ignored) > 0 {
+		r.logger.Warn("Configuration changes need a restart to take effect: %s", strings.Join(result.Ignored, ", "))
+	}
+	if len(result.Applied) == 0 {
+		r.logger.Info("Configuration reloaded (%s), no runtime settings changed", trigger)
+		return nil
+	}
+	r.logger.Info("Configuration reloaded (%s), applied: %s", trigger, strings.Join(result.Applied, ", "))
+	return nil
+}
+
+// Run listens for SIGHUP and watches the config files until ctx is done.
+// File watchers live as long as the process.
+func (r *Reloader) Run(ctx context.Context) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for _, file := range r.store.Options().Files(r.store.Current().App.Env) {
+		r.watch(file)
+	}
+
+	for {
+		select {
+		case <-signals:
+			_ = r.Reload(metrics.ReloadTriggerSignal)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// watch relies on viper's watcher, which observes the file's directory and so
+// also catches files created later and symlink swaps of mounted ConfigMaps.
+func (r *Reloader) watch(file string) {
+	watcher := viper.New()
+	watcher.SetConfigFile(file)
+	watcher.OnConfigChange(func(event fsnotify.Event) {
+		r.logger.Debug("Config file %s changed (%s)", event.Name, event.Op)
+		_ = r.Reload(metrics.ReloadTriggerFile)
+	})
+	watcher.WatchConfig()
+	r.logger.Info("Watching %s for configuration changes", file)
+}
@@ -0,0 +1,126 @@
+package reload
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/logger"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const baseConfig = `
+app:
+  env: development
+server:
+  port: "8080"
+log:
+  level: info
+`
+
+type reloaderFixture struct {
+	file     string
+	store    *config.Store
+	metrics  *metrics.Metrics
+	reloader *Reloader
+	applied  chan *config.Config
+}
+
+func setupReloaderFixture(t *testing.T) *reloaderFixture {
+	t.Helper()
+	t.Setenv("ENV", "")
+	t.Setenv("LOG_LEVEL", "")
+	t.Setenv("PORT", "")
+
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, file, baseConfig)
+
+	opts := config.Options{File: file}
+	cfg, err := config.Load(opts)
+	require.NoError(t, err)
+
+	fixture := &reloaderFixture{
+		file:    file,
+		store:   config.NewStore(cfg, opts),
+		metrics: metrics.New(),
+		applied: make(chan *config.Config, 10),
+	}
+	fixture.store.OnChange(func(cfg *config.Config) { fixture.applied <- cfg })
+	log := logger.NewWithWriter(io.Discard, cfg.Log, logger.NewLevel(cfg))
+	fixture.reloader = NewReloader(fixture.store, fixture.metrics, log)
+	return fixture
+}
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func assertReloads(t *testing.T, m *metrics.Metrics, trigger, result string) {
+	t.Helper()
+	expected := fmt.Sprintf(`
+# HELP weather_api_config_reloads_total Configuration reloads, by trigger and result.
+# TYPE weather_api_config_reloads_total counter
+weather_api_config_reloads_total{result=%q,trigger=%q} 1
+`, result, trigger)
+	assert.NoError(t, testutil.GatherAndCompare(m.Registry(), strings.NewReader(expected), "weather_api_config_reloads_total"))
+}
+
+func TestReloadAppliesRuntimeSettings(t *testing.T) {
+	fixture := setupReloaderFixture(t)
+	writeConfig(t, fixture.file, strings.NewReplacer("info", "debug", "8080", "9090").Replace(baseConfig))
+
+	err := fixture.reloader.Reload(metrics.ReloadTriggerSignal)
+
+	require.NoError(t, err)
+	current := fixture.store.Current()
+	assert.Equal(t, "debug", current.Log.Level)
+	assert.Equal(t, "8080", current.Server.Port, "server.port needs a restart")
+	assert.Same(t, current, <-fixture.applied)
+	assertReloads(t, fixture.metrics, "signal", "success")
+}
+
+func TestReloadRejectsInvalidConfiguration(t *testing.T) {
+	fixture := setupReloaderFixture(t)
+	before := fixture.store.Current()
+	writeConfig(t, fixture.file, strings.Replace(baseConfig, "info", "verbose", 1))
+
+	err := fixture.reloader.Reload(metrics.ReloadTriggerFile)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "LOG_LEVEL")
+	assert.Same(t, before, fixture.store.Current())
+	assert.Empty(t, fixture.applied)
+	assertReloads(t, fixture.metrics, "file", "rejected")
+}
+
+func TestRunReloadsOnFileChangeAndSIGHUP(t *testing.T) {
+	fixture := setupReloaderFixture(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = fixture.reloader.Run(ctx) }()
+
+	// Rewritten on every poll since the watcher starts asynchronously.
+	require.Eventually(t, func() bool {
+		writeConfig(t, fixture.file, strings.Replace(baseConfig, "info", "warn", 1))
+		return fixture.store.Current().Log.Level == "warn"
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// Env vars are only re-read on demand, so this change needs the signal.
+	t.Setenv("LOG_LEVEL", "error")
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	require.Eventually(t, func() bool {
+		return fixture.store.Current().Log.Level == "error"
+	}, 5*time.Second, 20*time.Millisecond)
+}
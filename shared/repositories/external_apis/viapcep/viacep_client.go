@@ -2,45 +2,89 @@ package viacep
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
-	"os"
+	"time"
 
 	"github.com/gerps2/desafio-cloud-run/shared/domain/valueObjects"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+	"github.com/gerps2/desafio-cloud-run/shared/requestid"
+	"github.com/gerps2/desafio-cloud-run/shared/telemetry"
+
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var ErrZipcodeNotFound = errors.New("zipcode not found")
+
+// pingCep is the CEP of Praça da Sé, used by the health check.
+const pingCep = "01001000"
+
+const tracerName = "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/viapcep"
+
 type ViaCepClient struct {
-	BaseURL string
+	BaseURL    string
+	tracer     trace.Tracer
+	client     *http.Client
+	propagator propagation.TextMapPropagator
+	metrics    *metrics.Metrics
 }
 
-func NewClient(baseURL string) *ViaCepClient {
+func NewClient(baseURL string, client *http.Client, tel *telemetry.Telemetry, m *metrics.Metrics) *ViaCepClient {
 	return &ViaCepClient{
-		BaseURL: baseURL,
+		BaseURL:    baseURL,
+		client:     client,
+		tracer:     tel.Tracer(tracerName),
+		propagator: tel.Propagator,
+		metrics:    m,
 	}
 }
 
-func (c *ViaCepClient) GetAddress(ctx context.Context, cep valueObjects.Cep) (*ViaCepResponse, error) {
+func (c *ViaCepClient) GetAddress(ctx context.Context, cep valueObjects.Cep) (_ *ViaCepResponse, err error) {
+	ctx, span := c.tracer.Start(ctx, "viacep.GetAddress",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			telemetry.AttrUpstream.String("viacep"),
+			telemetry.AttrCep.String(cep.String()),
+		),
+	)
+	start := time.Now()
+	defer func() {
+		outcome := metrics.Outcome(err)
+		if errors.Is(err, ErrZipcodeNotFound) {
+			outcome = metrics.OutcomeNotFound
+		}
+		c.metrics.ObserveUpstreamCall(metrics.UpstreamViaCep, outcome, time.Since(start))
+		telemetry.EndSpan(span, err)
+	}()
+
 	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s%s/json/", c.BaseURL, cep.String()), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	client := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: os.Getenv("ENV") == "production",
-			},
-		},
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.Header, id)
 	}
-	resp, err := client.Do(req)
+	c.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	span.SetAttributes(
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.ServerAddress(req.URL.Hostname()),
+		semconv.URLPath(req.URL.Path),
+	)
+
+	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+
 	if resp.StatusCode != 200 {
 		return nil, errors.New("failed to fetch address")
 	}
@@ -52,8 +96,29 @@ func (c *ViaCepClient) GetAddress(ctx context.Context, cep valueObjects.Cep) (*V
 	}
 
 	if address.Erro == "true" {
-		return nil, errors.New("zipcode not found")
+		return nil, ErrZipcodeNotFound
 	}
 
+	span.SetAttributes(telemetry.AttrCity.String(address.City))
+
 	return &address, nil
 }
+
+// Ping looks up a well-known CEP to check that ViaCEP is reachable.
+func (c *ViaCepClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s%s/json/", c.BaseURL, pingCep), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
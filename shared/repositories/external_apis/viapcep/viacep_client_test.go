@@ -0,0 +1,54 @@
+package viacep
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gerps2/desafio-cloud-run/shared/domain/valueObjects"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+	"github.com/gerps2/desafio-cloud-run/shared/requestid"
+	"github.com/gerps2/desafio-cloud-run/shared/telemetry"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestViaCepClientGetAddressForwardsRequestID(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(requestid.Header)
+		assert.Equal(t, "/01001-000/json/", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"cep":"01001-000","localidade":"São Paulo","uf":"SP"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", server.Client(), telemetry.NewNoop(), metrics.New())
+	cep, err := valueObjects.NewCep("01001000")
+	require.NoError(t, err)
+
+	ctx := requestid.NewContext(context.Background(), "req-42")
+	address, err := client.GetAddress(ctx, cep)
+
+	require.NoError(t, err)
+	assert.Equal(t, "São Paulo", address.City)
+	assert.Equal(t, "req-42", received)
+}
+
+func TestViaCepClientGetAddressNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"erro":"true"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", server.Client(), telemetry.NewNoop(), metrics.New())
+	cep, err := valueObjects.NewCep("99999999")
+	require.NoError(t, err)
+
+	address, err := client.GetAddress(context.Background(), cep)
+
+	assert.Error(t, err)
+	assert.Nil(t, address)
+}
@@ -0,0 +1,203 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	BudgetPeriodDay   = "day"
+	BudgetPeriodMonth = "month"
+)
+
+var ErrBudgetExceeded = errors.New("weather API call budget exceeded")
+
+// Budget enforces the WeatherAPI plan limits on this instance: a per-second
+// rate, where calls queue up to a maximum wait, and a number of calls per
+// day or month, reset at UTC boundaries.
+type Budget struct {
+	limiter     *rate.Limiter
+	burst       int
+	maxWait     time.Duration
+	period      string
+	periodCalls int64
+	metrics     *metrics.Metrics
+	now         func() time.Time
+
+	mu     sync.Mutex
+	window time.Time
+	used   int64
+}
+
+type BudgetSnapshot struct {
+	Upstream        string  `json:"upstream" xml:"upstream"`
+	CallsPerSecond  float64 `json:"calls_per_second,omitempty" xml:"calls_per_second,omitempty"`
+	Burst           int     `json:"burst,omitempty" xml:"burst,omitempty"`
+	AvailableTokens float64 `json:"available_tokens,omitempty" xml:"available_tokens,omitempty"`
+	Period          string  `json:"period,omitempty" xml:"period,omitempty"`
+	PeriodCalls     int64   `json:"period_calls,omitempty" xml:"period_calls,omitempty"`
+	PeriodUsed      int64   `json:"period_used" xml:"period_used"`
+	PeriodRemaining int64   `json:"period_remaining,omitempty" xml:"period_remaining,omitempty"`
+	// ResetsAt is nil when no period quota is configured.
+	ResetsAt *time.Time `json:"resets_at,omitempty" xml:"resets_at,omitempty"`
+}
+
+func NewBudget(cfg config.WeatherBudgetConfig, m *metrics.Metrics) (*Budget, error) {
+	if cfg.Period != BudgetPeriodDay && cfg.Period != BudgetPeriodMonth {
+		return nil, fmt.Errorf("invalid WEATHER_BUDGET_PERIOD %q: expected day or month", cfg.Period)
+	}
+
+	budget := &Budget{
+		maxWait:     time.Duration(cfg.MaxWaitMs) * time.Millisecond,
+		period:      cfg.Period,
+		periodCalls: cfg.PeriodCalls,
+		metrics:     m,
+		now:         time.Now,
+	}
+	if cfg.CallsPerSecond > 0 {
+		budget.burst = max(cfg.Burst, 1)
+		budget.limiter = rate.NewLimiter(rate.Limit(cfg.CallsPerSecond), budget.burst)
+	}
+	if budget.periodCalls > 0 {
+		m.SetUpstreamBudgetRemaining(metrics.UpstreamWeather, budget.periodCalls)
+	}
+
+	return budget, nil
+}
+
+// Acquire takes one call from the budget, waiting for a per-second slot when
+// it frees up within the maximum wait. Rejections wrap ErrBudgetExceeded.
+func (b *Budget) Acquire(ctx context.Context) error {
+	if err := b.takePeriodCall(); err != nil {
+		b.metrics.IncUpstreamBudgetRejection(metrics.UpstreamWeather, metrics.BudgetReasonQuota)
+		return err
+	}
+	if b.limiter == nil {
+		return nil
+	}
+
+	now := b.now()
+	reservation := b.limiter.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if !reservation.OK() || delay > b.maxWait {
+		reservation.CancelAt(now)
+		b.releasePeriodCall()
+		b.metrics.IncUpstreamBudgetRejection(metrics.UpstreamWeather, metrics.BudgetReasonRate)
+		return fmt.Errorf("%w: more than %g calls per second", ErrBudgetExceeded, float64(b.limiter.Limit()))
+	}
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		reservation.Cancel()
+		b.releasePeriodCall()
+		return ctx.Err()
+	}
+}
+
+func (b *Budget) Snapshot() BudgetSnapshot {
+	now := b.now()
+	snapshot := BudgetSnapshot{Upstream: metrics.UpstreamWeather}
+
+	if b.limiter != nil {
+		snapshot.CallsPerSecond = float64(b.limiter.Limit())
+		snapshot.Burst = b.burst
+		snapshot.AvailableTokens = max(b.limiter.TokensAt(now), 0)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollWindow(now)
+
+	snapshot.PeriodUsed = b.used
+	if b.periodCalls > 0 {
+		snapshot.Period = b.period
+		snapshot.PeriodCalls = b.periodCalls
+		snapshot.PeriodRemaining = max(b.periodCalls-b.used, 0)
+		resetsAt := b.nextWindow(b.window)
+		snapshot.ResetsAt = &resetsAt
+	}
+	return snapshot
+}
+
+// QuotaErr wraps ErrBudgetExceeded when the period quota is used up, without
+// taking a call.
+func (b *Budget) QuotaErr() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollWindow(b.now())
+	return b.quotaErr()
+}
+
+func (b *Budget) takePeriodCall() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollWindow(b.now())
+
+	if err := b.quotaErr(); err != nil {
+		return err
+	}
+	b.used++
+	b.reportRemaining()
+	return nil
+}
+
+// quotaErr must be called with b.mu held.
+func (b *Budget) quotaErr() error {
+	if b.periodCalls > 0 && b.used >= b.periodCalls {
+		return fmt.Errorf("%w: %d calls per %s used", ErrBudgetExceeded, b.periodCalls, b.period)
+	}
+	return nil
+}
+
+func (b *Budget) releasePeriodCall() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.used > 0 {
+		b.used--
+	}
+	b.reportRemaining()
+}
+
+// rollWindow resets the counter when a new period started. It must be called
+// with b.mu held.
+func (b *Budget) rollWindow(now time.Time) {
+	now = now.UTC()
+	window := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if b.period == BudgetPeriodDay {
+		window = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	if !window.Equal(b.window) {
+		b.window = window
+		b.used = 0
+		b.reportRemaining()
+	}
+}
+
+func (b *Budget) nextWindow(window time.Time) time.Time {
+	if b.period == BudgetPeriodDay {
+		return window.AddDate(0, 0, 1)
+	}
+	return window.AddDate(0, 1, 0)
+}
+
+func (b *Budget) reportRemaining() {
+	if b.periodCalls > 0 {
+		b.metrics.SetUpstreamBudgetRemaining(metrics.UpstreamWeather, max(b.periodCalls-b.used, 0))
+	}
+}
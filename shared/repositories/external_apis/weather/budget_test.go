@@ -0,0 +1,101 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBudget(t *testing.T, cfg config.WeatherBudgetConfig, now *time.Time) (*Budget, *metrics.Metrics) {
+	t.Helper()
+	m := metrics.New()
+	budget, err := NewBudget(cfg, m)
+	require.NoError(t, err)
+	budget.now = func() time.Time { return *now }
+	return budget, m
+}
+
+func TestBudgetPeriodQuota(t *testing.T) {
+	now := time.Date(2024, 1, 31, 23, 0, 0, 0, time.UTC)
+	budget, m := newTestBudget(t, config.WeatherBudgetConfig{PeriodCalls: 2, Period: BudgetPeriodMonth}, &now)
+
+	require.NoError(t, budget.Acquire(context.Background()))
+	require.NoError(t, budget.Acquire(context.Background()))
+
+	err := budget.Acquire(context.Background())
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+
+	snapshot := budget.Snapshot()
+	assert.Equal(t, int64(2), snapshot.PeriodUsed)
+	assert.Equal(t, int64(0), snapshot.PeriodRemaining)
+	require.NotNil(t, snapshot.ResetsAt)
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), *snapshot.ResetsAt)
+
+	expected := `
+# HELP weather_api_upstream_budget_rejections_total Upstream calls rejected by the client-side budget, by upstream and reason.
+# TYPE weather_api_upstream_budget_rejections_total counter
+weather_api_upstream_budget_rejections_total{reason="quota",upstream="weather"} 1
+# HELP weather_api_upstream_budget_remaining Calls left in the current budget period of an upstream API.
+# TYPE weather_api_upstream_budget_remaining gauge
+weather_api_upstream_budget_remaining{upstream="weather"} 0
+`
+	assert.NoError(t, testutil.GatherAndCompare(m.Registry(), strings.NewReader(expected),
+		"weather_api_upstream_budget_rejections_total", "weather_api_upstream_budget_remaining"))
+
+	// The budget resets at the start of the next UTC month.
+	now = now.Add(time.Hour)
+	require.NoError(t, budget.Acquire(context.Background()))
+	assert.Equal(t, int64(1), budget.Snapshot().PeriodRemaining)
+}
+
+func TestBudgetPerSecondRate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	budget, _ := newTestBudget(t, config.WeatherBudgetConfig{CallsPerSecond: 1, Burst: 1, MaxWaitMs: 0, Period: BudgetPeriodDay}, &now)
+
+	require.NoError(t, budget.Acquire(context.Background()))
+
+	err := budget.Acquire(context.Background())
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+	// A rejected call does not count against the period budget.
+	assert.Equal(t, int64(1), budget.Snapshot().PeriodUsed)
+
+	// Without a period quota there is no reset to report.
+	encoded, err := json.Marshal(budget.Snapshot())
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "resets_at")
+
+	now = now.Add(time.Second)
+	assert.NoError(t, budget.Acquire(context.Background()))
+}
+
+func TestBudgetQueuesWithinMaxWait(t *testing.T) {
+	budget, err := NewBudget(config.WeatherBudgetConfig{CallsPerSecond: 20, Burst: 1, MaxWaitMs: 500, Period: BudgetPeriodDay}, metrics.New())
+	require.NoError(t, err)
+
+	require.NoError(t, budget.Acquire(context.Background()))
+
+	start := time.Now()
+	require.NoError(t, budget.Acquire(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	// A cancelled caller gives its slot back.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.True(t, errors.Is(budget.Acquire(ctx), context.Canceled))
+	assert.Equal(t, int64(2), budget.Snapshot().PeriodUsed)
+}
+
+func TestNewBudgetRejectsInvalidPeriod(t *testing.T) {
+	_, err := NewBudget(config.WeatherBudgetConfig{Period: "week"}, metrics.New())
+	assert.Error(t, err)
+}
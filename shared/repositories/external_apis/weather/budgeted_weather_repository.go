@@ -0,0 +1,38 @@
+package weather
+
+import (
+	"context"
+)
+
+// BudgetedWeatherRepository spends one call of the budget before every
+// WeatherAPI request, so plan limits are never exceeded.
+type BudgetedWeatherRepository struct {
+	next   WeatherRepositoryInterface
+	budget *Budget
+}
+
+func NewBudgetedWeatherRepository(next WeatherRepositoryInterface, budget *Budget) WeatherRepositoryInterface {
+	return &BudgetedWeatherRepository{
+		next:   next,
+		budget: budget,
+	}
+}
+
+func (r *BudgetedWeatherRepository) GetWeather(ctx context.Context, city string) (*WeatherResponse, error) {
+	if err := r.budget.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	return r.next.GetWeather(ctx, city)
+}
+
+// Probe reports WeatherAPI readiness without spending the budget: the period
+// quota being used up, wrapping ErrBudgetExceeded, or else the outcome of
+// the last real call.
+func Probe(client *WeatherClient, budget *Budget) func(ctx context.Context) error {
+	return func(context.Context) error {
+		if err := budget.QuotaErr(); err != nil {
+			return err
+		}
+		return client.LastCallErr()
+	}
+}
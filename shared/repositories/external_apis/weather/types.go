@@ -2,15 +2,19 @@ package weather
 
 type WeatherResponse struct {
 	Location struct {
-		Name    string `json:"name"`
-		Region  string `json:"region"`
-		Country string `json:"country"`
+		Name    string  `json:"name"`
+		Region  string  `json:"region"`
+		Country string  `json:"country"`
+		Lat     float64 `json:"lat"`
+		Lon     float64 `json:"lon"`
 	} `json:"location"`
 	Current struct {
-		TempC     float64 `json:"temp_c"`
-		TempF     float64 `json:"temp_f"`
-		Condition struct {
+		TempC            float64 `json:"temp_c"`
+		TempF            float64 `json:"temp_f"`
+		LastUpdatedEpoch int64   `json:"last_updated_epoch"`
+		Condition        struct {
 			Text string `json:"text"`
+			Code int    `json:"code"`
 		} `json:"condition"`
 	} `json:"current"`
 }
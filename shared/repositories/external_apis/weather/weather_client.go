@@ -2,49 +2,93 @@ package weather
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
-	"os"
+	"sync"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+	"github.com/gerps2/desafio-cloud-run/shared/requestid"
+	"github.com/gerps2/desafio-cloud-run/shared/telemetry"
+
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const tracerName = "github.com/gerps2/desafio-cloud-run/shared/repositories/external_apis/weather"
+
 type WeatherClient struct {
-	BaseURL string
-	APIKey  string
+	BaseURL    string
+	APIKey     string
+	tracer     trace.Tracer
+	client     *http.Client
+	propagator propagation.TextMapPropagator
+	metrics    *metrics.Metrics
+
+	mu      sync.Mutex
+	lastErr error
 }
 
-func NewClient(baseURL string, apiKey string) *WeatherClient {
+func NewClient(baseURL string, apiKey string, client *http.Client, tel *telemetry.Telemetry, m *metrics.Metrics) *WeatherClient {
 	return &WeatherClient{
-		BaseURL: baseURL,
-		APIKey:  apiKey,
+		BaseURL:    baseURL,
+		APIKey:     apiKey,
+		client:     client,
+		tracer:     tel.Tracer(tracerName),
+		propagator: tel.Propagator,
+		metrics:    m,
 	}
 }
 
-func (c *WeatherClient) GetWeather(ctx context.Context, city string) (*WeatherResponse, error) {
+func (c *WeatherClient) GetWeather(ctx context.Context, city string) (_ *WeatherResponse, err error) {
+	ctx, span := c.tracer.Start(ctx, "weather.GetWeather",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			telemetry.AttrUpstream.String("weather"),
+			telemetry.AttrCity.String(city),
+		),
+	)
+	start := time.Now()
+	statusCode := 0
+	defer func() {
+		c.recordCall(ctx, statusCode, err)
+		c.metrics.ObserveUpstreamCall(metrics.UpstreamWeather, metrics.Outcome(err), time.Since(start))
+		telemetry.EndSpan(span, err)
+	}()
+
 	safeCity := url.QueryEscape(city)
 	fullURL := fmt.Sprintf("%s%s&q=%s", c.BaseURL, c.APIKey, safeCity)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
 	if err != nil {
-		return nil, err
+		return nil, withoutURL(err)
 	}
 
-	client := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: os.Getenv("ENV") == "production",
-			},
-		},
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.Header, id)
 	}
-	resp, err := client.Do(req)
+	c.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	// The query string carries the API key, so only host and path are recorded.
+	span.SetAttributes(
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.ServerAddress(req.URL.Hostname()),
+		semconv.URLPath(req.URL.Path),
+	)
+
+	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, withoutURL(err)
 	}
 	defer resp.Body.Close()
 
+	statusCode = resp.StatusCode
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+
 	if resp.StatusCode != 200 {
 		return nil, errors.New("failed to fetch weather data")
 	}
@@ -57,3 +101,43 @@ func (c *WeatherClient) GetWeather(ctx context.Context, city string) (*WeatherRe
 
 	return &weather, nil
 }
+
+// LastCallErr returns the failure of the last WeatherAPI call that reached a
+// verdict, or nil when it succeeded or no call was made yet. Readiness reads
+// it instead of spending the quota on calls of its own.
+func (c *WeatherClient) LastCallErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastErr
+}
+
+// recordCall keeps the outcome of a call for LastCallErr. Calls abandoned by
+// their caller say nothing about WeatherAPI, and an unknown city (400) means
+// it answered, so neither counts as a failure.
+func (c *WeatherClient) recordCall(ctx context.Context, statusCode int, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		err = errors.New("weather API key was rejected")
+	case statusCode == http.StatusBadRequest:
+		err = nil
+	case statusCode >= http.StatusInternalServerError:
+		err = fmt.Errorf("unexpected status code %d", statusCode)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastErr = err
+}
+
+// withoutURL unwraps *url.Error: the request URL carries the API key, and the
+// errors returned here end up in spans and logs.
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
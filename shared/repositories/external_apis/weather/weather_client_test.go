@@ -0,0 +1,70 @@
+package weather
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+	"github.com/gerps2/desafio-cloud-run/shared/metrics"
+	"github.com/gerps2/desafio-cloud-run/shared/telemetry"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeatherClientErrorsDoNotCarryTheAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	baseURL := server.URL + "/v1/current.json?key="
+	server.Close()
+
+	client := NewClient(baseURL, "secret-key", server.Client(), telemetry.NewNoop(), metrics.New())
+
+	_, err := client.GetWeather(context.Background(), "São Paulo")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-key")
+
+	invalid := NewClient("http://[::1:bad/?key=", "secret-key", server.Client(), telemetry.NewNoop(), metrics.New())
+	_, err = invalid.GetWeather(context.Background(), "São Paulo")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-key")
+}
+
+func TestProbeReusesTheLastCall(t *testing.T) {
+	status := http.StatusOK
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	budget, _ := newTestBudget(t, config.WeatherBudgetConfig{PeriodCalls: 4, Period: BudgetPeriodDay}, &now)
+	client := NewClient(server.URL+"/v1/current.json?key=", "secret-key", server.Client(), telemetry.NewNoop(), metrics.New())
+	repository := NewBudgetedWeatherRepository(NewWeatherRepository(client), budget)
+	probe := Probe(client, budget)
+
+	assert.NoError(t, probe(context.Background()), "ready before the first call")
+
+	status = http.StatusUnauthorized
+	_, _ = repository.GetWeather(context.Background(), "São Paulo")
+	assert.EqualError(t, probe(context.Background()), "weather API key was rejected")
+
+	status = http.StatusBadRequest
+	_, _ = repository.GetWeather(context.Background(), "Nowhere")
+	assert.NoError(t, probe(context.Background()), "an unknown city means WeatherAPI answered")
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _ = repository.GetWeather(canceled, "São Paulo")
+	assert.NoError(t, probe(context.Background()), "abandoned calls are not recorded")
+
+	require.NoError(t, budget.Acquire(context.Background()))
+	assert.ErrorIs(t, probe(context.Background()), ErrBudgetExceeded)
+	assert.Equal(t, 2, calls, "the probe never calls WeatherAPI")
+	assert.Equal(t, int64(4), budget.Snapshot().PeriodUsed)
+}
@@ -0,0 +1,42 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+)
+
+const Header = "X-Request-ID"
+
+const maxLength = 128
+
+var validID = regexp.MustCompile(`^[A-Za-z0-9._:\-]+$`)
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+func New() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// IsValid reports whether an ID received from a client is safe to echo back
+// in headers and log lines.
+func IsValid(id string) bool {
+	return len(id) > 0 && len(id) <= maxLength && validID.MatchString(id)
+}
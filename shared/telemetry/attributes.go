@@ -0,0 +1,9 @@
+package telemetry
+
+import "go.opentelemetry.io/otel/attribute"
+
+const (
+	AttrCep      = attribute.Key("app.cep")
+	AttrCity     = attribute.Key("app.city")
+	AttrUpstream = attribute.Key("app.upstream")
+)
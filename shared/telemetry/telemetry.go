@@ -0,0 +1,103 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gerps2/desafio-cloud-run/shared/config"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+// Telemetry groups the tracer provider and propagator shared by the HTTP
+// server, use cases and upstream clients.
+type Telemetry struct {
+	TracerProvider trace.TracerProvider
+	Propagator     propagation.TextMapPropagator
+}
+
+func New(cfg *config.Config) (*Telemetry, func(), error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch strings.ToLower(cfg.Telemetry.Exporter) {
+	case "", ExporterNone:
+		return NewNoop(), func() {}, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.Telemetry.OTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Telemetry.OTLPEndpoint))
+		}
+		exporter, err = otlptracehttp.New(context.Background(), opts...)
+	default:
+		return nil, nil, fmt.Errorf("unknown tracing exporter %q", cfg.Telemetry.Exporter)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create %s trace exporter: %w", cfg.Telemetry.Exporter, err)
+	}
+
+	res := resource.NewSchemaless(semconv.ServiceName(cfg.Telemetry.ServiceName))
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Telemetry.SampleRatio))),
+	)
+
+	cleanup := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = provider.Shutdown(ctx)
+	}
+
+	return &Telemetry{
+		TracerProvider: provider,
+		Propagator:     NewPropagator(),
+	}, cleanup, nil
+}
+
+// NewNoop keeps context propagation working while recording no spans.
+func NewNoop() *Telemetry {
+	return &Telemetry{
+		TracerProvider: noop.NewTracerProvider(),
+		Propagator:     NewPropagator(),
+	}
+}
+
+func NewPropagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+func (t *Telemetry) Tracer(name string) trace.Tracer {
+	return t.TracerProvider.Tracer(name)
+}
+
+// EndSpan records err on span, if any, and ends it. It is meant to be
+// deferred with a named error return.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}